@@ -0,0 +1,195 @@
+// Package apperr defines the errors the API answers with. Every error has a
+// kind, which decides its HTTP status, and a stable code clients can match on
+// instead of the message. ErrorHandler in the middleware package renders them
+// as RFC 7807 problem details.
+package apperr
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindPreconditionFailed
+	KindPreconditionRequired
+	KindTooLarge
+	KindUnsupportedMediaType
+	KindUnprocessable
+	KindWorkflowViolation
+)
+
+// kinds holds the status of each kind and the code its errors get by default
+var kinds = map[Kind]struct {
+	status int
+	code   string
+}{
+	KindInternal:             {http.StatusInternalServerError, "internal_error"},
+	KindBadRequest:           {http.StatusBadRequest, "bad_request"},
+	KindValidation:           {http.StatusBadRequest, "validation_failed"},
+	KindUnauthorized:         {http.StatusUnauthorized, "unauthorized"},
+	KindForbidden:            {http.StatusForbidden, "forbidden"},
+	KindNotFound:             {http.StatusNotFound, "not_found"},
+	KindConflict:             {http.StatusConflict, "conflict"},
+	KindPreconditionFailed:   {http.StatusPreconditionFailed, "precondition_failed"},
+	KindPreconditionRequired: {http.StatusPreconditionRequired, "precondition_required"},
+	KindTooLarge:             {http.StatusRequestEntityTooLarge, "too_large"},
+	KindUnsupportedMediaType: {http.StatusUnsupportedMediaType, "unsupported_media_type"},
+	KindUnprocessable:        {http.StatusUnprocessableEntity, "unprocessable"},
+	KindWorkflowViolation:    {http.StatusUnprocessableEntity, "workflow_violation"},
+}
+
+// FieldError is a problem with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error is an error the API can answer with. Its message is shown to clients,
+// except for internal errors, whose cause is only logged.
+type Error struct {
+	Kind       Kind
+	Code       string
+	Message    string
+	Fields     []FieldError
+	Extensions map[string]interface{}
+	cause      error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Status is the HTTP status the error is answered with
+func (e *Error) Status() int {
+	return kinds[e.Kind].status
+}
+
+// New returns an error of the kind with a code of its own
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func newKind(kind Kind, message string) *Error {
+	return New(kind, kinds[kind].code, message)
+}
+
+func BadRequest(message string) *Error {
+	return newKind(KindBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return newKind(KindUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return newKind(KindForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return newKind(KindNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return newKind(KindConflict, message)
+}
+
+func Unprocessable(message string) *Error {
+	return newKind(KindUnprocessable, message)
+}
+
+// Validation is a request with invalid fields
+func Validation(message string, fields ...FieldError) *Error {
+	err := newKind(KindValidation, message)
+	err.Fields = fields
+	return err
+}
+
+// Invalid is a request with one invalid field. The code names the problem
+// with the field; the error itself has the validation_failed code.
+func Invalid(field, code, message string) *Error {
+	return Validation(message, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Internal is a failure on our side. Only the message reaches the client; the
+// cause is kept for the log.
+func Internal(cause error, message string) *Error {
+	return newKind(KindInternal, message).Wrap(cause)
+}
+
+// OrInternal keeps err when an API error is in its chain, and otherwise
+// reports it as an internal error with the message
+func OrInternal(err error, message string) error {
+	var appErr *Error
+	var converter Converter
+	if errors.As(err, &appErr) || errors.As(err, &converter) {
+		return err
+	}
+	return Internal(err, message)
+}
+
+// Wrap records the error that caused e, so errors.Is and errors.As still find it
+func (e *Error) Wrap(cause error) *Error {
+	e.cause = cause
+	return e
+}
+
+// With adds an extension member to the problem the error is rendered as
+func (e *Error) With(key string, value interface{}) *Error {
+	if e.Extensions == nil {
+		e.Extensions = map[string]interface{}{}
+	}
+	e.Extensions[key] = value
+	return e
+}
+
+// Converter is implemented by domain errors that carry more than a sentinel
+// can, such as the details of a refused workflow transition
+type Converter interface {
+	AppError() *Error
+}
+
+// From finds the API error in err's chain. Domain errors wrapped with more
+// context keep that context in their message. Record-not-found errors become
+// not_found, and anything else is an internal error that hides its message.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		if appErr == err || appErr.Kind == KindInternal {
+			return appErr
+		}
+		wrapped := *appErr
+		wrapped.Message = err.Error()
+		return &wrapped
+	}
+	var converter Converter
+	if errors.As(err, &converter) {
+		return converter.AppError()
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return NotFound("record not found")
+	}
+	return Internal(err, "internal server error")
+}
+
+// Abort stops the request with err, which the error middleware answers with
+func Abort(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,100 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Binding describes why a request body could not be bound: each failed
+// binding rule or mistyped value becomes a field error named after the JSON
+// field. Other decoding errors are reported without their details.
+func Binding(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			name := jsonName(fieldErr.Field())
+			fields = append(fields, FieldError{Field: name, Code: fieldErr.Tag(), Message: ruleMessage(name, fieldErr)})
+		}
+		return Validation("request body is invalid", fields...)
+	case errors.As(err, &typeErr):
+		name := typeErr.Field
+		if name == "" {
+			return BadRequest("request body must be a JSON object")
+		}
+		if expected := typeName(typeErr); expected != "" {
+			return Invalid(name, "invalid_type", name+" must be a "+expected)
+		}
+		return Invalid(name, "invalid_type", name+" has the wrong type")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return BadRequest("request body is not valid JSON")
+	case errors.Is(err, io.EOF):
+		return BadRequest("request body is required")
+	default:
+		return BadRequest("request body is invalid")
+	}
+}
+
+func ruleMessage(field string, fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be an email address"
+	case "min":
+		return field + " must be at least " + fieldErr.Param() + " characters"
+	case "max":
+		return field + " must be at most " + fieldErr.Param() + " characters"
+	case "oneof":
+		return field + " must be one of " + strings.ReplaceAll(fieldErr.Param(), " ", ", ")
+	default:
+		return field + " is invalid"
+	}
+}
+
+func typeName(typeErr *json.UnmarshalTypeError) string {
+	switch typeErr.Type.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "list"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return ""
+	}
+}
+
+// jsonName turns a Go field name into the snake_case name the API uses for
+// it, keeping initialisms together: DueDate becomes due_date, UserID user_id
+func jsonName(field string) string {
+	runes := []rune(field)
+	var name strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			lowerBefore := i > 0 && unicode.IsLower(runes[i-1])
+			lowerAfter := i > 0 && i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1])
+			if lowerBefore || lowerAfter {
+				name.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name.WriteRune(r)
+	}
+	return name.String()
+}
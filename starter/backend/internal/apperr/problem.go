@@ -0,0 +1,62 @@
+package apperr
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType is the media type of problem details
+const ContentType = "application/problem+json"
+
+// Problem is an error as RFC 7807 problem details. Code, request_id, errors
+// and the error's extensions are extension members.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Code       string                 `json:"code"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	Errors     []FieldError           `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem describes err as problem details for the request to instance.
+// The type is about:blank, so the title is the status text and the code tells
+// errors with the same status apart.
+func NewProblem(err *Error, instance, requestID string) *Problem {
+	return &Problem{
+		Type:       "about:blank",
+		Title:      http.StatusText(err.Status()),
+		Status:     err.Status(),
+		Detail:     err.Message,
+		Instance:   instance,
+		Code:       err.Code,
+		RequestID:  requestID,
+		Errors:     err.Fields,
+		Extensions: err.Extensions,
+	}
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	members, err := json.Marshal((*problem)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return members, err
+	}
+
+	all := map[string]interface{}{}
+	for key, value := range p.Extensions {
+		all[key] = value
+	}
+	// The standard members win over extensions of the same name
+	var standard map[string]interface{}
+	if err := json.Unmarshal(members, &standard); err != nil {
+		return nil, err
+	}
+	for key, value := range standard {
+		all[key] = value
+	}
+	return json.Marshal(all)
+}
@@ -0,0 +1,50 @@
+// Package events fans task changes out to the streams of connected clients.
+// Events only carry identifiers; subscribers load the task and decide for
+// themselves whether their user may see it.
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	TaskCreated = "task.created"
+	TaskUpdated = "task.updated"
+	TaskDeleted = "task.deleted"
+)
+
+// ShareRef names a subject a task was shared with. Deletions carry the shares
+// the task had, because they are gone by the time subscribers look.
+type ShareRef struct {
+	Type string    `json:"type"`
+	ID   uuid.UUID `json:"id"`
+}
+
+type Event struct {
+	ID       uuid.UUID  `json:"id"`
+	Type     string     `json:"type"`
+	TenantID uuid.UUID  `json:"tenant_id"`
+	TaskID   uuid.UUID  `json:"task_id"`
+	Shares   []ShareRef `json:"shares,omitempty"`
+	At       time.Time  `json:"at"`
+}
+
+// Broker delivers every published event to every subscription
+type Broker interface {
+	Publish(ctx context.Context, event Event) error
+	Subscribe() *Subscription
+}
+
+// Subscription receives events on C until it is closed. A subscriber that
+// falls too far behind has C closed under it and should reconnect.
+type Subscription struct {
+	C     <-chan Event
+	close func()
+}
+
+func (s *Subscription) Close() {
+	s.close()
+}
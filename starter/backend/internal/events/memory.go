@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBroker fans events out within one process
+type MemoryBroker struct {
+	mu     sync.Mutex
+	subs   map[chan Event]struct{}
+	buffer int
+}
+
+// NewMemoryBroker returns a broker that holds up to buffer undelivered events
+// per subscription before dropping the subscription
+func NewMemoryBroker(buffer int) *MemoryBroker {
+	return &MemoryBroker{subs: make(map[chan Event]struct{}), buffer: buffer}
+}
+
+func (b *MemoryBroker) Publish(ctx context.Context, event Event) error {
+	b.deliver(event)
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe() *Subscription {
+	ch := make(chan Event, b.buffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return &Subscription{C: ch, close: func() { b.remove(ch) }}
+}
+
+// deliver never blocks: a subscription whose buffer is full is closed rather
+// than allowed to hold up everyone else
+func (b *MemoryBroker) deliver(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+func (b *MemoryBroker) remove(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		close(ch)
+	}
+}
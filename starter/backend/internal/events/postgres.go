@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// maxNotifyPayload stays below the 8000 byte limit Postgres puts on NOTIFY
+const maxNotifyPayload = 7900
+
+// PostgresBroker fans events out across replicas through LISTEN/NOTIFY. Events
+// are published with NOTIFY only; every replica, the publishing one included,
+// hands them to its subscribers when they come back on its LISTEN connection.
+type PostgresBroker struct {
+	db      *gorm.DB
+	dsn     string
+	channel string
+	local   *MemoryBroker
+}
+
+func NewPostgresBroker(db *gorm.DB, dsn, channel string, buffer int) *PostgresBroker {
+	return &PostgresBroker{db: db, dsn: dsn, channel: channel, local: NewMemoryBroker(buffer)}
+}
+
+func (b *PostgresBroker) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		// Too many shares to fit: subscribers can still tell from the task
+		// itself, only users who saw it through a share miss the deletion
+		event.Shares = nil
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	return b.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", b.channel, string(payload)).Error
+}
+
+func (b *PostgresBroker) Subscribe() *Subscription {
+	return b.local.Subscribe()
+}
+
+// Listen receives notifications until ctx is cancelled, reconnecting with
+// backoff whenever the connection drops. Events notified while it is
+// disconnected are lost.
+func (b *PostgresBroker) Listen(ctx context.Context) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		slog.WarnContext(ctx, "event broker disconnected", "error", err, "retry_in", backoff.String())
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+func (b *PostgresBroker) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			slog.WarnContext(ctx, "event broker ignored a malformed event", "error", err)
+			continue
+		}
+		b.local.deliver(event)
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/repositories"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/storage"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// AttachmentDownloadPath is where signed download links point
+const AttachmentDownloadPath = "/api/v1/attachments/"
+
+type AttachmentHandler struct {
+	db                *gorm.DB
+	taskService       services.TaskService
+	attachmentService services.AttachmentService
+	urlTTL            time.Duration
+}
+
+// AttachmentResponse is an attachment with a short-lived download link
+type AttachmentResponse struct {
+	models.Attachment
+	DownloadURL string    `json:"download_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func NewAttachmentHandler(db *gorm.DB, taskService services.TaskService, attachmentService services.AttachmentService, urlTTL time.Duration) *AttachmentHandler {
+	return &AttachmentHandler{db: db, taskService: taskService, attachmentService: attachmentService, urlTTL: urlTTL}
+}
+
+// UploadAttachment stores the multipart "file" field on the task. Editors and
+// owners can upload.
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	task, actor, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessEditor, "update")
+	if !ok {
+		return
+	}
+
+	// Leave room for the multipart framing around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.attachmentService.MaxSize()+1<<20)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apperr.Abort(c, services.ErrAttachmentTooLarge)
+			return
+		}
+		apperr.Abort(c, apperr.BadRequest("multipart field \"file\" is required"))
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("failed to read upload"))
+		return
+	}
+	defer src.Close()
+
+	attachment, err := h.attachmentService.Upload(requestDB(c, h.db), task, actor.UserID, file.Filename, src)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to store attachment"))
+		return
+	}
+	c.JSON(http.StatusCreated, h.withDownloadURL(c, *attachment, actor))
+}
+
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	task, actor, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessViewer, "access")
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachmentService.GetAttachments(requestDB(c, h.db), task.ID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get attachments"))
+		return
+	}
+
+	response := make([]AttachmentResponse, 0, len(attachments))
+	for _, attachment := range attachments {
+		response = append(response, h.withDownloadURL(c, attachment, actor))
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAttachment returns the attachment with a fresh download link
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	attachment, actor, ok := h.loadAttachment(c, models.TaskAccessViewer, "access")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, h.withDownloadURL(c, *attachment, actor))
+}
+
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	attachment, _, ok := h.loadAttachment(c, models.TaskAccessEditor, "update")
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentService.DeleteAttachment(requestDB(c, h.db), attachment); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to delete attachment"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DownloadAttachment serves the file behind a signed link. The link is bound
+// to the user it was issued to, and their access to the task is checked again
+// on every download, so revoking a share also revokes outstanding links.
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	attachmentID, err := uuid.FromString(c.Param("attachment_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid attachment ID"))
+		return
+	}
+
+	tenantID := uuid.FromStringOrNil(c.Query("tenant"))
+	userID := uuid.FromStringOrNil(c.Query("user"))
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil || userID == uuid.Nil ||
+		!utils.VerifyResourceSignature(downloadResource(attachmentID, tenantID, userID), time.Unix(expires, 0), c.Query("signature")) {
+		apperr.Abort(c, apperr.Forbidden("download link is invalid or has expired"))
+		return
+	}
+
+	// The link carries no token, so the tenant comes from the signed link
+	ctx := c.Request.Context()
+	if tenantID != uuid.Nil {
+		ctx = repositories.WithTenant(ctx, tenantID)
+	}
+	db := h.db.WithContext(ctx)
+
+	attachment, err := h.attachmentService.GetAttachmentByID(db, attachmentID)
+	if err != nil {
+		apperr.Abort(c, apperr.NotFound("attachment not found"))
+		return
+	}
+	task, err := h.taskService.GetTaskByID(db, attachment.TaskID)
+	if err != nil {
+		apperr.Abort(c, apperr.NotFound("attachment not found"))
+		return
+	}
+	actor, err := services.ActorForUser(db, userID)
+	if err != nil {
+		apperr.Abort(c, apperr.Forbidden("download link is invalid or has expired"))
+		return
+	}
+	level, err := h.taskService.GetTaskAccess(db, task, actor)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to resolve task access"))
+		return
+	}
+	if level == models.TaskAccessNone {
+		apperr.Abort(c, apperr.Forbidden("access denied - you can no longer access this task"))
+		return
+	}
+
+	body, err := h.attachmentService.Open(db, attachment)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			apperr.Abort(c, apperr.NotFound("attachment content not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to read attachment"))
+		return
+	}
+	defer body.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   `"` + attachment.Checksum + `"`,
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+// loadAttachment checks the current user's access to the task in :id and loads
+// the attachment in :attachment_id from that task
+func (h *AttachmentHandler) loadAttachment(c *gin.Context, required, verb string) (*models.Attachment, services.Actor, bool) {
+	task, actor, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, required, verb)
+	if !ok {
+		return nil, actor, false
+	}
+
+	attachmentID, err := uuid.FromString(c.Param("attachment_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid attachment ID"))
+		return nil, actor, false
+	}
+
+	attachment, err := h.attachmentService.GetAttachment(requestDB(c, h.db), task.ID, attachmentID)
+	if err != nil {
+		apperr.Abort(c, apperr.NotFound("attachment not found"))
+		return nil, actor, false
+	}
+	return attachment, actor, true
+}
+
+func (h *AttachmentHandler) withDownloadURL(c *gin.Context, attachment models.Attachment, actor services.Actor) AttachmentResponse {
+	tenantID, _ := repositories.TenantFromContext(c.Request.Context())
+	expires := time.Now().Add(h.urlTTL).Truncate(time.Second)
+
+	query := url.Values{}
+	query.Set("tenant", tenantID.String())
+	query.Set("user", actor.UserID.String())
+	query.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	query.Set("signature", utils.SignResource(downloadResource(attachment.ID, tenantID, actor.UserID), expires))
+
+	return AttachmentResponse{
+		Attachment:  attachment,
+		DownloadURL: AttachmentDownloadPath + attachment.ID.String() + "/download?" + query.Encode(),
+		ExpiresAt:   expires,
+	}
+}
+
+func downloadResource(attachmentID, tenantID, userID uuid.UUID) string {
+	return "attachment:" + attachmentID.String() + ":" + tenantID.String() + ":" + userID.String()
+}
@@ -1,8 +1,9 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
+	"task-manager/backend/internal/apperr"
 	"task-manager/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -32,24 +33,22 @@ func NewAuthHandler(db *gorm.DB, authService services.AuthService) *AuthHandler
 func (h *AuthHandler) Token(c *gin.Context) {
 	var req AuthRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Invalid request body: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		apperr.Abort(c, apperr.Binding(err))
 		return
 	}
 
 	// Authenticate user
-	user, err := h.authService.LoginUser(h.db, req.Username, req.Password)
+	user, err := h.authService.LoginUser(requestDB(c, h.db), req.Username, req.Password)
 	if err != nil {
-		log.Printf("Authentication failed: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		slog.WarnContext(c.Request.Context(), "authentication failed", "error", err)
+		apperr.Abort(c, err)
 		return
 	}
 
 	// Generate tokens
-	accessToken, refreshToken, err := h.authService.GenerateToken(h.db, user.ID, user.Username)
+	accessToken, refreshToken, err := h.authService.GenerateToken(requestDB(c, h.db), user.ID, user.Username)
 	if err != nil {
-		log.Printf("Token generation failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate tokens"})
+		apperr.Abort(c, apperr.OrInternal(err, "failed to generate tokens"))
 		return
 	}
 
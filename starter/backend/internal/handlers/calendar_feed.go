@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/repositories"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CalendarFeedPath is where calendar feed URLs point
+const CalendarFeedPath = "/api/v1/calendar/"
+
+type CalendarFeedHandler struct {
+	db              *gorm.DB
+	feedService     services.CalendarFeedService
+	transferService services.TaskTransferService
+}
+
+func NewCalendarFeedHandler(db *gorm.DB, feedService services.CalendarFeedService, transferService services.TaskTransferService) *CalendarFeedHandler {
+	return &CalendarFeedHandler{db: db, feedService: feedService, transferService: transferService}
+}
+
+// CreateFeed gives the current user a new calendar feed URL, revoking the one
+// they had. The URL is only shown in this response.
+func (h *CalendarFeedHandler) CreateFeed(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	token, feed, err := h.feedService.CreateFeed(requestDB(c, h.db), actor.UserID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to create calendar feed"))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"feed": feed, "url": CalendarFeedPath + token + ".ics"})
+}
+
+// GetFeed says whether the current user has a calendar feed and since when
+func (h *CalendarFeedHandler) GetFeed(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	feed, err := h.feedService.GetFeed(requestDB(c, h.db), actor.UserID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get calendar feed"))
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
+
+// RevokeFeed turns off the current user's calendar feed
+func (h *CalendarFeedHandler) RevokeFeed(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.feedService.RevokeFeed(requestDB(c, h.db), actor.UserID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to revoke calendar feed"))
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Feed serves the iCalendar feed of the user the token in the URL belongs to:
+// their own tasks, as ExportTasks writes them with format=ics. Calendar apps
+// cannot send a bearer token, so the URL is the credential.
+func (h *CalendarFeedHandler) Feed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	// The URL carries no tenant, so the feed is looked up across tenants and
+	// the rest of the request is scoped to the feed's
+	feed, err := h.feedService.ResolveFeed(h.db.WithContext(repositories.WithoutTenantScope(c.Request.Context())), token)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get calendar feed"))
+		return
+	}
+	db := h.db.WithContext(repositories.WithTenant(c.Request.Context(), feed.TenantID))
+
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Status(http.StatusOK)
+	err = writeTaskCalendar(c.Writer, "Tasks", func(write func(*models.Task) error) error {
+		return h.transferService.ExportTasks(db, func(query *gorm.DB) *gorm.DB {
+			return query.Where("user_id = ?", feed.UserID)
+		}, write)
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "calendar feed failed", "error", err)
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type CommentHandler struct {
+	db             *gorm.DB
+	taskService    services.TaskService
+	commentService services.CommentService
+}
+
+type CommentRequest struct {
+	Body string `json:"body" binding:"required,max=10000"`
+}
+
+func NewCommentHandler(db *gorm.DB, taskService services.TaskService, commentService services.CommentService) *CommentHandler {
+	return &CommentHandler{db: db, taskService: taskService, commentService: commentService}
+}
+
+// CreateComment posts a comment. Anyone who can see the task can discuss it.
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	task, actor, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessViewer, "comment on")
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	comment := models.Comment{AuthorID: actor.UserID, Body: req.Body}
+	if err := h.commentService.CreateComment(requestDB(c, h.db), task, &comment); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to create comment"))
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// GetComments lists the task's comments, oldest first, one page at a time
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	task, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessViewer, "access")
+	if !ok {
+		return
+	}
+
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	comments, total, err := h.commentService.GetComments(requestDB(c, h.db), task.ID, page)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get comments"))
+		return
+	}
+	c.JSON(http.StatusOK, pageResponse(comments, page, total))
+}
+
+// UpdateComment edits a comment. Only its author can edit it.
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	task, comment, actor, ok := h.loadComment(c)
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	if err := h.commentService.UpdateComment(requestDB(c, h.db), task, comment, req.Body, actor.UserID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to update comment"))
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment removes a comment. Its author and anyone with owner access on
+// the task can delete it.
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	task, comment, actor, ok := h.loadComment(c)
+	if !ok {
+		return
+	}
+
+	if comment.AuthorID != actor.UserID {
+		level, err := h.taskService.GetTaskAccess(requestDB(c, h.db), task, actor)
+		if err != nil {
+			apperr.Abort(c, apperr.OrInternal(err, "failed to resolve task access"))
+			return
+		}
+		if level != models.TaskAccessOwner {
+			apperr.Abort(c, apperr.Forbidden("access denied - you can only delete your own comments"))
+			return
+		}
+	}
+
+	if err := h.commentService.DeleteComment(requestDB(c, h.db), comment.ID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to delete comment"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetCommentHistory lists the earlier versions of a comment
+func (h *CommentHandler) GetCommentHistory(c *gin.Context) {
+	_, comment, _, ok := h.loadComment(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.commentService.GetRevisions(requestDB(c, h.db), comment.ID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get comment history"))
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// loadComment checks that the current user can see the task in :id and loads
+// the comment in :comment_id from that task
+func (h *CommentHandler) loadComment(c *gin.Context) (*models.Task, *models.Comment, services.Actor, bool) {
+	task, actor, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessViewer, "access")
+	if !ok {
+		return nil, nil, actor, false
+	}
+
+	commentID, err := uuid.FromString(c.Param("comment_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid comment ID"))
+		return nil, nil, actor, false
+	}
+
+	comment, err := h.commentService.GetComment(requestDB(c, h.db), task.ID, commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("comment not found"))
+			return nil, nil, actor, false
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get comment"))
+		return nil, nil, actor, false
+	}
+	return task, comment, actor, true
+}
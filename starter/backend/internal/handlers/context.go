@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// requestDB binds db to the request context so its queries are scoped to the
+// tenant resolved by TenantMiddleware and AuthMiddleware
+func requestDB(c *gin.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(c.Request.Context())
+}
+
+// currentActor builds the service actor from the values set by AuthMiddleware.
+// It writes a 401 response and returns false when the context is not authenticated.
+func currentActor(c *gin.Context) (services.Actor, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		apperr.Abort(c, apperr.Unauthorized("user not authenticated"))
+		return services.Actor{}, false
+	}
+
+	var userUUID uuid.UUID
+	switch v := userID.(type) {
+	case uuid.UUID:
+		userUUID = v
+	case string:
+		u, err := uuid.FromString(v)
+		if err != nil {
+			apperr.Abort(c, apperr.Unauthorized("invalid user id in token"))
+			return services.Actor{}, false
+		}
+		userUUID = u
+	default:
+		apperr.Abort(c, apperr.Unauthorized("invalid user id type"))
+		return services.Actor{}, false
+	}
+
+	actor := services.Actor{UserID: userUUID}
+	if roles, exists := c.Get("roles"); exists {
+		if rolesList, ok := roles.([]string); ok {
+			actor.Roles = rolesList
+		}
+	}
+	return actor, true
+}
@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// maxBulkLabelTasks caps how many tasks one bulk label request may touch
+const maxBulkLabelTasks = 500
+
+type LabelHandler struct {
+	db           *gorm.DB
+	labelService services.LabelService
+	taskService  services.TaskService
+}
+
+type LabelRequest struct {
+	Name   string     `json:"name" binding:"required"`
+	Color  string     `json:"color"`
+	TeamID *uuid.UUID `json:"team_id"`
+}
+
+type BulkLabelRequest struct {
+	TaskIDs  []uuid.UUID `json:"task_ids" binding:"required,min=1"`
+	LabelIDs []uuid.UUID `json:"label_ids" binding:"required,min=1"`
+}
+
+func NewLabelHandler(db *gorm.DB, labelService services.LabelService, taskService services.TaskService) *LabelHandler {
+	return &LabelHandler{db: db, labelService: labelService, taskService: taskService}
+}
+
+// CreateLabel creates a personal label, or a team label when team_id is given.
+// Only team admins can create labels for their team.
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	var req LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	label := models.Label{Name: req.Name, Color: req.Color, TeamID: req.TeamID, CreatedBy: actor.UserID}
+	if req.TeamID == nil {
+		label.UserID = &actor.UserID
+	}
+	level, err := h.labelService.GetLabelAccess(requestDB(c, h.db), &label, actor)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to resolve label access"))
+		return
+	}
+	if level != models.TaskAccessOwner {
+		apperr.Abort(c, apperr.Forbidden("access denied - only team admins can create team labels"))
+		return
+	}
+
+	if err := h.labelService.CreateLabel(requestDB(c, h.db), &label); err != nil {
+		writeLabelError(c, err, "failed to create label")
+		return
+	}
+	c.JSON(http.StatusCreated, label)
+}
+
+// GetLabels lists the labels the current user can apply
+func (h *LabelHandler) GetLabels(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	labels, err := h.labelService.GetLabels(requestDB(c, h.db), actor)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get labels"))
+		return
+	}
+	c.JSON(http.StatusOK, labels)
+}
+
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	label, ok := h.loadLabel(c, c.Param("label_id"), models.TaskAccessOwner)
+	if !ok {
+		return
+	}
+
+	var req LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	update := models.Label{Name: req.Name, Color: req.Color}
+	if err := h.labelService.UpdateLabel(requestDB(c, h.db), label.ID, &update); err != nil {
+		writeLabelError(c, err, "failed to update label")
+		return
+	}
+	c.JSON(http.StatusOK, update)
+}
+
+// DeleteLabel deletes a label and removes it from every task
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	label, ok := h.loadLabel(c, c.Param("label_id"), models.TaskAccessOwner)
+	if !ok {
+		return
+	}
+
+	if err := h.labelService.DeleteLabel(requestDB(c, h.db), label.ID); err != nil {
+		writeLabelError(c, err, "failed to delete label")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ApplyLabels puts every label on every task in the request. Nothing changes
+// unless the caller can use all of the labels and edit all of the tasks.
+func (h *LabelHandler) ApplyLabels(c *gin.Context) {
+	req, ok := h.bindBulkRequest(c)
+	if !ok {
+		return
+	}
+	for _, labelID := range req.LabelIDs {
+		if _, ok := h.loadLabel(c, labelID.String(), models.TaskAccessViewer); !ok {
+			return
+		}
+	}
+	actor, ok := h.authorizeTasks(c, req.TaskIDs)
+	if !ok {
+		return
+	}
+
+	if err := h.labelService.ApplyLabels(requestDB(c, h.db), req.TaskIDs, req.LabelIDs, actor.UserID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to apply labels"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveLabels takes every label in the request off every task. Any label on a
+// task the caller can edit may be removed, whoever owns the label.
+func (h *LabelHandler) RemoveLabels(c *gin.Context) {
+	req, ok := h.bindBulkRequest(c)
+	if !ok {
+		return
+	}
+	if _, ok := h.authorizeTasks(c, req.TaskIDs); !ok {
+		return
+	}
+
+	if err := h.labelService.RemoveLabels(requestDB(c, h.db), req.TaskIDs, req.LabelIDs); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to remove labels"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddTaskLabel puts one label on the task in the :id parameter and returns the
+// task's labels
+func (h *LabelHandler) AddTaskLabel(c *gin.Context) {
+	task, actor, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessEditor, "update")
+	if !ok {
+		return
+	}
+	label, ok := h.loadLabel(c, c.Param("label_id"), models.TaskAccessViewer)
+	if !ok {
+		return
+	}
+
+	db := requestDB(c, h.db)
+	if err := h.labelService.ApplyLabels(db, []uuid.UUID{task.ID}, []uuid.UUID{label.ID}, actor.UserID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to apply label"))
+		return
+	}
+	labels, err := h.labelService.GetTaskLabels(db, task.ID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get task labels"))
+		return
+	}
+	c.JSON(http.StatusOK, labels)
+}
+
+func (h *LabelHandler) RemoveTaskLabel(c *gin.Context) {
+	task, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessEditor, "update")
+	if !ok {
+		return
+	}
+	labelID, err := uuid.FromString(c.Param("label_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid label ID"))
+		return
+	}
+
+	if err := h.labelService.RemoveLabels(requestDB(c, h.db), []uuid.UUID{task.ID}, []uuid.UUID{labelID}); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to remove label"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetLabelUsage lists every label with the number of tasks carrying it
+func (h *LabelHandler) GetLabelUsage(c *gin.Context) {
+	usage, err := h.labelService.GetLabelUsage(requestDB(c, h.db))
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get label usage"))
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+// loadLabel fetches a label and makes sure the current user holds at least the
+// required access on it. Labels the user cannot use at all are reported as not
+// found.
+func (h *LabelHandler) loadLabel(c *gin.Context, rawID, required string) (*models.Label, bool) {
+	labelID, err := uuid.FromString(rawID)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid label ID"))
+		return nil, false
+	}
+	actor, ok := currentActor(c)
+	if !ok {
+		return nil, false
+	}
+
+	db := requestDB(c, h.db)
+	label, err := h.labelService.GetLabelByID(db, labelID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("label not found"))
+			return nil, false
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get label"))
+		return nil, false
+	}
+
+	level, err := h.labelService.GetLabelAccess(db, label, actor)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to resolve label access"))
+		return nil, false
+	}
+	if level == models.TaskAccessNone {
+		apperr.Abort(c, apperr.NotFound("label not found"))
+		return nil, false
+	}
+	if models.TaskAccessRank(level) < models.TaskAccessRank(required) {
+		apperr.Abort(c, apperr.Forbidden("access denied - only the label owner or a team admin can change this label"))
+		return nil, false
+	}
+	return label, true
+}
+
+func (h *LabelHandler) bindBulkRequest(c *gin.Context) (BulkLabelRequest, bool) {
+	var req BulkLabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return req, false
+	}
+	req.TaskIDs, req.LabelIDs = uniqueIDs(req.TaskIDs), uniqueIDs(req.LabelIDs)
+	if len(req.TaskIDs) > maxBulkLabelTasks {
+		apperr.Abort(c, apperr.BadRequest("too many tasks in one request"))
+		return req, false
+	}
+	return req, true
+}
+
+// authorizeTasks makes sure the current user can edit every task. The first
+// task that is missing or not editable is named in the error response.
+func (h *LabelHandler) authorizeTasks(c *gin.Context, taskIDs []uuid.UUID) (services.Actor, bool) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return actor, false
+	}
+
+	db := requestDB(c, h.db)
+	for _, taskID := range taskIDs {
+		task, err := h.taskService.GetTaskByID(db, taskID)
+		if err != nil {
+			apperr.Abort(c, apperr.NotFound("task not found").With("task_id", taskID))
+			return actor, false
+		}
+		level, err := h.taskService.GetTaskAccess(db, task, actor)
+		if err != nil {
+			apperr.Abort(c, apperr.OrInternal(err, "failed to resolve task access"))
+			return actor, false
+		}
+		if models.TaskAccessRank(level) < models.TaskAccessRank(models.TaskAccessEditor) {
+			apperr.Abort(c, apperr.Forbidden("access denied - you can only label tasks you can edit").With("task_id", taskID))
+			return actor, false
+		}
+	}
+	return actor, true
+}
+
+func writeLabelError(c *gin.Context, err error, fallback string) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apperr.Abort(c, apperr.NotFound("label not found"))
+		return
+	}
+	apperr.Abort(c, apperr.OrInternal(err, fallback))
+}
+
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type NotificationHandler struct {
+	db                  *gorm.DB
+	notificationService services.NotificationService
+}
+
+func NewNotificationHandler(db *gorm.DB, notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{db: db, notificationService: notificationService}
+}
+
+// GetNotifications lists the current user's notifications, newest first, with
+// their unread count. ?unread=true leaves out the ones already read.
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	db := requestDB(c, h.db)
+	notifications, total, err := h.notificationService.GetNotifications(db, actor.UserID, c.Query("unread") == "true", page)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get notifications"))
+		return
+	}
+	unread, err := h.notificationService.CountUnread(db, actor.UserID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to count unread notifications"))
+		return
+	}
+
+	resp := pageResponse(notifications, page, total)
+	resp["unread"] = unread
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	unread, err := h.notificationService.CountUnread(requestDB(c, h.db), actor.UserID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to count unread notifications"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread": unread})
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	notificationID, err := uuid.FromString(c.Param("notification_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid notification ID"))
+		return
+	}
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.MarkRead(requestDB(c, h.db), actor.UserID, notificationID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("notification not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to mark notification read"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllRead(requestDB(c, h.db), actor.UserID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to mark notifications read"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"strconv"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parsePage reads ?page= (from 1) and ?page_size= (up to 100). It writes a 400
+// response and returns false when either is not a positive number.
+func parsePage(c *gin.Context) (services.Page, bool) {
+	page := services.Page{Number: 1, Size: defaultPageSize}
+	for param, target := range map[string]*int{"page": &page.Number, "page_size": &page.Size} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 {
+			apperr.Abort(c, apperr.BadRequest("invalid "+param+" - expected a positive number"))
+			return page, false
+		}
+		*target = value
+	}
+	if page.Size > maxPageSize {
+		page.Size = maxPageSize
+	}
+	return page, true
+}
+
+// pageResponse wraps one page of a listing with its position and the total count
+func pageResponse(items interface{}, page services.Page, total int64) gin.H {
+	return gin.H{
+		"items":     items,
+		"page":      page.Number,
+		"page_size": page.Size,
+		"total":     total,
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type ProjectHandler struct {
+	db             *gorm.DB
+	projectService services.ProjectService
+	taskService    services.TaskService
+}
+
+type ProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Archived    bool   `json:"archived"`
+}
+
+type ProjectMemberRequest struct {
+	UserID     uuid.UUID `json:"user_id" binding:"required"`
+	Permission string    `json:"permission" binding:"required"`
+}
+
+func NewProjectHandler(db *gorm.DB, projectService services.ProjectService, taskService services.TaskService) *ProjectHandler {
+	return &ProjectHandler{db: db, projectService: projectService, taskService: taskService}
+}
+
+func (h *ProjectHandler) CreateProject(c *gin.Context) {
+	var req ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	project := models.Project{Name: req.Name, Description: req.Description}
+	if err := h.projectService.CreateProject(requestDB(c, h.db), &project, actor.UserID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to create project"))
+		return
+	}
+	c.JSON(http.StatusCreated, project)
+}
+
+// GetProjects lists the current user's projects. Archived projects are only
+// included with ?archived=true.
+func (h *ProjectHandler) GetProjects(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	projects, err := h.projectService.GetProjects(requestDB(c, h.db), actor, c.Query("archived") == "true")
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get projects"))
+		return
+	}
+	c.JSON(http.StatusOK, projects)
+}
+
+func (h *ProjectHandler) GetProject(c *gin.Context) {
+	projectID := uuid.FromStringOrNil(c.Param("project_id"))
+
+	project, err := h.projectService.GetProjectByID(requestDB(c, h.db), projectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("project not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get project"))
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+func (h *ProjectHandler) UpdateProject(c *gin.Context) {
+	projectID := uuid.FromStringOrNil(c.Param("project_id"))
+
+	var req ProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	project := models.Project{Name: req.Name, Description: req.Description, Archived: req.Archived}
+	if err := h.projectService.UpdateProject(requestDB(c, h.db), projectID, &project); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			apperr.Abort(c, apperr.NotFound("project not found"))
+		default:
+			apperr.Abort(c, apperr.OrInternal(err, "failed to update project"))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "project updated successfully"})
+}
+
+func (h *ProjectHandler) DeleteProject(c *gin.Context) {
+	projectID := uuid.FromStringOrNil(c.Param("project_id"))
+
+	if err := h.projectService.DeleteProject(requestDB(c, h.db), projectID); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			apperr.Abort(c, apperr.NotFound("project not found"))
+		default:
+			apperr.Abort(c, apperr.OrInternal(err, "failed to delete project"))
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ProjectHandler) GetMembers(c *gin.Context) {
+	projectID := uuid.FromStringOrNil(c.Param("project_id"))
+
+	members, err := h.projectService.GetMembers(requestDB(c, h.db), projectID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get project members"))
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+func (h *ProjectHandler) SetMember(c *gin.Context) {
+	projectID := uuid.FromStringOrNil(c.Param("project_id"))
+
+	var req ProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	member, err := h.projectService.SetMember(requestDB(c, h.db), projectID, req.UserID, req.Permission)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			apperr.Abort(c, apperr.NotFound("user not found"))
+		default:
+			apperr.Abort(c, apperr.OrInternal(err, "failed to update project member"))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *ProjectHandler) RemoveMember(c *gin.Context) {
+	projectID := uuid.FromStringOrNil(c.Param("project_id"))
+	userID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid user ID"))
+		return
+	}
+
+	if err := h.projectService.RemoveMember(requestDB(c, h.db), projectID, userID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to remove project member"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetProjectTasks lists the project's tasks, accepting the same filters as the other task listings
+func (h *ProjectHandler) GetProjectTasks(c *gin.Context) {
+	projectID := uuid.FromStringOrNil(c.Param("project_id"))
+
+	filter, ok := parseTaskFilter(c)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.projectService.GetProjectTasks(requestDB(c, h.db), projectID, filter)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get project tasks"))
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// CreateProjectTask creates a task inside the project. The creator is recorded in user_id.
+func (h *ProjectHandler) CreateProjectTask(c *gin.Context) {
+	projectID := uuid.FromStringOrNil(c.Param("project_id"))
+
+	var task models.Task
+	if err := c.ShouldBindJSON(&task); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	newID, err := uuid.NewV4()
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to generate task ID"))
+		return
+	}
+	task.ID = newID
+	task.UserID = actor.UserID
+	task.TeamID = nil
+	task.ProjectID = &projectID
+	// Subtasks are created through POST /tasks, which checks access to the parent
+	task.ParentID = nil
+
+	if err := h.taskService.CreateTask(requestDB(c, h.db), &task); err != nil {
+		apperr.Abort(c, createTaskError(err))
+		return
+	}
+	c.JSON(http.StatusCreated, task)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RecurrenceHandler struct {
+	db                *gorm.DB
+	taskService       services.TaskService
+	recurrenceService services.RecurrenceService
+}
+
+// RecurrenceRequest carries an iCalendar RRULE such as "FREQ=WEEKLY;BYDAY=MO".
+// An empty rule stops the task from recurring.
+type RecurrenceRequest struct {
+	Recurrence string `json:"recurrence"`
+}
+
+func NewRecurrenceHandler(db *gorm.DB, taskService services.TaskService, recurrenceService services.RecurrenceService) *RecurrenceHandler {
+	return &RecurrenceHandler{db: db, taskService: taskService, recurrenceService: recurrenceService}
+}
+
+// SetRecurrence changes the schedule of this and all following occurrences
+func (h *RecurrenceHandler) SetRecurrence(c *gin.Context) {
+	task, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessEditor, "update")
+	if !ok {
+		return
+	}
+
+	var req RecurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	updated, err := h.recurrenceService.SetRecurrence(requestDB(c, h.db), task.ID, req.Recurrence)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("task not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to update recurrence"))
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
@@ -1,8 +1,9 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
+	"task-manager/backend/internal/apperr"
 
 	"task-manager/backend/internal/services"
 
@@ -32,16 +33,15 @@ func NewRefreshHandler(db *gorm.DB, authService services.AuthService) *RefreshHa
 func (h *RefreshHandler) Refresh(c *gin.Context) {
 	var req RefreshRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Invalid request body: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		apperr.Abort(c, apperr.Binding(err))
 		return
 	}
 
 	// Refresh the token
-	accessToken, refreshToken, err := h.authService.RefreshToken(h.db, req.RefreshToken)
+	accessToken, refreshToken, err := h.authService.RefreshToken(requestDB(c, h.db), req.RefreshToken)
 	if err != nil {
-		log.Printf("Token refresh failed: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		slog.WarnContext(c.Request.Context(), "token refresh failed", "error", err)
+		apperr.Abort(c, err)
 		return
 	}
 
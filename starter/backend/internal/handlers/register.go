@@ -3,6 +3,7 @@ package handlers
 import (
 	"errors"
 	"net/http"
+	"task-manager/backend/internal/apperr"
 	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/services"
 
@@ -30,20 +31,20 @@ func NewRegisterHandler(db *gorm.DB, registerService services.RegisterService) *
 func (h *RegisterHandler) Registration(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apperr.Abort(c, apperr.Binding(err))
 		return
 	}
 
 	// Check if username already exists
 	var existingUser models.User
-	if err := h.db.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "username already exists"})
+	if err := requestDB(c, h.db).Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
+		apperr.Abort(c, apperr.Conflict("username already exists"))
 		return
 	}
 
 	// Check if email already exists
-	if err := h.db.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "email already exists"})
+	if err := requestDB(c, h.db).Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
+		apperr.Abort(c, apperr.Conflict("email already exists"))
 		return
 	}
 
@@ -54,8 +55,8 @@ func (h *RegisterHandler) Registration(c *gin.Context) {
 		Password: req.Password,
 	}
 
-	if err := h.registerService.RegisterUser(h.db, user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create user"})
+	if err := h.registerService.RegisterUser(requestDB(c, h.db), user); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to create user"))
 		return
 	}
 
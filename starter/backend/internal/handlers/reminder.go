@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ReminderHandler struct {
+	db              *gorm.DB
+	reminderService services.ReminderService
+}
+
+// ReminderPreferenceRequest changes the fields it carries and leaves the others
+// as they are
+type ReminderPreferenceRequest struct {
+	Enabled     *bool    `json:"enabled"`
+	LeadMinutes *int     `json:"lead_minutes"`
+	Overdue     *bool    `json:"overdue"`
+	Channels    []string `json:"channels"`
+	WebhookURL  *string  `json:"webhook_url"`
+}
+
+func NewReminderHandler(db *gorm.DB, reminderService services.ReminderService) *ReminderHandler {
+	return &ReminderHandler{db: db, reminderService: reminderService}
+}
+
+// GetPreferences returns the current user's reminder settings
+func (h *ReminderHandler) GetPreferences(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	pref, err := h.reminderService.GetPreference(requestDB(c, h.db), actor.UserID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get reminder preferences"))
+		return
+	}
+	c.JSON(http.StatusOK, pref)
+}
+
+func (h *ReminderHandler) UpdatePreferences(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req ReminderPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	db := requestDB(c, h.db)
+	pref, err := h.reminderService.GetPreference(db, actor.UserID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get reminder preferences"))
+		return
+	}
+	if req.Enabled != nil {
+		pref.Enabled = *req.Enabled
+	}
+	if req.LeadMinutes != nil {
+		pref.LeadMinutes = *req.LeadMinutes
+	}
+	if req.Overdue != nil {
+		pref.Overdue = *req.Overdue
+	}
+	if req.Channels != nil {
+		pref.Channels = req.Channels
+	}
+	if req.WebhookURL != nil {
+		pref.WebhookURL = *req.WebhookURL
+	}
+
+	if err := h.reminderService.SetPreference(db, pref); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to save reminder preferences"))
+		return
+	}
+	c.JSON(http.StatusOK, pref)
+}
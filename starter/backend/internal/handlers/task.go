@@ -1,20 +1,22 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/metrics"
 	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/services"
 
-	"log"
-
 	"github.com/gin-gonic/gin"
 	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
 )
 
 type TaskHandler struct {
-	db          *gorm.DB
-	taskService services.TaskService
+	db             *gorm.DB
+	taskService    services.TaskService
+	requireIfMatch bool
 }
 
 func NewTaskHandler(db *gorm.DB, taskService services.TaskService) *TaskHandler {
@@ -24,219 +26,301 @@ func NewTaskHandler(db *gorm.DB, taskService services.TaskService) *TaskHandler
 func (h *TaskHandler) CreateTask(c *gin.Context) {
 	var task models.Task
 	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apperr.Abort(c, apperr.Binding(err))
 		return
 	}
 
-	// Get user ID from the JWT token
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
-		return
-	}
-
-	var userUUID uuid.UUID
-	switch v := userID.(type) {
-	case uuid.UUID:
-		userUUID = v
-	case string:
-		u, err := uuid.FromString(v)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id in token"})
-			return
-		}
-		userUUID = u
-	default:
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id type"})
+	actor, ok := currentActor(c)
+	if !ok {
 		return
 	}
+	userUUID := actor.UserID
 
 	// Set the user ID from the token
 	task.UserID = userUUID
 
-	// Debug logging
-	log.Printf("[DEBUG] userUUID: %v (type: %T)", userUUID, userUUID)
-	log.Printf("[DEBUG] task: %+v", task)
+	if appErr := h.checkNewTask(requestDB(c, h.db), &task, actor); appErr != nil {
+		apperr.Abort(c, appErr)
+		return
+	}
 
 	// Generate a new UUID for the task if not set
 	if task.ID == uuid.Nil {
 		newID, err := uuid.NewV4()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate task ID"})
+			apperr.Abort(c, apperr.OrInternal(err, "failed to generate task ID"))
 			return
 		}
 		task.ID = newID
 	}
 
-	if err := h.taskService.CreateTask(h.db, &task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.taskService.CreateTask(requestDB(c, h.db), &task); err != nil {
+		apperr.Abort(c, createTaskError(err))
 		return
 	}
 	c.JSON(http.StatusCreated, task)
 }
 
-func (h *TaskHandler) GetTaskByID(c *gin.Context) {
-	id := c.Param("id")
-	taskID, err := uuid.FromString(id)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
-		return
+// createTaskError is the error a failed task creation is answered with. The
+// only record the service looks up without a domain error of its own is the
+// task's project.
+func createTaskError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperr.NotFound("project not found")
 	}
+	return apperr.OrInternal(err, "failed to create task")
+}
 
-	// Get user info from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
-		return
+// checkNewTask checks that the actor may create the task: tasks owned by a
+// team need team membership, tasks in a project need project editor access and
+// subtasks need editor access on their parent
+func (h *TaskHandler) checkNewTask(db *gorm.DB, task *models.Task, actor services.Actor) *apperr.Error {
+	if task.TeamID != nil && !actor.IsAdmin() {
+		if _, err := services.TeamMemberRole(db, *task.TeamID, actor.UserID); err != nil {
+			return apperr.Forbidden("access denied - team membership required")
+		}
 	}
-
-	userUUID := userID.(uuid.UUID)
-
-	// Check if user is admin
-	userRoles, exists := c.Get("roles")
-	isAdmin := false
-	if exists {
-		rolesList := userRoles.([]string)
-		for _, role := range rolesList {
-			if role == "admin" {
-				isAdmin = true
-				break
-			}
+	if task.ProjectID != nil {
+		if appErr := checkProjectEditor(db, *task.ProjectID, actor); appErr != nil {
+			return appErr
 		}
 	}
+	if task.ParentID != nil {
+		return h.checkParentEditor(db, *task.ParentID, actor)
+	}
+	return nil
+}
 
-	task, err := h.taskService.GetTaskByID(h.db, taskID)
+// checkParentEditor checks that the actor may attach subtasks to the parent task
+func (h *TaskHandler) checkParentEditor(db *gorm.DB, parentID uuid.UUID, actor services.Actor) *apperr.Error {
+	parent, err := h.taskService.GetTaskByID(db, parentID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
-		return
+		return apperr.NotFound("parent task not found")
+	}
+	level, err := h.taskService.GetTaskAccess(db, parent, actor)
+	if err != nil {
+		return apperr.Internal(err, "failed to resolve task access")
+	}
+	if models.TaskAccessRank(level) < models.TaskAccessRank(models.TaskAccessEditor) {
+		return apperr.Forbidden("access denied - parent task editor access required")
+	}
+	return nil
+}
+
+// checkProjectEditor checks that the actor may add tasks to a project
+func checkProjectEditor(db *gorm.DB, projectID uuid.UUID, actor services.Actor) *apperr.Error {
+	if actor.IsAdmin() {
+		return nil
+	}
+	level, err := services.ProjectMemberPermission(db, projectID, actor.UserID)
+	if err != nil {
+		return apperr.Internal(err, "failed to resolve project access")
 	}
+	if models.TaskAccessRank(level) < models.TaskAccessRank(models.TaskAccessEditor) {
+		return apperr.Forbidden("access denied - project editor access required")
+	}
+	return nil
+}
 
-	// Enforce ownership: only task owner or admin can access
-	if !isAdmin && task.UserID != userUUID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied - you can only access your own tasks"})
+func (h *TaskHandler) GetTaskByID(c *gin.Context) {
+	task, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessViewer, "access")
+	if !ok {
+		return
+	}
+	if notModified(c, task) {
 		return
 	}
 
+	c.Header("ETag", taskETag(task))
 	c.JSON(http.StatusOK, task)
 }
 
-func (h *TaskHandler) GetTasks(c *gin.Context) {
-	// This endpoint is already protected by RequireRoleAndPermission("admin", "tasks", "read")
-	// So only admins can access all tasks
-	tasks, err := h.taskService.GetTasks(h.db)
+// GetTaskTree returns the task with all of its subtasks, recursively, and
+// their progress. Access to a task covers its whole subtree.
+func (h *TaskHandler) GetTaskTree(c *gin.Context) {
+	task, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessViewer, "access")
+	if !ok {
+		return
+	}
+
+	tree, err := h.taskService.GetTaskTree(requestDB(c, h.db), task.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		apperr.Abort(c, apperr.OrInternal(err, "failed to load task tree"))
 		return
 	}
-	c.JSON(http.StatusOK, tasks)
+	c.JSON(http.StatusOK, tree)
 }
 
-func (h *TaskHandler) UpdateTask(c *gin.Context) {
-	id := c.Param("id")
-	taskID, err := uuid.FromString(id)
+// authorizeTask loads the task named by the :id parameter and checks that the
+// current user holds at least the required access level on it, either as owner,
+// admin or through a share. It writes the error response itself and returns
+// false when the request must stop.
+func authorizeTask(c *gin.Context, db *gorm.DB, taskService services.TaskService, required, verb string) (*models.Task, services.Actor, bool) {
+	taskID, err := uuid.FromString(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
-		return
+		apperr.Abort(c, apperr.BadRequest("invalid task ID"))
+		return nil, services.Actor{}, false
 	}
 
-	// Get user info from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
-		return
+	actor, ok := currentActor(c)
+	if !ok {
+		return nil, actor, false
 	}
 
-	userUUID := userID.(uuid.UUID)
+	task, appErr := checkTaskAccess(db, taskService, taskID, actor, required, verb)
+	if appErr != nil {
+		apperr.Abort(c, appErr)
+		return nil, actor, false
+	}
+	return task, actor, true
+}
 
-	// Check if user is admin
-	userRoles, exists := c.Get("roles")
-	isAdmin := false
-	if exists {
-		rolesList := userRoles.([]string)
-		for _, role := range rolesList {
-			if role == "admin" {
-				isAdmin = true
-				break
-			}
-		}
+// checkTaskAccess loads the task and checks that the actor holds at least the
+// required access level on it
+func checkTaskAccess(db *gorm.DB, taskService services.TaskService, taskID uuid.UUID, actor services.Actor, required, verb string) (*models.Task, *apperr.Error) {
+	task, err := taskService.GetTaskByID(db, taskID)
+	if err != nil {
+		return nil, services.ErrTaskNotFound
+	}
+
+	level, err := taskService.GetTaskAccess(db, task, actor)
+	if err != nil {
+		return nil, apperr.Internal(err, "failed to resolve task access")
+	}
+
+	// Enforce ownership: only the owner, an admin or a user the task is shared with can proceed
+	if models.TaskAccessRank(level) < models.TaskAccessRank(required) {
+		metrics.RecordDenial("task:" + required)
+		return nil, apperr.Forbidden("access denied - you can only " + verb + " your own tasks or tasks shared with you")
 	}
+	return task, nil
+}
 
-	// Get the existing task to check ownership
-	existingTask, err := h.taskService.GetTaskByID(h.db, taskID)
+func (h *TaskHandler) GetTasks(c *gin.Context) {
+	// This endpoint is already protected by RequireRoleAndPermission("admin", "tasks", "read")
+	// So only admins can access all tasks
+	filter, ok := parseTaskFilter(c)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.taskService.GetTasks(requestDB(c, h.db), filter)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get tasks"))
 		return
 	}
+	c.JSON(http.StatusOK, tasks)
+}
 
-	// Enforce ownership: only task owner or admin can update
-	if !isAdmin && existingTask.UserID != userUUID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied - you can only update your own tasks"})
+// UpdateTask replaces the task with the request body. Every updatable field is
+// written and those left out are cleared, except the owner, which is kept.
+// Only admins can reassign a task to another user or team; the owner and team
+// others send are ignored.
+func (h *TaskHandler) UpdateTask(c *gin.Context) {
+	existingTask, actor, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessEditor, "update")
+	if !ok {
 		return
 	}
 
 	var task models.Task
 	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apperr.Abort(c, apperr.Binding(err))
 		return
 	}
-
-	// Ensure the task belongs to the same user (unless admin)
-	if !isAdmin {
-		task.UserID = userUUID
+	if task.Status == "" || task.Priority == "" {
+		apperr.Abort(c, apperr.BadRequest("status and priority are required - PUT replaces the whole task, use PATCH to change single fields"))
+		return
 	}
 
-	if err := h.taskService.UpdateTask(h.db, taskID, &task); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	ifVersion, ok := h.ifMatchVersion(c, existingTask)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "task updated successfully"})
-}
-
-func (h *TaskHandler) DeleteTask(c *gin.Context) {
-	id := c.Param("id")
-	taskID, err := uuid.FromString(id)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task ID"})
+	opts, ok := parseUpdateOptions(c)
+	if !ok {
 		return
 	}
+	opts.IfVersion = ifVersion
+	for _, field := range services.UpdatableTaskFields {
+		switch {
+		case (field == "user_id" || field == "team_id") && !actor.IsAdmin():
+		case field == "user_id" && task.UserID == uuid.Nil:
+		default:
+			opts.Fields = append(opts.Fields, field)
+		}
+	}
 
-	// Get user info from context
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+	if appErr := h.checkMove(requestDB(c, h.db), existingTask, &task, actor); appErr != nil {
+		apperr.Abort(c, appErr)
 		return
 	}
+	if err := h.taskService.UpdateTask(requestDB(c, h.db), existingTask.ID, &task, opts); err != nil {
+		h.writeUpdateError(c, existingTask.ID, err)
+		return
+	}
+	if updated, err := h.taskService.GetTaskByID(requestDB(c, h.db), existingTask.ID); err == nil {
+		c.Header("ETag", taskETag(updated))
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "task updated successfully"})
+}
 
-	userUUID := userID.(uuid.UUID)
+// parseUpdateOptions reads the options shared by PUT and PATCH: ?force=true
+// completes a task even while blockers or subtasks are still open, and
+// ?scope=following carries the change over to later occurrences of a
+// recurring task. It writes a 400 response and returns false on a bad scope.
+func parseUpdateOptions(c *gin.Context) (services.UpdateOptions, bool) {
+	opts := services.UpdateOptions{Force: c.Query("force") == "true"}
+	switch c.DefaultQuery("scope", "this") {
+	case "this":
+	case "following":
+		opts.Following = true
+	default:
+		apperr.Abort(c, apperr.BadRequest("scope must be this or following"))
+		return opts, false
+	}
+	return opts, true
+}
 
-	// Check if user is admin
-	userRoles, exists := c.Get("roles")
-	isAdmin := false
-	if exists {
-		rolesList := userRoles.([]string)
-		for _, role := range rolesList {
-			if role == "admin" {
-				isAdmin = true
-				break
-			}
+// checkMove checks that the actor may move the task into the project and
+// under the parent given in task, when those differ from the existing ones.
+// That takes editor access on the new project or parent.
+func (h *TaskHandler) checkMove(db *gorm.DB, existingTask, task *models.Task, actor services.Actor) *apperr.Error {
+	if task.ProjectID != nil && (existingTask.ProjectID == nil || *task.ProjectID != *existingTask.ProjectID) {
+		if appErr := checkProjectEditor(db, *task.ProjectID, actor); appErr != nil {
+			return appErr
 		}
 	}
+	if task.ParentID != nil && (existingTask.ParentID == nil || *task.ParentID != *existingTask.ParentID) {
+		return h.checkParentEditor(db, *task.ParentID, actor)
+	}
+	return nil
+}
 
-	// Get the existing task to check ownership
-	existingTask, err := h.taskService.GetTaskByID(h.db, taskID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+// writeUpdateError answers a failed PUT or PATCH
+func (h *TaskHandler) writeUpdateError(c *gin.Context, taskID uuid.UUID, err error) {
+	if errors.Is(err, services.ErrVersionMismatch) {
+		h.writeCurrentTask(c, taskID)
 		return
 	}
+	apperr.Abort(c, apperr.OrInternal(err, "failed to update task"))
+}
 
-	// Enforce ownership: only task owner or admin can delete
-	if !isAdmin && existingTask.UserID != userUUID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied - you can only delete your own tasks"})
+func (h *TaskHandler) DeleteTask(c *gin.Context) {
+	existingTask, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessOwner, "delete")
+	if !ok {
+		return
+	}
+	ifVersion, ok := h.ifMatchVersion(c, existingTask)
+	if !ok {
 		return
 	}
 
-	if err := h.taskService.DeleteTask(h.db, taskID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.taskService.DeleteTask(requestDB(c, h.db), existingTask.ID, ifVersion); err != nil {
+		if errors.Is(err, services.ErrVersionMismatch) {
+			h.writeCurrentTask(c, existingTask.ID)
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to delete task"))
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
@@ -246,41 +330,45 @@ func (h *TaskHandler) GetTasksByUser(c *gin.Context) {
 	userID := c.Param("user_id")
 	userUUID, err := uuid.FromString(userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		apperr.Abort(c, apperr.BadRequest("invalid user ID"))
 		return
 	}
 
-	// Get current user info from context
-	currentUserID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+	actor, ok := currentActor(c)
+	if !ok {
 		return
 	}
 
-	currentUserUUID := currentUserID.(uuid.UUID)
+	filter, ok := parseTaskFilter(c)
+	if !ok {
+		return
+	}
 
-	// Check if current user is admin
-	userRoles, exists := c.Get("roles")
-	isAdmin := false
-	if exists {
-		rolesList := userRoles.([]string)
-		for _, role := range rolesList {
-			if role == "admin" {
-				isAdmin = true
-				break
-			}
-		}
+	// Users see their own tasks in full; for anyone else's tasks they only see
+	// the ones shared with them. Admins can access any user's tasks.
+	tasks, err := h.taskService.GetTasksByUser(requestDB(c, h.db), userUUID, actor, filter)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get tasks"))
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// GetSharedTasks lists tasks other users have shared with the current user
+func (h *TaskHandler) GetSharedTasks(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
 	}
 
-	// Enforce access control: users can only access their own tasks, admins can access any user's tasks
-	if !isAdmin && currentUserUUID != userUUID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied - you can only access your own tasks"})
+	filter, ok := parseTaskFilter(c)
+	if !ok {
 		return
 	}
 
-	var tasks []models.Task
-	if err := h.db.Where("user_id = ?", userUUID).Find(&tasks).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	tasks, err := h.taskService.GetSharedTasks(requestDB(c, h.db), actor, filter)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get shared tasks"))
 		return
 	}
 	c.JSON(http.StatusOK, tasks)
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/metrics"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/patch"
+	"task-manager/backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Modes of a bulk request
+const (
+	bulkAllOrNothing = "all_or_nothing"
+	bulkBestEffort   = "best_effort"
+)
+
+// maxBulkOperations caps the operations of one bulk request, counting each
+// task a filter matches as one
+const maxBulkOperations = 1000
+
+// errBulkFailed aborts the transaction of an all-or-nothing bulk request
+var errBulkFailed = errors.New("bulk operation failed")
+
+type bulkRequest struct {
+	Mode       string          `json:"mode"`
+	Operations []bulkOperation `json:"operations"`
+	Filter     *bulkFilter     `json:"filter"`
+	Update     json.RawMessage `json:"update"`
+}
+
+// bulkOperation creates a task from Task, applies Task as a merge patch to the
+// task with ID, or deletes it. A non-zero Version works like If-Match, and
+// like If-Match it is required for updates and deletes when the handler
+// requires If-Match.
+type bulkOperation struct {
+	Op      string          `json:"op"`
+	ID      uuid.UUID       `json:"id"`
+	Version int             `json:"version"`
+	Task    json.RawMessage `json:"task"`
+}
+
+// bulkFilter selects tasks like the listing filters do
+type bulkFilter struct {
+	Status    string     `json:"status"`
+	Priority  string     `json:"priority"`
+	DueBefore *time.Time `json:"due_before"`
+	DueAfter  *time.Time `json:"due_after"`
+	Search    string     `json:"q"`
+	Labels    []string   `json:"labels"`
+	LabelMode string     `json:"label_mode"`
+}
+
+type bulkResult struct {
+	Index  int          `json:"index"`
+	Op     string       `json:"op"`
+	ID     *uuid.UUID   `json:"id,omitempty"`
+	Status int          `json:"status"`
+	Code   string       `json:"code,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Task   *models.Task `json:"task,omitempty"`
+}
+
+func (r bulkResult) failed() bool {
+	return r.Status >= http.StatusBadRequest
+}
+
+// BulkTasks applies a list of create, update and delete operations, or one
+// update to every task a filter matches, and reports a result per operation.
+// Each operation gets the permission and access checks of its single-task
+// endpoint. In all_or_nothing mode, the default, the operations share one
+// transaction that the first failure rolls back; in best_effort mode each
+// stands on its own. ?force and ?scope apply to every update as on PATCH.
+func (h *TaskHandler) BulkTasks(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	var req bulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = bulkAllOrNothing
+	}
+	if req.Mode != bulkAllOrNothing && req.Mode != bulkBestEffort {
+		apperr.Abort(c, apperr.BadRequest("mode must be all_or_nothing or best_effort"))
+		return
+	}
+	opts, ok := parseUpdateOptions(c)
+	if !ok {
+		return
+	}
+
+	operations, appErr := h.bulkOperations(requestDB(c, h.db), &req, actor)
+	if appErr != nil {
+		apperr.Abort(c, appErr)
+		return
+	}
+	permissions, _ := c.Get("permissions")
+	granted, _ := permissions.([]string)
+
+	results := make([]bulkResult, len(operations))
+	committed := true
+	if req.Mode == bulkBestEffort {
+		db := requestDB(c, h.db)
+		for i, operation := range operations {
+			results[i] = h.runBulkOperation(db, i, operation, actor, granted, opts)
+		}
+	} else {
+		ctx, release := services.HoldTaskEvents(c.Request.Context())
+		failed := -1
+		err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for i, operation := range operations {
+				if results[i] = h.runBulkOperation(tx, i, operation, actor, granted, opts); results[i].failed() {
+					failed = i
+					return errBulkFailed
+				}
+			}
+			return nil
+		})
+		release(err == nil)
+		if err != nil && failed < 0 {
+			apperr.Abort(c, apperr.OrInternal(err, "failed to apply bulk operations"))
+			return
+		}
+		if failed >= 0 {
+			committed = false
+			for i := range results {
+				switch {
+				case i < failed:
+					results[i].Status, results[i].Code, results[i].Error, results[i].Task = http.StatusFailedDependency, "rolled_back", fmt.Sprintf("rolled back - operation %d failed", failed), nil
+				case i > failed:
+					results[i] = bulkResult{Index: i, Op: operations[i].Op, Status: http.StatusFailedDependency, Code: "not_attempted", Error: fmt.Sprintf("not attempted - operation %d failed", failed)}
+					if operations[i].ID != uuid.Nil {
+						results[i].ID = &operations[i].ID
+					}
+				}
+			}
+		}
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if !result.failed() {
+			succeeded++
+		}
+	}
+	status := http.StatusOK
+	switch {
+	case !committed:
+		status = http.StatusUnprocessableEntity
+	case succeeded < len(results):
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"mode":      req.Mode,
+		"committed": committed,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	})
+}
+
+// bulkOperations returns the operations of the request, turning a filter and
+// update into one update operation per matching task the actor can see
+func (h *TaskHandler) bulkOperations(db *gorm.DB, req *bulkRequest, actor services.Actor) ([]bulkOperation, *apperr.Error) {
+	if (len(req.Operations) > 0) == (req.Filter != nil) {
+		return nil, apperr.BadRequest("send either operations or a filter with an update")
+	}
+	if req.Filter == nil {
+		if len(req.Operations) > maxBulkOperations {
+			return nil, apperr.BadRequest(fmt.Sprintf("at most %d operations are allowed", maxBulkOperations))
+		}
+		return req.Operations, nil
+	}
+
+	if len(req.Update) == 0 {
+		return nil, apperr.BadRequest("a filter needs an update")
+	}
+	filter, appErr := req.Filter.taskFilter()
+	if appErr != nil {
+		return nil, appErr
+	}
+	var matches []struct {
+		ID      uuid.UUID
+		Version int
+	}
+	err := db.Model(&models.Task{}).
+		Scopes(services.VisibleTasks(db, actor), filter.Scope).
+		Select("id, version").
+		Order("created_at, id").
+		Limit(maxBulkOperations + 1).
+		Find(&matches).Error
+	if err != nil {
+		return nil, apperr.Internal(err, "failed to find tasks")
+	}
+	if len(matches) > maxBulkOperations {
+		return nil, apperr.BadRequest(fmt.Sprintf("filter matches more than %d tasks - narrow it down", maxBulkOperations))
+	}
+
+	// Each update expects the version the filter saw, so a task changed in
+	// the meantime fails instead of being overwritten
+	operations := make([]bulkOperation, len(matches))
+	for i, match := range matches {
+		operations[i] = bulkOperation{Op: "update", ID: match.ID, Version: match.Version, Task: req.Update}
+	}
+	return operations, nil
+}
+
+// taskFilter validates the filter like parseTaskFilter does the query string
+func (f *bulkFilter) taskFilter() (services.TaskFilter, *apperr.Error) {
+	filter := services.TaskFilter{
+		Status:    f.Status,
+		Priority:  f.Priority,
+		DueBefore: f.DueBefore,
+		DueAfter:  f.DueAfter,
+		Search:    f.Search,
+		Labels:    f.Labels,
+		LabelMode: f.LabelMode,
+	}
+	if filter.Status != "" && !models.ValidTaskStatus(filter.Status) {
+		return filter, services.ErrInvalidStatus
+	}
+	if filter.Priority != "" && !models.ValidTaskPriority(filter.Priority) {
+		return filter, services.ErrInvalidPriority
+	}
+	switch filter.LabelMode {
+	case "":
+		filter.LabelMode = services.LabelMatchAny
+	case services.LabelMatchAny, services.LabelMatchAll:
+	default:
+		return filter, apperr.Invalid("label_mode", "invalid_label_mode", "label_mode must be any or all")
+	}
+	return filter, nil
+}
+
+// runBulkOperation applies one operation and reports how it went
+func (h *TaskHandler) runBulkOperation(db *gorm.DB, index int, operation bulkOperation, actor services.Actor, permissions []string, opts services.UpdateOptions) bulkResult {
+	result := bulkResult{Index: index, Op: operation.Op}
+	if operation.ID != uuid.Nil {
+		result.ID = &operation.ID
+	}
+	fail := func(appErr *apperr.Error) bulkResult {
+		result.Status, result.Code, result.Error = appErr.Status(), appErr.Code, appErr.Message
+		return result
+	}
+
+	if operation.Op != "create" && operation.Op != "update" && operation.Op != "delete" {
+		return fail(apperr.BadRequest("op must be create, update or delete"))
+	}
+	if !slices.Contains(permissions, "tasks:"+operation.Op) {
+		metrics.RecordDenial("tasks:" + operation.Op)
+		return fail(apperr.Forbidden("insufficient permissions - tasks:" + operation.Op + " required"))
+	}
+	if operation.Op != "create" && operation.ID == uuid.Nil {
+		return fail(apperr.BadRequest("id is required"))
+	}
+	// version stands in for If-Match, so it is required when If-Match is
+	if operation.Op != "create" && operation.Version == 0 && h.requireIfMatch {
+		return fail(apperr.New(apperr.KindPreconditionRequired, "if_match_required", "version required - send the task's current version"))
+	}
+
+	switch operation.Op {
+	case "create":
+		var task models.Task
+		if err := json.Unmarshal(operation.Task, &task); err != nil {
+			return fail(apperr.BadRequest("task must be a task object"))
+		}
+		task.UserID = actor.UserID
+		if appErr := h.checkNewTask(db, &task, actor); appErr != nil {
+			return fail(appErr)
+		}
+		if task.ID == uuid.Nil {
+			id, err := uuid.NewV4()
+			if err != nil {
+				return fail(apperr.Internal(err, "failed to generate task ID"))
+			}
+			task.ID = id
+		}
+		if err := h.taskService.CreateTask(db, &task); err != nil {
+			return fail(apperr.From(createTaskError(err)))
+		}
+		result.ID, result.Status, result.Task = &task.ID, http.StatusCreated, &task
+
+	case "update":
+		existingTask, appErr := checkTaskAccess(db, h.taskService, operation.ID, actor, models.TaskAccessEditor, "update")
+		if appErr != nil {
+			return fail(appErr)
+		}
+		if operation.Version > 0 && operation.Version != existingTask.Version {
+			return fail(services.ErrVersionMismatch)
+		}
+		task, fields, appErr := patchTask(existingTask, patch.Merge, operation.Task, actor)
+		if appErr != nil {
+			return fail(appErr)
+		}
+		if appErr := h.checkMove(db, existingTask, task, actor); appErr != nil {
+			return fail(appErr)
+		}
+		if len(fields) > 0 {
+			opts.IfVersion, opts.Fields = operation.Version, fields
+			if err := h.taskService.UpdateTask(db, existingTask.ID, task, opts); err != nil {
+				return fail(bulkTaskError(err, "failed to update task"))
+			}
+		}
+		updated, err := h.taskService.GetTaskByID(db, existingTask.ID)
+		if err != nil {
+			return fail(apperr.Internal(err, "failed to get task"))
+		}
+		result.Status, result.Task = http.StatusOK, updated
+
+	case "delete":
+		if _, appErr := checkTaskAccess(db, h.taskService, operation.ID, actor, models.TaskAccessOwner, "delete"); appErr != nil {
+			return fail(appErr)
+		}
+		if err := h.taskService.DeleteTask(db, operation.ID, operation.Version); err != nil {
+			return fail(bulkTaskError(err, "failed to delete task"))
+		}
+		result.Status = http.StatusNoContent
+	}
+	return result
+}
+
+// bulkTaskError reports a failed service call, keeping unexpected errors to
+// the generic message
+func bulkTaskError(err error, message string) *apperr.Error {
+	return apperr.From(apperr.OrInternal(err, message))
+}
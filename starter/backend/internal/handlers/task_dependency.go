@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type TaskDependencyHandler struct {
+	db                *gorm.DB
+	taskService       services.TaskService
+	dependencyService services.TaskDependencyService
+}
+
+type TaskDependencyRequest struct {
+	BlockedByID uuid.UUID `json:"blocked_by_id" binding:"required"`
+}
+
+func NewTaskDependencyHandler(db *gorm.DB, taskService services.TaskService, dependencyService services.TaskDependencyService) *TaskDependencyHandler {
+	return &TaskDependencyHandler{db: db, taskService: taskService, dependencyService: dependencyService}
+}
+
+// AddDependency marks the task as blocked by another task. The caller needs
+// editor access on the blocked task and must be able to see the blocker.
+func (h *TaskDependencyHandler) AddDependency(c *gin.Context) {
+	task, actor, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessEditor, "update")
+	if !ok {
+		return
+	}
+
+	var req TaskDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	blocker, err := h.taskService.GetTaskByID(requestDB(c, h.db), req.BlockedByID)
+	if err != nil {
+		apperr.Abort(c, apperr.NotFound(services.ErrBlockerNotFound.Error()))
+		return
+	}
+	level, err := h.taskService.GetTaskAccess(requestDB(c, h.db), blocker, actor)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to resolve task access"))
+		return
+	}
+	if level == models.TaskAccessNone {
+		apperr.Abort(c, apperr.Forbidden("access denied - you can only depend on tasks you can see"))
+		return
+	}
+
+	dependency, err := h.dependencyService.AddDependency(requestDB(c, h.db), task.ID, blocker.ID, actor.UserID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to add dependency"))
+		return
+	}
+	c.JSON(http.StatusCreated, dependency)
+}
+
+// GetDependencies lists the tasks blocking this task and the tasks it blocks
+func (h *TaskDependencyHandler) GetDependencies(c *gin.Context) {
+	task, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessViewer, "access")
+	if !ok {
+		return
+	}
+
+	deps, err := h.dependencyService.GetDependencies(requestDB(c, h.db), task.ID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get dependencies"))
+		return
+	}
+	c.JSON(http.StatusOK, deps)
+}
+
+func (h *TaskDependencyHandler) RemoveDependency(c *gin.Context) {
+	task, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessEditor, "update")
+	if !ok {
+		return
+	}
+
+	blockedByID, err := uuid.FromString(c.Param("blocker_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid blocker ID"))
+		return
+	}
+
+	if err := h.dependencyService.RemoveDependency(requestDB(c, h.db), task.ID, blockedByID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("dependency not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to remove dependency"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
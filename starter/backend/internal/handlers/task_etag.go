@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+// WithRequireIfMatch makes PUT, PATCH and DELETE on a task refuse requests without an
+// If-Match header with 428 Precondition Required
+func (h *TaskHandler) WithRequireIfMatch(require bool) *TaskHandler {
+	h.requireIfMatch = require
+	return h
+}
+
+// taskETag is the entity tag of a task, derived from its version
+func taskETag(task *models.Task) string {
+	return `"` + strconv.Itoa(task.Version) + `"`
+}
+
+// matchesETag reports whether an If-Match or If-None-Match header lists the
+// tag. Weak tags match only when weak is set, as If-None-Match allows.
+func matchesETag(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified answers a GET with 304 Not Modified when its If-None-Match
+// header lists the task's ETag, and returns whether it did
+func notModified(c *gin.Context, task *models.Task) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" || !matchesETag(header, taskETag(task), true) {
+		return false
+	}
+	c.Header("ETag", taskETag(task))
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+// ifMatchVersion checks the If-Match header of a write to the task and returns
+// the version the write must still find, or 0 when the request sent none or
+// sent "*". It aborts the request and returns false when the header is
+// missing but required, or names another version.
+func (h *TaskHandler) ifMatchVersion(c *gin.Context, task *models.Task) (int, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if h.requireIfMatch {
+			apperr.Abort(c, apperr.New(apperr.KindPreconditionRequired, "if_match_required", "If-Match header required - send the task's ETag"))
+			return 0, false
+		}
+		return 0, true
+	}
+	if strings.TrimSpace(header) == "*" {
+		return 0, true
+	}
+	if !matchesETag(header, taskETag(task), false) {
+		writeStaleTask(c, task)
+		return 0, false
+	}
+	return task.Version, true
+}
+
+// writeStaleTask answers a write made against an outdated version with 412
+// Precondition Failed, carrying the task as it now is in the task member
+func writeStaleTask(c *gin.Context, task *models.Task) {
+	c.Header("ETag", taskETag(task))
+	apperr.Abort(c, apperr.New(apperr.KindPreconditionFailed, services.ErrVersionMismatch.Code, services.ErrVersionMismatch.Message).With("task", task))
+}
+
+// writeCurrentTask reloads the task after a write that lost a race with
+// another one and answers with writeStaleTask
+func (h *TaskHandler) writeCurrentTask(c *gin.Context, taskID uuid.UUID) {
+	task, err := h.taskService.GetTaskByID(requestDB(c, h.db), taskID)
+	if err != nil {
+		apperr.Abort(c, services.ErrVersionMismatch)
+		return
+	}
+	writeStaleTask(c, task)
+}
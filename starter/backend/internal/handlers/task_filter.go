@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseTaskFilter reads the task listing filters from the query string:
+// status, priority, due_before and due_after (RFC 3339), q for a text search
+// and labels, a comma-separated list of label names matched according to
+// label_mode (any, the default, or all).
+// It writes a 400 response and returns false when a value is malformed.
+func parseTaskFilter(c *gin.Context) (services.TaskFilter, bool) {
+	filter := services.TaskFilter{
+		Status:   c.Query("status"),
+		Priority: c.Query("priority"),
+		Search:   c.Query("q"),
+	}
+
+	if filter.Status != "" && !models.ValidTaskStatus(filter.Status) {
+		apperr.Abort(c, services.ErrInvalidStatus)
+		return filter, false
+	}
+	if filter.Priority != "" && !models.ValidTaskPriority(filter.Priority) {
+		apperr.Abort(c, services.ErrInvalidPriority)
+		return filter, false
+	}
+
+	for _, name := range strings.Split(c.Query("labels"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			filter.Labels = append(filter.Labels, name)
+		}
+	}
+	switch filter.LabelMode = c.DefaultQuery("label_mode", services.LabelMatchAny); filter.LabelMode {
+	case services.LabelMatchAny, services.LabelMatchAll:
+	default:
+		apperr.Abort(c, apperr.Invalid("label_mode", "invalid_label_mode", "label_mode must be any or all"))
+		return filter, false
+	}
+
+	for param, target := range map[string]**time.Time{
+		"due_before": &filter.DueBefore,
+		"due_after":  &filter.DueAfter,
+	} {
+		raw := c.Query(param)
+		if raw == "" {
+			continue
+		}
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			apperr.Abort(c, apperr.Invalid(param, "invalid_timestamp", "invalid "+param+" - expected RFC 3339 timestamp"))
+			return filter, false
+		}
+		*target = &value
+	}
+
+	return filter, true
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTaskHistory lists the task's revisions with their field-level changes,
+// newest first and paginated
+func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
+	task, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessViewer, "access")
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	revisions, total, err := h.taskService.GetTaskHistory(requestDB(c, h.db), task.ID, page)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get task history"))
+		return
+	}
+	c.JSON(http.StatusOK, pageResponse(revisions, page, total))
+}
+
+// RevertTask restores the task to a past revision and returns the task.
+// ?force=true completes it even while blockers or subtasks are still open.
+// If-Match is checked as for PUT.
+func (h *TaskHandler) RevertTask(c *gin.Context) {
+	task, _, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessEditor, "update")
+	if !ok {
+		return
+	}
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision < 1 {
+		apperr.Abort(c, apperr.BadRequest("invalid revision"))
+		return
+	}
+	ifVersion, ok := h.ifMatchVersion(c, task)
+	if !ok {
+		return
+	}
+
+	opts := services.UpdateOptions{Force: c.Query("force") == "true", IfVersion: ifVersion}
+	if err := h.taskService.RevertTask(requestDB(c, h.db), task.ID, revision, opts); err != nil {
+		if errors.Is(err, services.ErrVersionMismatch) {
+			h.writeCurrentTask(c, task.ID)
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to revert task"))
+		return
+	}
+
+	reverted, err := h.taskService.GetTaskByID(requestDB(c, h.db), task.ID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get task"))
+		return
+	}
+	c.Header("ETag", taskETag(reverted))
+	c.JSON(http.StatusOK, reverted)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/patch"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PatchTask changes single fields of the task. The body is a JSON Merge Patch
+// (application/merge-patch+json, or plain application/json) or a JSON Patch
+// (application/json-patch+json) against the task as GET returns it. Only the
+// fields the patch changes are validated and written, and null or a removed
+// member clears a field. Patches touching fields that cannot be updated are
+// rejected. Responds with the patched task.
+func (h *TaskHandler) PatchTask(c *gin.Context) {
+	existingTask, actor, ok := authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessEditor, "update")
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("failed to read request body"))
+		return
+	}
+	var apply func(doc, patch []byte) ([]byte, error)
+	switch c.ContentType() {
+	case patch.MergePatchType, "application/json":
+		apply = patch.Merge
+	case patch.JSONPatchType:
+		apply = patch.Apply
+	default:
+		apperr.Abort(c, apperr.New(apperr.KindUnsupportedMediaType, "unsupported_content_type", "content type must be "+patch.MergePatchType+" or "+patch.JSONPatchType))
+		return
+	}
+	task, fields, appErr := patchTask(existingTask, apply, body, actor)
+	if appErr != nil {
+		apperr.Abort(c, appErr)
+		return
+	}
+
+	ifVersion, ok := h.ifMatchVersion(c, existingTask)
+	if !ok {
+		return
+	}
+	opts, ok := parseUpdateOptions(c)
+	if !ok {
+		return
+	}
+	if appErr := h.checkMove(requestDB(c, h.db), existingTask, task, actor); appErr != nil {
+		apperr.Abort(c, appErr)
+		return
+	}
+
+	if len(fields) > 0 {
+		opts.IfVersion, opts.Fields = ifVersion, fields
+		if err := h.taskService.UpdateTask(requestDB(c, h.db), existingTask.ID, task, opts); err != nil {
+			h.writeUpdateError(c, existingTask.ID, err)
+			return
+		}
+	}
+
+	updated, err := h.taskService.GetTaskByID(requestDB(c, h.db), existingTask.ID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get task"))
+		return
+	}
+	c.Header("ETag", taskETag(updated))
+	c.JSON(http.StatusOK, updated)
+}
+
+// patchTask applies the patch to the task as GET returns it, and returns the
+// patched task with the fields the patch changed. Fields that cannot be
+// updated, and owner or team changes by anyone but an admin, are refused.
+func patchTask(existingTask *models.Task, apply func(doc, patch []byte) ([]byte, error), body []byte, actor services.Actor) (*models.Task, []string, *apperr.Error) {
+	original, err := json.Marshal(existingTask)
+	if err != nil {
+		return nil, nil, apperr.Internal(err, "failed to encode task")
+	}
+	patched, err := apply(original, body)
+	if err != nil {
+		if errors.Is(err, patch.ErrTestFailed) {
+			return nil, nil, apperr.New(apperr.KindConflict, "patch_test_failed", err.Error()).Wrap(err)
+		}
+		return nil, nil, apperr.New(apperr.KindBadRequest, "invalid_patch", err.Error()).Wrap(err)
+	}
+
+	fields, err := changedFields(original, patched)
+	if err != nil {
+		return nil, nil, apperr.BadRequest("the patched task must be a JSON object")
+	}
+	for _, field := range fields {
+		if !slices.Contains(services.UpdatableTaskFields, field) {
+			return nil, nil, apperr.New(apperr.KindBadRequest, services.ErrFieldNotUpdatable.Code, field+" cannot be changed")
+		}
+		if (field == "user_id" || field == "team_id") && !actor.IsAdmin() {
+			return nil, nil, apperr.Forbidden("access denied - only admins can reassign tasks")
+		}
+	}
+	var task models.Task
+	if err := json.Unmarshal(patched, &task); err != nil {
+		return nil, nil, apperr.Binding(err)
+	}
+	return &task, fields, nil
+}
+
+// changedFields lists the top-level members that differ between two JSON
+// objects, in name order
+func changedFields(before, after []byte) ([]string, error) {
+	var previous, current map[string]interface{}
+	if err := json.Unmarshal(before, &previous); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &current); err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for name, value := range current {
+		if !reflect.DeepEqual(value, previous[name]) {
+			fields = append(fields, name)
+		}
+	}
+	for name, value := range previous {
+		if _, ok := current[name]; !ok && value != nil {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
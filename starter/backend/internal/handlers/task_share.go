@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type TaskShareHandler struct {
+	db           *gorm.DB
+	taskService  services.TaskService
+	shareService services.TaskShareService
+}
+
+type ShareTaskRequest struct {
+	SubjectType string    `json:"subject_type" binding:"required"`
+	SubjectID   uuid.UUID `json:"subject_id" binding:"required"`
+	Permission  string    `json:"permission" binding:"required"`
+}
+
+func NewTaskShareHandler(db *gorm.DB, taskService services.TaskService, shareService services.TaskShareService) *TaskShareHandler {
+	return &TaskShareHandler{db: db, taskService: taskService, shareService: shareService}
+}
+
+func (h *TaskShareHandler) ShareTask(c *gin.Context) {
+	task, actor, ok := h.authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	var req ShareTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	share := models.TaskShare{
+		TaskID:      task.ID,
+		SubjectType: req.SubjectType,
+		SubjectID:   req.SubjectID,
+		Permission:  req.Permission,
+		GrantedBy:   actor.UserID,
+	}
+	if err := h.shareService.ShareTask(requestDB(c, h.db), &share); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to share task"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, share)
+}
+
+func (h *TaskShareHandler) GetShares(c *gin.Context) {
+	task, _, ok := h.authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	shares, err := h.shareService.GetShares(requestDB(c, h.db), task.ID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get task shares"))
+		return
+	}
+	c.JSON(http.StatusOK, shares)
+}
+
+func (h *TaskShareHandler) RevokeShare(c *gin.Context) {
+	task, _, ok := h.authorizeOwner(c)
+	if !ok {
+		return
+	}
+
+	shareID, err := uuid.FromString(c.Param("share_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid share ID"))
+		return
+	}
+
+	if err := h.shareService.RevokeShare(requestDB(c, h.db), task.ID, shareID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("share not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to revoke share"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// authorizeOwner makes sure the current user holds owner access on the task in
+// the :id parameter. Only owners may see or change who a task is shared with.
+func (h *TaskShareHandler) authorizeOwner(c *gin.Context) (*models.Task, services.Actor, bool) {
+	return authorizeTask(c, requestDB(c, h.db), h.taskService, models.TaskAccessOwner, "share")
+}
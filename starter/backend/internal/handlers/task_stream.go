@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/events"
+	"task-manager/backend/internal/repositories"
+	"task-manager/backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// streamHeartbeat keeps idle streams from being closed by proxies
+const streamHeartbeat = 15 * time.Second
+
+type TaskStreamHandler struct {
+	db          *gorm.DB
+	taskService services.TaskService
+	broker      events.Broker
+}
+
+func NewTaskStreamHandler(db *gorm.DB, taskService services.TaskService, broker events.Broker) *TaskStreamHandler {
+	return &TaskStreamHandler{db: db, taskService: taskService, broker: broker}
+}
+
+// Stream sends the current user every change to a task they can see as
+// server-sent events until the client disconnects. Created and updated events
+// carry the task, deleted events only its ID. A client that falls behind is
+// disconnected and should reconnect.
+func (h *TaskStreamHandler) Stream(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		apperr.Abort(c, apperr.Internal(nil, "streaming is not supported"))
+		return
+	}
+
+	sub := h.broker.Subscribe()
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	flusher.Flush()
+
+	ctx := c.Request.Context()
+	tenantID, scoped := repositories.TenantFromContext(ctx)
+	db := requestDB(c, h.db)
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			flusher.Flush()
+		case event, open := <-sub.C:
+			if !open {
+				return
+			}
+			if scoped && event.TenantID != tenantID {
+				continue
+			}
+
+			task, visible, err := services.VisibleTaskEvent(db, h.taskService, event, actor)
+			if err != nil {
+				slog.ErrorContext(ctx, "checking task stream access failed", "task_id", event.TaskID, "error", err)
+				continue
+			}
+			if !visible {
+				continue
+			}
+
+			var data []byte
+			if event.Type == events.TaskDeleted {
+				data, err = json.Marshal(gin.H{"id": event.TaskID})
+			} else {
+				data, err = json.Marshal(task)
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "encoding task stream event failed", "task_id", event.TaskID, "error", err)
+				continue
+			}
+			fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/ical"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Limits of one import
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 10000
+)
+
+// taskCSVColumns are the columns of a CSV export. Imports read the title,
+// description, status, priority, due_date and user_id columns and ignore the
+// rest, so an export can be imported again.
+var taskCSVColumns = []string{
+	"id", "title", "description", "status", "priority", "due_date", "labels",
+	"user_id", "team_id", "project_id", "parent_id", "started_at", "completed_at", "created_at", "updated_at",
+}
+
+type TaskTransferHandler struct {
+	db              *gorm.DB
+	transferService services.TaskTransferService
+}
+
+func NewTaskTransferHandler(db *gorm.DB, transferService services.TaskTransferService) *TaskTransferHandler {
+	return &TaskTransferHandler{db: db, transferService: transferService}
+}
+
+// ExportTasks streams tasks as CSV, NDJSON or iCalendar, chosen by ?format=
+// (csv, the default, ndjson or ics). Users export their own tasks; admins can
+// pass ?user_id= for another user's or ?all=true for the whole tenant's. The
+// listing filters apply. Once streaming has started errors can only end the
+// response early.
+func (h *TaskTransferHandler) ExportTasks(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	filter, ok := parseTaskFilter(c)
+	if !ok {
+		return
+	}
+
+	ownerID := actor.UserID
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.FromString(raw)
+		if err != nil {
+			apperr.Abort(c, apperr.BadRequest("invalid user ID"))
+			return
+		}
+		ownerID = id
+	}
+	all := c.Query("all") == "true"
+	if (all || ownerID != actor.UserID) && !actor.IsAdmin() {
+		apperr.Abort(c, apperr.Forbidden("access denied - only admins can export other users' tasks"))
+		return
+	}
+	scope := func(query *gorm.DB) *gorm.DB {
+		query = filter.Scope(query)
+		if !all {
+			query = query.Where("user_id = ?", ownerID)
+		}
+		return query
+	}
+	export := func(write func(*models.Task) error) error {
+		return h.transferService.ExportTasks(requestDB(c, h.db), scope, write)
+	}
+
+	var err error
+	switch format := c.DefaultQuery("format", "csv"); format {
+	case "csv":
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="tasks.csv"`)
+		c.Status(http.StatusOK)
+		err = writeTaskCSV(c.Writer, export)
+	case "ndjson":
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="tasks.ndjson"`)
+		c.Status(http.StatusOK)
+		encoder := json.NewEncoder(c.Writer)
+		err = export(func(task *models.Task) error {
+			return encoder.Encode(task)
+		})
+	case "ics":
+		c.Header("Content-Type", "text/calendar; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="tasks.ics"`)
+		c.Status(http.StatusOK)
+		err = writeTaskCalendar(c.Writer, "Tasks", export)
+	default:
+		apperr.Abort(c, apperr.BadRequest("format must be csv, ndjson or ics"))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "task export failed", "error", err)
+	}
+}
+
+// ImportTasks creates tasks from CSV with a header row (text/csv), a JSON
+// array (application/json) or NDJSON (application/x-ndjson). The tasks belong
+// to the importing user; admins can give a user_id. ?dry_run=true only
+// validates. The import is all or nothing: when any row is invalid nothing is
+// created and the report lists the problems with 422.
+func (h *TaskTransferHandler) ImportTasks(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	var rows []services.ImportRow
+	var err error
+	switch c.ContentType() {
+	case "text/csv":
+		rows, err = readCSVImport(body)
+	case "application/json":
+		rows, err = readJSONImport(body)
+	case "application/x-ndjson":
+		rows, err = readNDJSONImport(body)
+	default:
+		apperr.Abort(c, apperr.New(apperr.KindUnsupportedMediaType, "unsupported_content_type", "content type must be text/csv, application/json or application/x-ndjson"))
+		return
+	}
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apperr.Abort(c, apperr.New(apperr.KindTooLarge, "import_too_large", fmt.Sprintf("imports are limited to %d bytes", maxImportBytes)))
+			return
+		}
+		apperr.Abort(c, apperr.New(apperr.KindBadRequest, "invalid_import", err.Error()).Wrap(err))
+		return
+	}
+	if len(rows) == 0 {
+		apperr.Abort(c, apperr.BadRequest("nothing to import"))
+		return
+	}
+
+	for i := range rows {
+		row := &rows[i]
+		switch {
+		case row.Task.UserID == uuid.Nil:
+			row.Task.UserID = actor.UserID
+		case row.Task.UserID != actor.UserID && !actor.IsAdmin():
+			row.Errors = append(row.Errors, services.ImportError{Line: row.Line, Field: "user_id", Error: "only admins can import tasks for other users"})
+		}
+	}
+
+	report, err := h.transferService.ImportTasks(requestDB(c, h.db), rows, c.Query("dry_run") == "true")
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to import tasks"))
+		return
+	}
+	switch {
+	case len(report.Errors) > 0:
+		c.JSON(http.StatusUnprocessableEntity, report)
+	case report.DryRun:
+		c.JSON(http.StatusOK, report)
+	default:
+		c.JSON(http.StatusCreated, report)
+	}
+}
+
+// writeTaskCSV writes a header row and a record per exported task
+func writeTaskCSV(w io.Writer, export func(func(*models.Task) error) error) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(taskCSVColumns); err != nil {
+		return err
+	}
+	err := export(func(task *models.Task) error {
+		labels := make([]string, len(task.Labels))
+		for i, label := range task.Labels {
+			labels[i] = label.Name
+		}
+		return writer.Write([]string{
+			task.ID.String(),
+			csvText(task.Title),
+			csvText(task.Description),
+			task.Status,
+			task.Priority,
+			csvTime(task.DueDate),
+			csvText(strings.Join(labels, ";")),
+			task.UserID.String(),
+			csvID(task.TeamID),
+			csvID(task.ProjectID),
+			csvID(task.ParentID),
+			csvTime(task.StartedAt),
+			csvTime(task.CompletedAt),
+			csvTime(&task.CreatedAt),
+			csvTime(&task.UpdatedAt),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText keeps spreadsheets from running text that starts like a formula,
+// by prefixing it with a quote that readCSVImport removes again
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func csvID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// readCSVImport reads tasks from CSV whose first record names the columns
+func readCSVImport(r io.Reader) ([]services.ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("the CSV header must have a title column")
+	}
+
+	var rows []services.ImportRow
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("imports are limited to %d rows", maxImportRows)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := services.ImportRow{Line: line}
+		row.Task.Title = uncsvText(field("title"))
+		row.Task.Description = uncsvText(field("description"))
+		row.Task.Status = strings.ToLower(field("status"))
+		row.Task.Priority = strings.ToLower(field("priority"))
+		if raw := field("due_date"); raw != "" {
+			due, err := parseImportDate(raw)
+			if err != nil {
+				row.Errors = append(row.Errors, services.ImportError{Line: line, Field: "due_date", Error: err.Error()})
+			}
+			row.Task.DueDate = due
+		}
+		if raw := field("user_id"); raw != "" {
+			id, err := uuid.FromString(raw)
+			if err != nil {
+				row.Errors = append(row.Errors, services.ImportError{Line: line, Field: "user_id", Error: "invalid user ID"})
+			}
+			row.Task.UserID = id
+		}
+		rows = append(rows, row)
+	}
+}
+
+func uncsvText(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// parseImportDate reads an RFC 3339 timestamp or a plain date, taken as
+// midnight UTC
+func parseImportDate(raw string) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return &t, nil
+	}
+	return nil, errors.New("expected an RFC 3339 timestamp or a YYYY-MM-DD date")
+}
+
+// readJSONImport reads tasks from a JSON array
+func readJSONImport(r io.Reader) ([]services.ImportRow, error) {
+	var elements []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&elements); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of tasks: %w", err)
+	}
+	if len(elements) > maxImportRows {
+		return nil, fmt.Errorf("imports are limited to %d rows", maxImportRows)
+	}
+	rows := make([]services.ImportRow, len(elements))
+	for i, element := range elements {
+		rows[i] = jsonImportRow(i+1, element)
+	}
+	return rows, nil
+}
+
+// readNDJSONImport reads tasks from NDJSON, one per line
+func readNDJSONImport(r io.Reader) ([]services.ImportRow, error) {
+	var rows []services.ImportRow
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxImportBytes)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("imports are limited to %d rows", maxImportRows)
+		}
+		rows = append(rows, jsonImportRow(line, scanner.Bytes()))
+	}
+	return rows, scanner.Err()
+}
+
+// jsonImportRow reads the importable fields of one JSON task
+func jsonImportRow(line int, raw []byte) services.ImportRow {
+	row := services.ImportRow{Line: line}
+	var task models.Task
+	if err := json.Unmarshal(raw, &task); err != nil {
+		row.Errors = append(row.Errors, services.ImportError{Line: line, Error: "invalid task: " + err.Error()})
+		return row
+	}
+	row.Task = models.Task{
+		Title:       strings.TrimSpace(task.Title),
+		Description: task.Description,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		DueDate:     task.DueDate,
+		UserID:      task.UserID,
+	}
+	return row
+}
+
+// writeTaskCalendar writes the exported tasks as an iCalendar object: each
+// task as a VTODO, and each task with a due date also as a VEVENT at that
+// time, for calendars that do not show to-dos
+func writeTaskCalendar(w io.Writer, name string, export func(func(*models.Task) error) error) error {
+	calendar := ical.NewWriter(w, "-//task-manager//tasks//EN", name)
+	err := export(func(task *models.Task) error {
+		uid := task.ID.String() + "@task-manager"
+		common := []ical.Property{
+			ical.DateTime("DTSTAMP", task.UpdatedAt),
+			ical.DateTime("CREATED", task.CreatedAt),
+			ical.DateTime("LAST-MODIFIED", task.UpdatedAt),
+			ical.Text("SUMMARY", task.Title),
+		}
+		if task.Description != "" {
+			common = append(common, ical.Text("DESCRIPTION", task.Description))
+		}
+		if len(task.Labels) > 0 {
+			categories := make([]string, len(task.Labels))
+			for i, label := range task.Labels {
+				categories[i] = ical.EscapeText(label.Name)
+			}
+			common = append(common, ical.Property{Name: "CATEGORIES", Value: strings.Join(categories, ",")})
+		}
+
+		todo := append([]ical.Property{ical.Text("UID", uid)}, common...)
+		todo = append(todo,
+			ical.Property{Name: "STATUS", Value: todoStatus(task.Status)},
+			ical.Property{Name: "PRIORITY", Value: todoPriority(task.Priority)},
+		)
+		if task.DueDate != nil {
+			todo = append(todo, ical.DateTime("DUE", *task.DueDate))
+		}
+		if task.CompletedAt != nil {
+			todo = append(todo, ical.DateTime("COMPLETED", *task.CompletedAt))
+		}
+		if err := calendar.Write(ical.Component{Name: "VTODO", Properties: todo}); err != nil {
+			return err
+		}
+
+		if task.DueDate == nil {
+			return nil
+		}
+		event := append([]ical.Property{ical.Text("UID", "due-"+uid)}, common...)
+		event = append(event, ical.DateTime("DTSTART", *task.DueDate))
+		if task.Status == models.TaskStatusCancelled {
+			event = append(event, ical.Property{Name: "STATUS", Value: "CANCELLED"})
+		}
+		return calendar.Write(ical.Component{Name: "VEVENT", Properties: event})
+	})
+	if err != nil {
+		return err
+	}
+	return calendar.Close()
+}
+
+func todoStatus(status string) string {
+	switch status {
+	case models.TaskStatusInProgress, models.TaskStatusReview:
+		return "IN-PROCESS"
+	case models.TaskStatusCompleted:
+		return "COMPLETED"
+	case models.TaskStatusCancelled:
+		return "CANCELLED"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// todoPriority maps priorities onto iCalendar's 1 (highest) to 9 (lowest)
+func todoPriority(priority string) string {
+	switch priority {
+	case models.TaskPriorityHigh:
+		return "1"
+	case models.TaskPriorityLow:
+		return "9"
+	default:
+		return "5"
+	}
+}
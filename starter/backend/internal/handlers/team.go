@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type TeamHandler struct {
+	db          *gorm.DB
+	teamService services.TeamService
+	taskService services.TaskService
+}
+
+type TeamRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type TeamMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role" binding:"required"`
+}
+
+func NewTeamHandler(db *gorm.DB, teamService services.TeamService, taskService services.TaskService) *TeamHandler {
+	return &TeamHandler{db: db, teamService: teamService, taskService: taskService}
+}
+
+func (h *TeamHandler) CreateTeam(c *gin.Context) {
+	var req TeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	team := models.Team{Name: req.Name, Description: req.Description}
+	if err := h.teamService.CreateTeam(requestDB(c, h.db), &team, actor.UserID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to create team"))
+		return
+	}
+	c.JSON(http.StatusCreated, team)
+}
+
+func (h *TeamHandler) GetTeams(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	teams, err := h.teamService.GetTeams(requestDB(c, h.db), actor)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get teams"))
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
+
+func (h *TeamHandler) GetTeam(c *gin.Context) {
+	teamID := uuid.FromStringOrNil(c.Param("team_id"))
+
+	team, err := h.teamService.GetTeamByID(requestDB(c, h.db), teamID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("team not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get team"))
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+func (h *TeamHandler) UpdateTeam(c *gin.Context) {
+	teamID := uuid.FromStringOrNil(c.Param("team_id"))
+
+	var req TeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	team := models.Team{Name: req.Name, Description: req.Description}
+	if err := h.teamService.UpdateTeam(requestDB(c, h.db), teamID, &team); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("team not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to update team"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "team updated successfully"})
+}
+
+func (h *TeamHandler) DeleteTeam(c *gin.Context) {
+	teamID := uuid.FromStringOrNil(c.Param("team_id"))
+
+	if err := h.teamService.DeleteTeam(requestDB(c, h.db), teamID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("team not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to delete team"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TeamHandler) GetMembers(c *gin.Context) {
+	teamID := uuid.FromStringOrNil(c.Param("team_id"))
+
+	members, err := h.teamService.GetMembers(requestDB(c, h.db), teamID)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get team members"))
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+func (h *TeamHandler) SetMember(c *gin.Context) {
+	teamID := uuid.FromStringOrNil(c.Param("team_id"))
+
+	var req TeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	member, err := h.teamService.SetMember(requestDB(c, h.db), teamID, req.UserID, req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			apperr.Abort(c, apperr.NotFound("user not found"))
+		default:
+			apperr.Abort(c, apperr.OrInternal(err, "failed to update team member"))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *TeamHandler) RemoveMember(c *gin.Context) {
+	teamID := uuid.FromStringOrNil(c.Param("team_id"))
+	userID, err := uuid.FromString(c.Param("user_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid user ID"))
+		return
+	}
+
+	if err := h.teamService.RemoveMember(requestDB(c, h.db), teamID, userID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to remove team member"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *TeamHandler) GetTeamTasks(c *gin.Context) {
+	teamID := uuid.FromStringOrNil(c.Param("team_id"))
+
+	filter, ok := parseTaskFilter(c)
+	if !ok {
+		return
+	}
+
+	tasks, err := h.teamService.GetTeamTasks(requestDB(c, h.db), teamID, filter)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get team tasks"))
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// CreateTeamTask creates a task owned by the team. The creator is recorded in user_id,
+// and filing the task into a project needs project editor access.
+func (h *TeamHandler) CreateTeamTask(c *gin.Context) {
+	teamID := uuid.FromStringOrNil(c.Param("team_id"))
+
+	var task models.Task
+	if err := c.ShouldBindJSON(&task); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	newID, err := uuid.NewV4()
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to generate task ID"))
+		return
+	}
+	task.ID = newID
+	task.UserID = actor.UserID
+	task.TeamID = &teamID
+	// Subtasks are created through POST /tasks, which checks access to the parent
+	task.ParentID = nil
+
+	db := requestDB(c, h.db)
+	if task.ProjectID != nil {
+		if appErr := checkProjectEditor(db, *task.ProjectID, actor); appErr != nil {
+			apperr.Abort(c, appErr)
+			return
+		}
+	}
+
+	if err := h.taskService.CreateTask(db, &task); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to create task"))
+		return
+	}
+	c.JSON(http.StatusCreated, task)
+}
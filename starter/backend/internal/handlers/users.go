@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"task-manager/backend/internal/apperr"
 	"task-manager/backend/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -22,25 +23,25 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
 	// Get user ID from the JWT token
 	userID, exists := c.Get("user_id")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		apperr.Abort(c, apperr.Unauthorized("user not authenticated"))
 		return
 	}
 
 	// Convert userID to UUID
 	userUUID, ok := userID.(uuid.UUID)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid user id in token"})
+		apperr.Abort(c, apperr.Unauthorized("invalid user id in token"))
 		return
 	}
 
 	// Get user profile
-	user, err := h.userService.GetUserProfile(h.db, userUUID)
+	user, err := h.userService.GetUserProfile(requestDB(c, h.db), userUUID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			apperr.Abort(c, apperr.NotFound("user not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user profile"})
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get user profile"))
 		return
 	}
 
@@ -52,18 +53,18 @@ func (h *UserHandler) GetUserProfileByUserId(c *gin.Context) {
 	userID := c.Param("user_id")
 	userUUID, err := uuid.FromString(userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID format"})
+		apperr.Abort(c, apperr.BadRequest("invalid user ID format"))
 		return
 	}
 
 	// Get user profile
-	user, err := h.userService.GetUserProfile(h.db, userUUID)
+	user, err := h.userService.GetUserProfile(requestDB(c, h.db), userUUID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			apperr.Abort(c, apperr.NotFound("user not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user profile"})
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get user profile"))
 		return
 	}
 
@@ -71,9 +72,9 @@ func (h *UserHandler) GetUserProfileByUserId(c *gin.Context) {
 }
 
 func (h *UserHandler) GetUsers(c *gin.Context) {
-	users, err := h.userService.GetUsers(h.db)
+	users, err := h.userService.GetUsers(requestDB(c, h.db))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get users"})
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get users"))
 		return
 	}
 
@@ -85,18 +86,18 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	userID := c.Param("user_id")
 	userUUID, err := uuid.FromString(userID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID format"})
+		apperr.Abort(c, apperr.BadRequest("invalid user ID format"))
 		return
 	}
 
 	// Delete user
-	err = h.userService.DeleteUser(h.db, userUUID)
+	err = h.userService.DeleteUser(requestDB(c, h.db), userUUID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			apperr.Abort(c, apperr.NotFound("user not found"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete user"})
+		apperr.Abort(c, apperr.OrInternal(err, "failed to delete user"))
 		return
 	}
 
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	db             *gorm.DB
+	webhookService services.WebhookService
+}
+
+// WebhookRequest creates a webhook, or changes the fields it carries on an
+// existing one. The scope is fixed once the webhook exists.
+type WebhookRequest struct {
+	URL    *string  `json:"url"`
+	Events []string `json:"events"`
+	Scope  string   `json:"scope"`
+	Active *bool    `json:"active"`
+}
+
+// createdWebhook is the only response that includes the signing secret
+type createdWebhook struct {
+	*models.Webhook
+	Secret string `json:"secret"`
+}
+
+func NewWebhookHandler(db *gorm.DB, webhookService services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{db: db, webhookService: webhookService}
+}
+
+// CreateWebhook subscribes a URL to events. Only admins can create tenant
+// webhooks. The response carries the secret payloads are signed with; it is
+// not shown again.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+	if req.Scope == models.WebhookScopeTenant && !actor.IsAdmin() {
+		apperr.Abort(c, apperr.Forbidden("access denied - only admins can create tenant webhooks"))
+		return
+	}
+
+	webhook := models.Webhook{UserID: actor.UserID, Events: req.Events, Scope: req.Scope}
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if err := h.webhookService.CreateWebhook(requestDB(c, h.db), &webhook); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to create webhook"))
+		return
+	}
+	c.JSON(http.StatusCreated, createdWebhook{Webhook: &webhook, Secret: webhook.Secret})
+}
+
+// GetWebhooks lists the current user's webhooks; admins see every webhook of the tenant
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	actor, ok := currentActor(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.webhookService.GetWebhooks(requestDB(c, h.db), actor)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get webhooks"))
+		return
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhook, ok := h.authorizeWebhook(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := h.authorizeWebhook(c)
+	if !ok {
+		return
+	}
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apperr.Abort(c, apperr.Binding(err))
+		return
+	}
+
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = req.Events
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	if err := h.webhookService.UpdateWebhook(requestDB(c, h.db), webhook); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to update webhook"))
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := h.authorizeWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(requestDB(c, h.db), webhook.ID); err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to delete webhook"))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetDeliveries lists the webhook's deliveries, newest first and paginated,
+// with the response code and body of each one's latest attempt
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	webhook, ok := h.authorizeWebhook(c)
+	if !ok {
+		return
+	}
+	page, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	deliveries, total, err := h.webhookService.GetDeliveries(requestDB(c, h.db), webhook.ID, page)
+	if err != nil {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get webhook deliveries"))
+		return
+	}
+	c.JSON(http.StatusOK, pageResponse(deliveries, page, total))
+}
+
+// Redeliver queues an earlier delivery's payload to be sent again
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	webhook, ok := h.authorizeWebhook(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := uuid.FromString(c.Param("delivery_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid delivery ID"))
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(requestDB(c, h.db), webhook.ID, deliveryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apperr.Abort(c, apperr.NotFound("delivery not found"))
+			return
+		}
+		apperr.Abort(c, apperr.OrInternal(err, "failed to redeliver"))
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// authorizeWebhook loads the webhook named by :webhook_id when the current user
+// owns it or is an admin. Other users' webhooks are reported as not found.
+func (h *WebhookHandler) authorizeWebhook(c *gin.Context) (*models.Webhook, bool) {
+	webhookID, err := uuid.FromString(c.Param("webhook_id"))
+	if err != nil {
+		apperr.Abort(c, apperr.BadRequest("invalid webhook ID"))
+		return nil, false
+	}
+	actor, ok := currentActor(c)
+	if !ok {
+		return nil, false
+	}
+
+	webhook, err := h.webhookService.GetWebhook(requestDB(c, h.db), webhookID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		apperr.Abort(c, apperr.OrInternal(err, "failed to get webhook"))
+		return nil, false
+	}
+	if err != nil || (webhook.UserID != actor.UserID && !actor.IsAdmin()) {
+		apperr.Abort(c, apperr.NotFound("webhook not found"))
+		return nil, false
+	}
+	return webhook, true
+}
@@ -0,0 +1,103 @@
+// Package ical writes iCalendar (RFC 5545) data.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxLineOctets is the longest content line RFC 5545 allows before folding
+const maxLineOctets = 75
+
+// Property is one content line of a component
+type Property struct {
+	Name  string
+	Value string
+}
+
+// Text is a TEXT property, escaped as RFC 5545 requires
+func Text(name, value string) Property {
+	return Property{Name: name, Value: EscapeText(value)}
+}
+
+// DateTime is a DATE-TIME property in UTC
+func DateTime(name string, t time.Time) Property {
+	return Property{Name: name, Value: t.UTC().Format("20060102T150405Z")}
+}
+
+// Component is a calendar component such as VTODO or VEVENT
+type Component struct {
+	Name       string
+	Properties []Property
+}
+
+// Writer writes one VCALENDAR object. Call Close to end it.
+type Writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// NewWriter starts a VCALENDAR object on w with the given product identifier
+// and calendar name
+func NewWriter(w io.Writer, prodID, name string) *Writer {
+	writer := &Writer{w: bufio.NewWriter(w)}
+	writer.line("BEGIN", "VCALENDAR")
+	writer.line("VERSION", "2.0")
+	writer.line("PRODID", prodID)
+	writer.line("CALSCALE", "GREGORIAN")
+	if name != "" {
+		writer.line("X-WR-CALNAME", EscapeText(name))
+	}
+	return writer
+}
+
+// Write adds a component
+func (w *Writer) Write(component Component) error {
+	w.line("BEGIN", component.Name)
+	for _, property := range component.Properties {
+		w.line(property.Name, property.Value)
+	}
+	w.line("END", component.Name)
+	return w.err
+}
+
+// Close ends the VCALENDAR object and flushes it
+func (w *Writer) Close() error {
+	w.line("END", "VCALENDAR")
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// line writes a content line, folded so no line exceeds 75 octets and never
+// splitting a UTF-8 sequence
+func (w *Writer) line(name, value string) {
+	if w.err != nil {
+		return
+	}
+	content := name + ":" + value
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		if _, w.err = w.w.WriteString(content[:cut] + "\r\n "); w.err != nil {
+			return
+		}
+		content = content[cut:]
+		// Continuation lines start with the space that folding adds
+		limit = maxLineOctets - 1
+	}
+	_, w.err = w.w.WriteString(content + "\r\n")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// EscapeText escapes a TEXT value, such as one item of a list
+func EscapeText(value string) string {
+	return textEscaper.Replace(value)
+}
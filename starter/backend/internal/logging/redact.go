@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the values of sensitive fields in logs
+const Redacted = "[REDACTED]"
+
+// Field names are lowercased and stripped of dashes and underscores before
+// they are matched. Credentials match anywhere in the name, so
+// refresh_token or smtp_password are covered too; personal data only whole.
+var (
+	sensitiveParts = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "apikey", "signature"}
+	sensitiveNames = map[string]bool{"email": true, "phone": true, "dsn": true}
+)
+
+// Sensitive reports whether a field of that name must not be logged
+func Sensitive(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	if sensitiveNames[normalized] {
+		return true
+	}
+	for _, part := range sensitiveParts {
+		if strings.Contains(normalized, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactJSON returns the JSON document with the values of sensitive members
+// replaced at any depth. Anything that is not JSON is withheld entirely.
+func RedactJSON(data []byte) json.RawMessage {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return json.RawMessage(`"` + Redacted + `"`)
+	}
+	redacted, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return json.RawMessage(`"` + Redacted + `"`)
+	}
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, member := range v {
+			if Sensitive(key) {
+				v[key] = Redacted
+			} else {
+				v[key] = redactValue(member)
+			}
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = redactValue(element)
+		}
+	}
+	return value
+}
+
+// redactAttr hides sensitive attributes, and sensitive fields of structured
+// values such as payloads, before they are written
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if Sensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	if a.Value.Kind() != slog.KindAny {
+		return a
+	}
+
+	switch v := a.Value.Any().(type) {
+	case error:
+		return a
+	case json.RawMessage:
+		return slog.Any(a.Key, RedactJSON(v))
+	case []byte:
+		return a
+	}
+	// Structs, maps and slices are logged as JSON, so they are redacted as JSON
+	kind := reflect.Indirect(reflect.ValueOf(a.Value.Any())).Kind()
+	if kind != reflect.Struct && kind != reflect.Map && kind != reflect.Slice {
+		return a
+	}
+	data, err := json.Marshal(a.Value.Any())
+	if err != nil {
+		return a
+	}
+	return slog.Any(a.Key, RedactJSON(data))
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const queryStartKey = "metrics:query_start"
+
+// InstrumentGorm installs GORM callbacks that time every statement into the
+// query duration histogram, labelled by create, query, update, delete, row or
+// raw
+func InstrumentGorm(db *gorm.DB) error {
+	callbacks := db.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("*").Register, callbacks.Create().After("*").Register},
+		{"query", callbacks.Query().Before("*").Register, callbacks.Query().After("*").Register},
+		{"update", callbacks.Update().Before("*").Register, callbacks.Update().After("*").Register},
+		{"delete", callbacks.Delete().Before("*").Register, callbacks.Delete().After("*").Register},
+		{"row", callbacks.Row().Before("*").Register, callbacks.Row().After("*").Register},
+		{"raw", callbacks.Raw().Before("*").Register, callbacks.Raw().After("*").Register},
+	}
+	for _, p := range processors {
+		if err := p.before("metrics:before_"+p.operation, startQuery); err != nil {
+			return err
+		}
+		if err := p.after("metrics:after_"+p.operation, observeQuery(p.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func observeQuery(operation string) func(*gorm.DB) {
+	histogram := dbQueryDuration.WithLabelValues(operation)
+	return func(db *gorm.DB) {
+		if start, ok := db.InstanceGet(queryStartKey); ok {
+			histogram.Observe(time.Since(start.(time.Time)).Seconds())
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+var tasksDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "tasks"),
+	"Tasks that are not deleted, by status, across all tenants.",
+	[]string{"status"}, nil,
+)
+
+// TaskCollector reports the number of tasks in each status. It counts them
+// when scraped, so the database must cross tenants: pass a session whose
+// context bypasses the tenant scope.
+type TaskCollector struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewTaskCollector(db *gorm.DB, timeout time.Duration) *TaskCollector {
+	return &TaskCollector{db: db, timeout: timeout}
+}
+
+func (c *TaskCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tasksDesc
+}
+
+func (c *TaskCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(c.db.Statement.Context, c.timeout)
+	defer cancel()
+
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := c.db.WithContext(ctx).Model(&models.Task{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error; err != nil {
+		slog.ErrorContext(ctx, "counting tasks for metrics failed", "error", err)
+		ch <- prometheus.NewInvalidMetric(tasksDesc, err)
+		return
+	}
+
+	// Statuses without tasks are reported as zero rather than left out
+	counts := make(map[string]int64, len(models.TaskStatuses))
+	for _, status := range models.TaskStatuses {
+		counts[status] = 0
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(tasksDesc, prometheus.GaugeValue, float64(count), status)
+	}
+}
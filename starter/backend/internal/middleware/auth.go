@@ -1,8 +1,9 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
 	"strings"
+	"task-manager/backend/internal/apperr"
 	"task-manager/backend/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -11,35 +12,90 @@ import (
 
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header is required"})
-			c.Abort()
+		token, ok := bearerToken(c)
+		if !ok {
 			return
 		}
+		if authenticate(c, token) != nil {
+			c.Next()
+		}
+	}
+}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
-			c.Abort()
+// StreamAuthMiddleware authenticates like AuthMiddleware but also accepts the
+// token in the access_token query parameter, since browsers cannot set headers
+// on an EventSource. Access tokens cannot be revoked, so the request is
+// cancelled when its token expires: a stream lives no longer than the token
+// that opened it, and the client reconnects with a fresh one.
+func StreamAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("access_token")
+		if c.GetHeader("Authorization") != "" {
+			var ok bool
+			if token, ok = bearerToken(c); !ok {
+				return
+			}
+		} else if token == "" {
+			apperr.Abort(c, apperr.Unauthorized("authorization header or access_token is required"))
 			return
 		}
 
-		claims, err := utils.ValidateAccessToken(parts[1])
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			c.Abort()
+		claims := authenticate(c, token)
+		if claims == nil {
 			return
 		}
+		if claims.ExpiresAt != nil {
+			ctx, cancel := context.WithDeadline(c.Request.Context(), claims.ExpiresAt.Time)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+		}
+		c.Next()
+	}
+}
 
-		// Set user info in context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("roles", claims.Roles)
-		c.Set("permissions", claims.Permissions)
+// bearerToken reads the token from the Authorization header, aborting the
+// request when there is none
+func bearerToken(c *gin.Context) (string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		apperr.Abort(c, apperr.Unauthorized("authorization header is required"))
+		return "", false
+	}
 
-		c.Next()
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		apperr.Abort(c, apperr.Unauthorized("invalid authorization header format"))
+		return "", false
+	}
+	return parts[1], true
+}
+
+// authenticate validates the access token and stores its claims in the
+// context. It aborts the request and returns nil when the token is refused.
+func authenticate(c *gin.Context, token string) *utils.Claims {
+	claims, err := utils.ValidateAccessToken(token)
+	if err != nil {
+		apperr.Abort(c, err)
+		return nil
 	}
+
+	// The token's tenant is authoritative, so a token cannot be replayed against another tenant
+	if header := c.GetHeader(TenantHeader); header != "" && uuid.FromStringOrNil(header) != claims.TenantID {
+		apperr.Abort(c, apperr.Unauthorized("token does not belong to the requested tenant"))
+		return nil
+	}
+	if claims.TenantID != uuid.Nil {
+		setTenant(c, claims.TenantID)
+	}
+
+	// Set user info in context
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("roles", claims.Roles)
+	c.Set("permissions", claims.Permissions)
+	setActor(c, claims.UserID)
+
+	return claims
 }
 
 // RequireRole checks if the user has any of the required roles
@@ -47,8 +103,7 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRoles, exists := c.Get("roles")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user roles not found"})
-			c.Abort()
+			apperr.Abort(c, apperr.Unauthorized("user roles not found"))
 			return
 		}
 
@@ -62,8 +117,7 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions - role required"})
-		c.Abort()
+		deny(c, "role:"+strings.Join(roles, "|"), "insufficient permissions - role required")
 	}
 }
 
@@ -72,8 +126,7 @@ func RequirePermission(resource, action string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		permissions, exists := c.Get("permissions")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user permissions not found"})
-			c.Abort()
+			apperr.Abort(c, apperr.Unauthorized("user permissions not found"))
 			return
 		}
 
@@ -87,8 +140,7 @@ func RequirePermission(resource, action string) gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions - permission required"})
-		c.Abort()
+		deny(c, requiredPermission, "insufficient permissions - permission required")
 	}
 }
 
@@ -98,8 +150,7 @@ func RequireRoleAndPermission(role string, resource, action string) gin.HandlerF
 		// Check role first
 		userRoles, exists := c.Get("roles")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user roles not found"})
-			c.Abort()
+			apperr.Abort(c, apperr.Unauthorized("user roles not found"))
 			return
 		}
 
@@ -113,16 +164,14 @@ func RequireRoleAndPermission(role string, resource, action string) gin.HandlerF
 		}
 
 		if !hasRole {
-			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions - role required"})
-			c.Abort()
+			deny(c, "role:"+role, "insufficient permissions - role required")
 			return
 		}
 
 		// Check permission
 		permissions, exists := c.Get("permissions")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user permissions not found"})
-			c.Abort()
+			apperr.Abort(c, apperr.Unauthorized("user permissions not found"))
 			return
 		}
 
@@ -136,8 +185,7 @@ func RequireRoleAndPermission(role string, resource, action string) gin.HandlerF
 			}
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions - permission required"})
-		c.Abort()
+		deny(c, requiredPermission, "insufficient permissions - permission required")
 	}
 }
 
@@ -146,8 +194,7 @@ func RequireOwnershipOrAdmin(resourceIDParam string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, exists := c.Get("user_id")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
-			c.Abort()
+			apperr.Abort(c, apperr.Unauthorized("user not authenticated"))
 			return
 		}
 
@@ -166,16 +213,14 @@ func RequireOwnershipOrAdmin(resourceIDParam string) gin.HandlerFunc {
 		// Check ownership
 		resourceID := c.Param(resourceIDParam)
 		if resourceID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "resource ID not provided"})
-			c.Abort()
+			apperr.Abort(c, apperr.BadRequest("resource ID not provided"))
 			return
 		}
 
 		// Parse resource ID
 		resourceUUID, err := uuid.FromString(resourceID)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource ID format"})
-			c.Abort()
+			apperr.Abort(c, apperr.BadRequest("invalid resource ID format"))
 			return
 		}
 
@@ -186,8 +231,7 @@ func RequireOwnershipOrAdmin(resourceIDParam string) gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "access denied - resource ownership required"})
-		c.Abort()
+		deny(c, "ownership:"+resourceIDParam, "access denied - resource ownership required")
 	}
 }
 
@@ -220,8 +264,7 @@ func RequireUserManagementAccess() gin.HandlerFunc {
 		// Only admins can manage users
 		userRoles, exists := c.Get("roles")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user roles not found"})
-			c.Abort()
+			apperr.Abort(c, apperr.Unauthorized("user roles not found"))
 			return
 		}
 
@@ -233,8 +276,7 @@ func RequireUserManagementAccess() gin.HandlerFunc {
 			}
 		}
 
-		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions - admin role required for user management"})
-		c.Abort()
+		deny(c, "role:admin", "insufficient permissions - admin role required for user management")
 	}
 }
 
@@ -243,8 +285,7 @@ func RequireTaskManagementAccess() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRoles, exists := c.Get("roles")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "user roles not found"})
-			c.Abort()
+			apperr.Abort(c, apperr.Unauthorized("user roles not found"))
 			return
 		}
 
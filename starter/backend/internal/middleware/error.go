@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log/slog"
+	"task-manager/backend/internal/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler answers requests stopped with apperr.Abort with problem
+// details, as application/problem+json, carrying the error's code and the
+// request ID. Errors that are not API errors become a 500 whose cause is
+// logged instead of shown. Handlers that already wrote a response are left
+// alone.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		writeProblem(c)
+	}
+}
+
+// writeProblem answers the request with its last error, unless it has none or
+// a response was already written
+func writeProblem(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	err := c.Errors.Last().Err
+	appErr := apperr.From(err)
+	if appErr.Kind == apperr.KindInternal {
+		slog.ErrorContext(c.Request.Context(), "request failed", "route", c.FullPath(), "error", err)
+	}
+
+	c.Header("Content-Type", apperr.ContentType)
+	c.JSON(appErr.Status(), apperr.NewProblem(appErr, c.Request.URL.Path, c.GetString("request_id")))
+}
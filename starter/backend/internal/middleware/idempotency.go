@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency lets clients retry a POST safely by sending an Idempotency-Key
+// header. The first response under a key is stored for the signed-in user and
+// replayed with an Idempotent-Replayed header to retries with the same method,
+// path and body. Reusing a key for a different request is rejected with 422,
+// and a retry while the first request is still running with 409. Server
+// errors and panics are not stored, so a request that failed with one can be
+// retried under its key. Requests without the header are not affected.
+//
+// Anonymous clients, such as those registering, have no user, so their keys
+// are scoped to the client's IP address instead. The request hash is only
+// compared, never looked up by, so a different body under the same key is
+// rejected like any other reuse.
+func Idempotency(db *gorm.DB, idempotencyService services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			apperr.Abort(c, apperr.BadRequest("idempotency key is too long"))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apperr.Abort(c, apperr.BadRequest("failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		path := c.Request.URL.RequestURI()
+		hash := requestHash(c.Request.Method, path, body)
+		scope := uuid.NewV5(anonymousIdempotencyScope, c.ClientIP())
+		if id, exists := c.Get("user_id"); exists {
+			scope = id.(uuid.UUID)
+		}
+		requestDB := db.WithContext(c.Request.Context())
+
+		record, err := idempotencyService.Begin(requestDB, scope, key, hash, c.Request.Method, path)
+		if err != nil {
+			// ErrIdempotencyKeyReused and ErrIdempotencyKeyInProgress carry their own status
+			apperr.Abort(c, err)
+			return
+		}
+
+		if record.Completed() {
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(record.StatusCode, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		// The outcome is saved even when the client has gone away, and the key
+		// is released when the handler panics, so retries are not locked out
+		saveDB := db.WithContext(context.WithoutCancel(c.Request.Context()))
+		settled := false
+		defer func() {
+			if settled {
+				return
+			}
+			if err := idempotencyService.Release(saveDB, record); err != nil {
+				slog.ErrorContext(c.Request.Context(), "failed to release idempotency key", "error", err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		// Render an error now, so it is stored like any other response
+		writeProblem(c)
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		settled = true
+		if err := idempotencyService.Complete(saveDB, record, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			slog.ErrorContext(c.Request.Context(), "failed to save idempotency key", "error", err)
+		}
+	}
+}
+
+// anonymousIdempotencyScope is the namespace of the scopes derived from the
+// addresses of anonymous clients
+var anonymousIdempotencyScope = uuid.Must(uuid.FromString("6f1b7c3e-2d4a-5e8f-9a0b-1c2d3e4f5a6b"))
+
+// requestHash identifies a request by its method, path and body
+func requestHash(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body as it is written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
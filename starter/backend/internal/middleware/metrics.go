@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics counts and times every request by method, route template and
+// status. Run it before ErrorHandler so it sees the status of problem
+// responses.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		metrics.ObserveRequest(c.Request.Method, routeTemplate(c), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// deny refuses the request with a 403 and counts the denial against the
+// permission it lacked
+func deny(c *gin.Context, permission, message string) {
+	metrics.RecordDenial(permission)
+	apperr.Abort(c, apperr.Forbidden(message))
+}
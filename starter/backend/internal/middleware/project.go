@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// RequireProjectAccess checks that the user holds at least the given
+// permission (viewer, editor or owner) on the project in the :project_id
+// parameter. Global admins always pass. The resolved permission is stored in
+// the context under "project_access".
+func RequireProjectAccess(db *gorm.DB, projectService services.ProjectService, required string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			apperr.Abort(c, apperr.Unauthorized("user not authenticated"))
+			return
+		}
+
+		projectID, err := uuid.FromString(c.Param("project_id"))
+		if err != nil {
+			apperr.Abort(c, apperr.BadRequest("invalid project ID"))
+			return
+		}
+
+		actor := services.Actor{UserID: userID.(uuid.UUID)}
+		if userRoles, exists := c.Get("roles"); exists {
+			actor.Roles = userRoles.([]string)
+		}
+
+		level, err := projectService.GetProjectAccess(db.WithContext(c.Request.Context()), projectID, actor)
+		if err != nil {
+			apperr.Abort(c, apperr.Internal(err, "failed to resolve project access"))
+			return
+		}
+
+		if level == models.TaskAccessNone {
+			deny(c, "project:"+required, "access denied - project membership required")
+			return
+		}
+		if models.TaskAccessRank(level) < models.TaskAccessRank(required) {
+			deny(c, "project:"+required, "insufficient permissions - project "+required+" access required")
+			return
+		}
+
+		c.Set("project_access", level)
+		c.Next()
+	}
+}
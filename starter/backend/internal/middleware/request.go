@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log/slog"
+	"task-manager/backend/internal/logging"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs taken from clients
+const maxRequestIDLength = 128
+
+// RequestInfoMiddleware records the client IP, user agent and request ID in
+// the request context for the audit log, and the request ID for every log
+// line written with the context. A request ID sent by the client or a proxy is
+// kept; otherwise one is generated. It is echoed in the response.
+func RequestInfoMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.Must(uuid.NewV4()).String()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Set("request_id", requestID)
+
+		info := services.RequestInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent(), RequestID: requestID}
+		ctx := logging.WithAttrs(c.Request.Context(), slog.String("request_id", requestID))
+		c.Request = c.Request.WithContext(services.WithRequestInfo(ctx, info))
+		c.Next()
+	}
+}
+
+// setActor names the authenticated user as the actor of the request's changes
+func setActor(c *gin.Context, userID uuid.UUID) {
+	ctx := c.Request.Context()
+	info := services.RequestInfoFromContext(ctx)
+	info.ActorID = &userID
+	ctx = logging.WithAttrs(ctx, slog.String("user_id", userID.String()))
+	c.Request = c.Request.WithContext(services.WithRequestInfo(ctx, info))
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// RequireTeamRole checks that the user belongs to the team in the :team_id
+// parameter with one of the given team roles. With no roles any membership is
+// enough. Global admins always pass. The resolved team role is stored in the
+// context under "team_role".
+func RequireTeamRole(db *gorm.DB, teamService services.TeamService, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			apperr.Abort(c, apperr.Unauthorized("user not authenticated"))
+			return
+		}
+
+		teamID, err := uuid.FromString(c.Param("team_id"))
+		if err != nil {
+			apperr.Abort(c, apperr.BadRequest("invalid team ID"))
+			return
+		}
+
+		// Global admins can manage every team
+		if userRoles, exists := c.Get("roles"); exists {
+			for _, role := range userRoles.([]string) {
+				if role == "admin" {
+					c.Set("team_role", "admin")
+					c.Next()
+					return
+				}
+			}
+		}
+
+		teamRole, err := teamService.GetMemberRole(db.WithContext(c.Request.Context()), teamID, userID.(uuid.UUID))
+		if err != nil {
+			if errors.Is(err, services.ErrNotTeamMember) {
+				deny(c, "team:member", "access denied - team membership required")
+			} else {
+				apperr.Abort(c, apperr.Internal(err, "failed to resolve team membership"))
+			}
+			return
+		}
+
+		if len(roles) == 0 {
+			c.Set("team_role", teamRole)
+			c.Next()
+			return
+		}
+		for _, role := range roles {
+			if role == teamRole {
+				c.Set("team_role", teamRole)
+				c.Next()
+				return
+			}
+		}
+
+		deny(c, "team:"+strings.Join(roles, "|"), "insufficient permissions - team role required")
+	}
+}
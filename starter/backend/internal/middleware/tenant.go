@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/repositories"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+const TenantHeader = "X-Tenant-ID"
+
+// TenantMiddleware resolves the tenant of a request from the X-Tenant-ID
+// header, falling back to DEFAULT_TENANT_ID. Requests carrying a bearer token
+// and no header are left for AuthMiddleware, which uses the token's tenant.
+func TenantMiddleware(db *gorm.DB, tenantService services.TenantService) gin.HandlerFunc {
+	defaultTenant := utils.GetEnv("DEFAULT_TENANT_ID", models.DefaultTenantID.String())
+
+	return func(c *gin.Context) {
+		raw := c.GetHeader(TenantHeader)
+		if raw == "" {
+			if c.GetHeader("Authorization") != "" {
+				c.Next()
+				return
+			}
+			raw = defaultTenant
+		}
+
+		tenantID, err := uuid.FromString(raw)
+		if err != nil {
+			apperr.Abort(c, apperr.BadRequest("invalid tenant ID"))
+			return
+		}
+
+		if _, err := tenantService.GetTenantByID(db.WithContext(c.Request.Context()), tenantID); err != nil {
+			apperr.Abort(c, apperr.BadRequest("unknown tenant"))
+			return
+		}
+
+		setTenant(c, tenantID)
+		c.Next()
+	}
+}
+
+// setTenant scopes the rest of the request, including its database queries, to tenantID
+func setTenant(c *gin.Context, tenantID uuid.UUID) {
+	c.Set("tenant_id", tenantID)
+	c.Request = c.Request.WithContext(repositories.WithTenant(c.Request.Context(), tenantID))
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Attachment describes a file uploaded to a task. The bytes live in the
+// configured storage under StorageKey.
+type Attachment struct {
+	ID          uuid.UUID `json:"id" gorm:"primaryKey"`
+	TenantID    uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	TaskID      uuid.UUID `json:"task_id" gorm:"index"`
+	UploadedBy  uuid.UUID `json:"uploaded_by"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
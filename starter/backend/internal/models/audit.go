@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Audited actions. Changes to data share their names with the domain events.
+const (
+	AuditLogin       = "auth.login"
+	AuditLoginFailed = "auth.login_failed"
+	AuditRefresh     = "auth.refresh"
+	AuditRoleChanged = EventRoleChanged
+	AuditUserDeleted = EventUserDeleted
+	AuditTaskCreated = EventTaskCreated
+	AuditTaskUpdated = EventTaskUpdated
+	AuditTaskDeleted = EventTaskDeleted
+)
+
+// FieldChange is one field's value before and after a change. Before is
+// null for created records, After for deleted ones.
+type FieldChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditEntry is one record of the append-only audit log. The entries of a
+// tenant form a hash chain: Seq counts them from 1 and each Hash covers the
+// entry together with the Hash of the one before, so altering, removing or
+// reordering an entry breaks the chain from that entry on.
+type AuditEntry struct {
+	ID         uuid.UUID              `json:"id" gorm:"primaryKey"`
+	TenantID   uuid.UUID              `json:"tenant_id" gorm:"<-:create;uniqueIndex:idx_audit_entries_tenant_seq"`
+	Seq        int64                  `json:"seq" gorm:"uniqueIndex:idx_audit_entries_tenant_seq"`
+	Action     string                 `json:"action" gorm:"index"`
+	ActorID    *uuid.UUID             `json:"actor_id" gorm:"index"`
+	TargetType string                 `json:"target_type"`
+	TargetID   *uuid.UUID             `json:"target_id" gorm:"index"`
+	Changes    map[string]FieldChange `json:"changes,omitempty" gorm:"serializer:json"`
+	Detail     string                 `json:"detail,omitempty"`
+	IP         string                 `json:"ip"`
+	UserAgent  string                 `json:"user_agent"`
+	RequestID  string                 `json:"request_id"`
+	PrevHash   string                 `json:"prev_hash"`
+	Hash       string                 `json:"hash"`
+	CreatedAt  time.Time              `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// CalendarFeed is a user's secret iCalendar subscription URL. Only a hash of
+// its token is stored; the URL is shown once, when the feed is created, and
+// deleting the feed revokes it. A user has at most one feed.
+type CalendarFeed struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	TenantID  uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	UserID    uuid.UUID `json:"user_id" gorm:"uniqueIndex"`
+	TokenHash string    `json:"-" gorm:"uniqueIndex"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Comment is a Markdown message in a task's discussion thread. The body is
+// stored as written; rendering is left to clients.
+type Comment struct {
+	ID        uuid.UUID        `json:"id" gorm:"primaryKey"`
+	TenantID  uuid.UUID        `json:"tenant_id" gorm:"<-:create;index"`
+	TaskID    uuid.UUID        `json:"task_id" gorm:"index"`
+	AuthorID  uuid.UUID        `json:"author_id"`
+	Body      string           `json:"body"`
+	Mentions  []CommentMention `json:"mentions" gorm:"foreignKey:CommentID"`
+	EditedAt  *time.Time       `json:"edited_at"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	DeletedAt gorm.DeletedAt   `json:"deleted_at" gorm:"index"`
+}
+
+// CommentRevision keeps the body a comment had before one of its edits
+type CommentRevision struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	TenantID  uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	CommentID uuid.UUID `json:"comment_id" gorm:"index"`
+	Body      string    `json:"body"`
+	EditedBy  uuid.UUID `json:"edited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentMention records a user @mentioned in a comment
+type CommentMention struct {
+	CommentID uuid.UUID `json:"comment_id" gorm:"primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"primaryKey;index"`
+	TenantID  uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// IdempotencyKey is the outcome of a request sent with an Idempotency-Key
+// header, kept so a retry of the request gets the same response instead of
+// running it again. A record with no status code is a request still running.
+// UserID scopes the key: it is the signed-in user, or for requests made before
+// signing in, such as registration, an ID derived from the client's address.
+type IdempotencyKey struct {
+	ID           uuid.UUID `json:"id" gorm:"primaryKey"`
+	TenantID     uuid.UUID `json:"tenant_id" gorm:"<-:create;uniqueIndex:idx_idempotency_keys_scope"`
+	UserID       uuid.UUID `json:"user_id" gorm:"uniqueIndex:idx_idempotency_keys_scope"`
+	Key          string    `json:"key" gorm:"uniqueIndex:idx_idempotency_keys_scope"`
+	RequestHash  string    `json:"-"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	StatusCode   int       `json:"status_code"`
+	ContentType  string    `json:"-"`
+	ResponseBody []byte    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+}
+
+// Completed reports whether the request has finished and its response can be replayed
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
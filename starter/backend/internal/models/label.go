@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// DefaultLabelColor is used when a label is created without a color
+const DefaultLabelColor = "#9e9e9e"
+
+// Label tags tasks. A label belongs either to one user (TeamID unset) or to a
+// team, and its name is unique among the labels of that owner.
+type Label struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey"`
+	TenantID  uuid.UUID  `json:"tenant_id" gorm:"<-:create;index"`
+	Name      string     `json:"name"`
+	Color     string     `json:"color"`
+	UserID    *uuid.UUID `json:"user_id" gorm:"index"`
+	TeamID    *uuid.UUID `json:"team_id" gorm:"index"`
+	CreatedBy uuid.UUID  `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TaskLabel is the join row between a task and one of its labels
+type TaskLabel struct {
+	TaskID    uuid.UUID `json:"task_id" gorm:"primaryKey"`
+	LabelID   uuid.UUID `json:"label_id" gorm:"primaryKey;index"`
+	TenantID  uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Notification types
+const (
+	NotificationTaskAssigned  = "task.assigned"
+	NotificationTaskShared    = "task.shared"
+	NotificationTaskCommented = "task.commented"
+	NotificationTaskMentioned = "task.mentioned"
+	NotificationTaskDueSoon   = "task.due_soon"
+	NotificationTaskOverdue   = "task.overdue"
+	NotificationRoleChanged   = "role.changed"
+)
+
+// Notification is an entry in a user's in-app inbox
+type Notification struct {
+	ID        uuid.UUID  `json:"id" gorm:"primaryKey"`
+	TenantID  uuid.UUID  `json:"tenant_id" gorm:"<-:create;index"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"index"`
+	Type      string     `json:"type"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+	TaskID    *uuid.UUID `json:"task_id,omitempty"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Domain event types recorded in the outbox
+const (
+	EventTaskCreated = "task.created"
+	EventTaskUpdated = "task.updated"
+	EventTaskDeleted = "task.deleted"
+	EventUserCreated = "user.created"
+	EventUserDeleted = "user.deleted"
+	EventRoleChanged = "role.changed"
+)
+
+// Aggregate types named by outbox events
+const (
+	AggregateTask = "task"
+	AggregateUser = "user"
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change
+// it describes. The relay publishes it to every sink and marks it published
+// once all of them have taken it; until then it is retried at NextAttemptAt.
+type OutboxEvent struct {
+	ID            uuid.UUID  `json:"id" gorm:"primaryKey"`
+	TenantID      uuid.UUID  `json:"tenant_id" gorm:"<-:create;index"`
+	Type          string     `json:"type"`
+	AggregateType string     `json:"aggregate_type"`
+	AggregateID   uuid.UUID  `json:"aggregate_id"`
+	Payload       string     `json:"payload"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	PublishedAt   *time.Time `json:"published_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// OutboxDelivery records that the sink with ConsumerID has taken an event, so
+// that retrying an event only sends it to the sinks that have not
+type OutboxDelivery struct {
+	EventID     uuid.UUID `json:"event_id" gorm:"primaryKey"`
+	ConsumerID  string    `json:"consumer_id" gorm:"primaryKey"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// InboxProjectName is the name of the default project every user gets
+const InboxProjectName = "Inbox"
+
+type Project struct {
+	ID          uuid.UUID       `json:"id" gorm:"primaryKey"`
+	TenantID    uuid.UUID       `json:"tenant_id" gorm:"<-:create;index"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	OwnerID     uuid.UUID       `json:"owner_id" gorm:"index"`
+	Inbox       bool            `json:"inbox"`
+	Archived    bool            `json:"archived"`
+	Members     []ProjectMember `json:"members,omitempty" gorm:"foreignKey:ProjectID"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt  `json:"deleted_at" gorm:"index"`
+}
+
+// ProjectMember grants a user access to every task in a project. Permission
+// uses the same viewer, editor and owner levels as task shares.
+type ProjectMember struct {
+	ProjectID  uuid.UUID `json:"project_id" gorm:"primaryKey"`
+	UserID     uuid.UUID `json:"user_id" gorm:"primaryKey;index"`
+	TenantID   uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	ReminderDueSoon = "due_soon"
+	ReminderOverdue = "overdue"
+)
+
+const (
+	ReminderChannelInApp   = "in_app"
+	ReminderChannelEmail   = "email"
+	ReminderChannelWebhook = "webhook"
+)
+
+// ReminderPreference says whether, when and how a user is reminded of their
+// tasks' due dates. Users without a row get the service defaults.
+type ReminderPreference struct {
+	UserID      uuid.UUID `json:"user_id" gorm:"primaryKey"`
+	TenantID    uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	Enabled     bool      `json:"enabled"`
+	LeadMinutes int       `json:"lead_minutes"`
+	Overdue     bool      `json:"overdue"`
+	Channels    []string  `json:"channels" gorm:"serializer:json"`
+	WebhookURL  string    `json:"webhook_url"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TaskReminder records a reminder sent for a task. Each kind of reminder goes
+// out once per due date and channel, so moving the due date re-arms it.
+type TaskReminder struct {
+	ID       uuid.UUID `json:"id" gorm:"primaryKey"`
+	TenantID uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	TaskID   uuid.UUID `json:"task_id" gorm:"uniqueIndex:idx_task_reminders_sent"`
+	UserID   uuid.UUID `json:"user_id" gorm:"uniqueIndex:idx_task_reminders_sent"`
+	Kind     string    `json:"kind" gorm:"uniqueIndex:idx_task_reminders_sent"`
+	Channel  string    `json:"channel" gorm:"uniqueIndex:idx_task_reminders_sent"`
+	DueDate  time.Time `json:"due_date" gorm:"uniqueIndex:idx_task_reminders_sent"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// Lease gives one holder the exclusive right to run a background job until it
+// expires. Leases are shared by all tenants.
+type Lease struct {
+	Name      string    `json:"name" gorm:"primaryKey"`
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
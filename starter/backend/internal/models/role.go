@@ -8,7 +8,8 @@ import (
 type Role struct {
 	gorm.Model
 	ID          uuid.UUID    `json:"id" gorm:"primaryKey"`
-	Name        string       `json:"name" gorm:"unique"`
+	TenantID    uuid.UUID    `json:"tenant_id" gorm:"<-:create;uniqueIndex:idx_roles_tenant_name"`
+	Name        string       `json:"name" gorm:"uniqueIndex:idx_roles_tenant_name"`
 	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;"`
 }
 
@@ -6,15 +6,71 @@ import (
 	"gorm.io/gorm"
 )
 
+// Task statuses
+const (
+	TaskStatusPending    = "pending"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusReview     = "review"
+	TaskStatusCompleted  = "completed"
+	TaskStatusCancelled  = "cancelled"
+)
+
+// Task priorities
+const (
+	TaskPriorityLow    = "low"
+	TaskPriorityMedium = "medium"
+	TaskPriorityHigh   = "high"
+)
+
+// TaskStatuses lists every valid task status
+var TaskStatuses = []string{TaskStatusPending, TaskStatusInProgress, TaskStatusReview, TaskStatusCompleted, TaskStatusCancelled}
+
+// TaskPriorities lists every valid task priority, from lowest to highest
+var TaskPriorities = []string{TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh}
+
 type Task struct {
-	ID          uuid.UUID      `json:"id" gorm:"primaryKey"`
-	Title       string         `json:"title"`
-	Description string         `json:"description"`
-	Status      string         `json:"status"`
-	Priority    string         `json:"priority"`
-	DueDate     *time.Time     `json:"due_date"`
-	UserID      uuid.UUID      `json:"user_id"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
-} 
\ No newline at end of file
+	ID           uuid.UUID      `json:"id" gorm:"primaryKey"`
+	TenantID     uuid.UUID      `json:"tenant_id" gorm:"<-:create;index"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Status       string         `json:"status"`
+	Priority     string         `json:"priority"`
+	DueDate      *time.Time     `json:"due_date"`
+	UserID       uuid.UUID      `json:"user_id"`
+	TeamID       *uuid.UUID     `json:"team_id" gorm:"index"`
+	ProjectID    *uuid.UUID     `json:"project_id" gorm:"index"`
+	ParentID     *uuid.UUID     `json:"parent_id" gorm:"index"`
+	StartedAt    *time.Time     `json:"started_at"`
+	CompletedAt  *time.Time     `json:"completed_at"`
+	Labels       []Label        `json:"labels,omitempty" gorm:"many2many:task_labels"`
+	Recurrence   string         `json:"recurrence,omitempty"`
+	SeriesID     *uuid.UUID     `json:"series_id,omitempty" gorm:"uniqueIndex:idx_tasks_series_occurrence"`
+	OccurrenceAt *time.Time     `json:"occurrence_at,omitempty" gorm:"uniqueIndex:idx_tasks_series_occurrence"`
+	Version      int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+func ValidTaskStatus(status string) bool {
+	for _, s := range TaskStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidTaskPriority(priority string) bool {
+	for _, p := range TaskPriorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskClosed reports whether a task in this status needs no more work
+func TaskClosed(status string) bool {
+	return status == TaskStatusCompleted || status == TaskStatusCancelled
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// TaskDependency records that TaskID cannot be completed before BlockedByID
+type TaskDependency struct {
+	TaskID      uuid.UUID `json:"task_id" gorm:"primaryKey"`
+	BlockedByID uuid.UUID `json:"blocked_by_id" gorm:"primaryKey;index"`
+	TenantID    uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	CreatedBy   uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
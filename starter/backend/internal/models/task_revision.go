@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// TaskRevision records one change to a task: who made it, which fields moved
+// from what to what, and the task as it was afterwards. Revisions are numbered
+// per task from 1, the creation of the task.
+type TaskRevision struct {
+	ID       uuid.UUID              `json:"id" gorm:"primaryKey"`
+	TenantID uuid.UUID              `json:"tenant_id" gorm:"<-:create;index"`
+	TaskID   uuid.UUID              `json:"task_id" gorm:"uniqueIndex:idx_task_revisions_task_revision"`
+	Revision int                    `json:"revision" gorm:"uniqueIndex:idx_task_revisions_task_revision"`
+	ActorID  *uuid.UUID             `json:"actor_id"`
+	Action   string                 `json:"action"`
+	Changes  map[string]FieldChange `json:"changes" gorm:"serializer:json"`
+	// Snapshot is the task's JSON after the change, which a revert restores from
+	Snapshot string `json:"-"`
+	// RevertedFrom is set when the change restored an earlier revision
+	RevertedFrom *int      `json:"reverted_from,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Subject types a task can be shared with
+const (
+	ShareSubjectUser = "user"
+	ShareSubjectRole = "role"
+	ShareSubjectTeam = "team"
+)
+
+// Access levels on a task, from weakest to strongest
+const (
+	TaskAccessNone   = ""
+	TaskAccessViewer = "viewer"
+	TaskAccessEditor = "editor"
+	TaskAccessOwner  = "owner"
+)
+
+type TaskShare struct {
+	ID          uuid.UUID `json:"id" gorm:"primaryKey"`
+	TenantID    uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	TaskID      uuid.UUID `json:"task_id" gorm:"index;uniqueIndex:idx_task_shares_subject"`
+	SubjectType string    `json:"subject_type" gorm:"uniqueIndex:idx_task_shares_subject"`
+	SubjectID   uuid.UUID `json:"subject_id" gorm:"index;uniqueIndex:idx_task_shares_subject"`
+	Permission  string    `json:"permission"`
+	GrantedBy   uuid.UUID `json:"granted_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TaskAccessRank orders access levels so they can be compared
+func TaskAccessRank(level string) int {
+	switch level {
+	case TaskAccessViewer:
+		return 1
+	case TaskAccessEditor:
+		return 2
+	case TaskAccessOwner:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// ValidTaskAccess reports whether level can be granted through a share
+func ValidTaskAccess(level string) bool {
+	return TaskAccessRank(level) > 0
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Roles a user can hold inside a team
+const (
+	TeamRoleAdmin  = "admin"
+	TeamRoleMember = "member"
+)
+
+type Team struct {
+	ID          uuid.UUID      `json:"id" gorm:"primaryKey"`
+	TenantID    uuid.UUID      `json:"tenant_id" gorm:"<-:create;index"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	CreatedBy   uuid.UUID      `json:"created_by"`
+	Members     []TeamMember   `json:"members,omitempty" gorm:"foreignKey:TeamID"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+}
+
+type TeamMember struct {
+	TeamID    uuid.UUID `json:"team_id" gorm:"primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"primaryKey;index"`
+	TenantID  uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ValidTeamRole reports whether role is a known team role
+func ValidTeamRole(role string) bool {
+	return role == TeamRoleAdmin || role == TeamRoleMember
+}
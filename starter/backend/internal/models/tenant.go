@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// DefaultTenantID is the tenant seeded by the migrations and used when a
+// request does not name one
+var DefaultTenantID = uuid.Must(uuid.FromString("00000000-0000-0000-0000-000000000001"))
+
+type Tenant struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug" gorm:"unique"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -10,6 +10,7 @@ import (
 type Token struct {
 	gorm.Model
 	ID           uuid.UUID `json:"id" gorm:"primaryKey"`
+	TenantID     uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
 	UserId       uuid.UUID `json:"user_id"`
 	RefreshToken uuid.UUID `json:"refresh_token"`
 	ExpiresAt    time.Time `json:"expires_at"`
@@ -8,8 +8,9 @@ import (
 type User struct {
 	gorm.Model
 	ID       uuid.UUID `json:"id" gorm:"primaryKey"`
-	Username string    `json:"username" gorm:"unique"`
-	Email    string    `json:"email" gorm:"unique"`
+	TenantID uuid.UUID `json:"tenant_id" gorm:"<-:create;uniqueIndex:idx_users_tenant_username;uniqueIndex:idx_users_tenant_email"`
+	Username string    `json:"username" gorm:"uniqueIndex:idx_users_tenant_username"`
+	Email    string    `json:"email" gorm:"uniqueIndex:idx_users_tenant_email"`
 	Password string    `json:"password"`
 	Roles    []Role    `json:"roles" gorm:"many2many:user_roles;"`
 }
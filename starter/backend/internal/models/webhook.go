@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Webhook event types
+const (
+	WebhookTaskCreated = EventTaskCreated
+	WebhookTaskUpdated = EventTaskUpdated
+	WebhookTaskDeleted = EventTaskDeleted
+	WebhookUserCreated = EventUserCreated
+	WebhookUserDeleted = EventUserDeleted
+)
+
+// WebhookEventTypes lists every event a webhook can subscribe to
+var WebhookEventTypes = []string{WebhookTaskCreated, WebhookTaskUpdated, WebhookTaskDeleted, WebhookUserCreated, WebhookUserDeleted}
+
+// Webhook scopes. A user webhook receives events about the tasks its owner can
+// see; a tenant webhook, which only admins can own, receives every task event
+// of the tenant and the user events as well.
+const (
+	WebhookScopeUser   = "user"
+	WebhookScopeTenant = "tenant"
+)
+
+// Webhook delivery states
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook subscribes a URL to events. Payloads are signed with Secret, which is
+// only ever shown when the webhook is created.
+type Webhook struct {
+	ID        uuid.UUID `json:"id" gorm:"primaryKey"`
+	TenantID  uuid.UUID `json:"tenant_id" gorm:"<-:create;index"`
+	UserID    uuid.UUID `json:"user_id" gorm:"index"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events" gorm:"serializer:json"`
+	Scope     string    `json:"scope"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookDelivery is one event queued for one webhook, and the log of how
+// sending it went. Pending deliveries are retried at NextAttemptAt.
+type WebhookDelivery struct {
+	ID            uuid.UUID  `json:"id" gorm:"primaryKey"`
+	TenantID      uuid.UUID  `json:"tenant_id" gorm:"<-:create;index"`
+	WebhookID     uuid.UUID  `json:"webhook_id" gorm:"index"`
+	EventID       uuid.UUID  `json:"event_id"`
+	EventType     string     `json:"event_type"`
+	Payload       string     `json:"payload"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"response_code"`
+	ResponseBody  string     `json:"response_body"`
+	Error         string     `json:"error"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	DeliveredAt   *time.Time `json:"delivered_at"`
+	RedeliveryOf  *uuid.UUID `json:"redelivery_of,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,34 @@
+// Package notify delivers messages to users over external channels such as
+// email and webhooks. Channels that live in the database, like the in-app
+// inbox, implement Notifier in the services package.
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+var ErrNoAddress = errors.New("recipient has no address for this channel")
+
+// Message is one notification for one user. Each channel uses the fields it
+// needs: email sends Subject and Text to Email, webhooks post the message as
+// JSON to WebhookURL.
+type Message struct {
+	TenantID   uuid.UUID  `json:"tenant_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Event      string     `json:"event"`
+	TaskID     *uuid.UUID `json:"task_id,omitempty"`
+	Subject    string     `json:"subject"`
+	Text       string     `json:"text"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Email      string     `json:"-"`
+	WebhookURL string     `json:"-"`
+}
+
+// Notifier sends a message over one channel
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicAddress is returned when a user-supplied URL resolves to an
+// address inside the deployment rather than on the internet
+var ErrNonPublicAddress = errors.New("address is not publicly routable")
+
+// NewPublicClient returns an HTTP client for URLs chosen by users, such as
+// webhook receivers. It refuses to connect to loopback, private, link-local,
+// multicast and unspecified addresses, so those URLs cannot reach the
+// metrics port, cloud metadata or other internal services. The address is
+// checked after DNS resolution, when the connection is made, so a name that
+// resolves to an internal address is refused too. Redirects are not followed:
+// the 3xx is returned as the response.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   refuseNonPublic,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// refuseNonPublic runs before every connection with the resolved address
+func refuseNonPublic(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, address)
+	}
+	if !PublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// PublicAddr reports whether addr may be reached by a user-supplied URL
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which is not
+// covered by IsPrivate but is just as internal
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
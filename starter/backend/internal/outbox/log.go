@@ -0,0 +1,24 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogSink writes every event to the process log
+type LogSink struct {
+	id string
+}
+
+func NewLogSink(id string) *LogSink {
+	return &LogSink{id: id}
+}
+
+func (s *LogSink) ID() string {
+	return s.id
+}
+
+func (s *LogSink) Publish(ctx context.Context, event Event) error {
+	slog.InfoContext(ctx, "outbox event", "event_id", event.ID, "type", event.Type, "aggregate_type", event.AggregateType, "aggregate_id", event.AggregateID, "data", event.Data)
+	return nil
+}
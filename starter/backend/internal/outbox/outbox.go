@@ -0,0 +1,62 @@
+// Package outbox holds the sinks the outbox relay publishes domain events to.
+// Delivery is at least once: a sink may be handed an event again after a
+// failure or a restart, so every event carries an ID that consumers use to
+// drop duplicates.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"task-manager/backend/internal/utils"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Event is a domain event as sinks publish it. Data holds the aggregate as it
+// was when the change was made.
+type Event struct {
+	ID            uuid.UUID       `json:"id"`
+	Type          string          `json:"type"`
+	TenantID      uuid.UUID       `json:"tenant_id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uuid.UUID       `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Sink publishes events to one consumer. ID names that consumer: the relay
+// remembers per ID which events it has taken, so it must not change between
+// restarts, and renaming a sink makes it receive the pending events again.
+type Sink interface {
+	ID() string
+	Publish(ctx context.Context, event Event) error
+}
+
+// FromEnv builds the sinks named in OUTBOX_SINKS, a comma-separated list of
+// "log" and "webhook". The webhook sink posts to OUTBOX_WEBHOOK_URL, signed
+// with OUTBOX_WEBHOOK_SECRET when set. Stream sinks need a broker client and
+// are wired up in code.
+func FromEnv() ([]Sink, error) {
+	var sinks []Sink
+	for _, kind := range strings.Split(utils.GetEnv("OUTBOX_SINKS", "log"), ",") {
+		switch kind = strings.TrimSpace(kind); kind {
+		case "":
+		case "log":
+			sinks = append(sinks, NewLogSink("log"))
+		case "webhook":
+			url := utils.GetEnv("OUTBOX_WEBHOOK_URL", "")
+			if url == "" {
+				return nil, errors.New("OUTBOX_WEBHOOK_URL is required for the webhook sink")
+			}
+			sinks = append(sinks, NewWebhookSink("webhook", url, utils.GetEnv("OUTBOX_WEBHOOK_SECRET", ""),
+				utils.GetEnvAsDuration("OUTBOX_WEBHOOK_TIMEOUT", 10*time.Second)))
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", kind)
+		}
+	}
+	return sinks, nil
+}
@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// StreamMessage is one message for a log-based broker
+type StreamMessage struct {
+	// ID is the event ID. Adapters pass it on where the broker deduplicates,
+	// such as the Nats-Msg-Id header of NATS JetStream, or as a header for
+	// Kafka consumers to deduplicate on.
+	ID string
+	// Subject is the NATS subject or Kafka topic
+	Subject string
+	// Key is the aggregate ID. Used as the Kafka partition key it keeps the
+	// events of one task or user in order.
+	Key  string
+	Data []byte
+}
+
+// StreamPublisher is the producer side of a broker such as NATS JetStream or
+// Kafka. An adapter around the broker's client library implements it; Publish
+// must only return once the broker has acknowledged the message.
+type StreamPublisher interface {
+	Publish(ctx context.Context, msg StreamMessage) error
+}
+
+// StreamSink publishes every event to a broker under "<prefix>.<event type>",
+// for example "taskmanager.task.created"
+type StreamSink struct {
+	id        string
+	prefix    string
+	publisher StreamPublisher
+}
+
+func NewStreamSink(id, prefix string, publisher StreamPublisher) *StreamSink {
+	return &StreamSink{id: id, prefix: prefix, publisher: publisher}
+}
+
+func (s *StreamSink) ID() string {
+	return s.id
+}
+
+func (s *StreamSink) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.publisher.Publish(ctx, StreamMessage{
+		ID:      event.ID.String(),
+		Subject: s.prefix + "." + event.Type,
+		Key:     event.AggregateID.String(),
+		Data:    data,
+	})
+}
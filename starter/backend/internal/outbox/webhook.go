@@ -0,0 +1,64 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookSink posts every event as JSON to one URL. The event ID is sent as
+// the Idempotency-Key header, and with a secret the body is signed with
+// HMAC-SHA256 in X-Outbox-Signature. Any response other than 2xx fails the
+// delivery, which the relay retries.
+type WebhookSink struct {
+	id     string
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookSink(id, url, secret string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{id: id, url: url, secret: secret, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSink) ID() string {
+	return s.id
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", event.ID.String())
+	req.Header.Set("X-Outbox-Event", event.Type)
+	if s.secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write(body)
+		req.Header.Set("X-Outbox-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("outbox webhook answered %s", resp.Status)
+	}
+	return nil
+}
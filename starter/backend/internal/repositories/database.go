@@ -2,14 +2,15 @@ package repositories
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"task-manager/backend/internal/logging"
+	"task-manager/backend/internal/metrics"
 	"task-manager/backend/internal/utils"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 type DatabaseConfig struct {
@@ -22,6 +23,9 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	// SlowQueryThreshold is how long a query may take before it is logged
+	// as slow
+	SlowQueryThreshold time.Duration
 }
 
 func NewDatabaseConfig() *DatabaseConfig {
@@ -35,22 +39,34 @@ func NewDatabaseConfig() *DatabaseConfig {
 		MaxOpenConns:    utils.GetEnvAsInt("DB_MAX_OPEN_CONNS", 25),
 		MaxIdleConns:    utils.GetEnvAsInt("DB_MAX_IDLE_CONNS", 5),
 		ConnMaxLifetime: utils.GetEnvAsDuration("DB_CONN_MAX_LIFETIME", time.Hour),
+
+		SlowQueryThreshold: utils.GetEnvAsDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
 	}
 }
 
-func (cfg *DatabaseConfig) Connect() (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
+// DSN returns the connection string for the configured database
+func (cfg *DatabaseConfig) DSN() string {
+	return fmt.Sprintf(
 		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
 		cfg.Host, cfg.User, cfg.Password, cfg.Name, cfg.Port, cfg.SSLMode,
 	)
+}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
+func (cfg *DatabaseConfig) Connect() (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{
+		Logger: logging.NewGormLogger(slog.Default(), cfg.SlowQueryThreshold),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if err := RegisterTenantScope(db); err != nil {
+		return nil, fmt.Errorf("failed to register tenant scope: %w", err)
+	}
+	if err := metrics.InstrumentGorm(db); err != nil {
+		return nil, fmt.Errorf("failed to instrument queries: %w", err)
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database instance: %w", err)
@@ -80,8 +96,8 @@ func NewDatabaseConnection() (*gorm.DB, error) {
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
-		log.Fatal(err)
-		return nil, err
+		slog.Error("failed to connect to database", "error", err)
+		os.Exit(1)
 	}
 	return db, nil
 }
@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrMissingTenant = errors.New("tenant not resolved for tenant-scoped query")
+
+type tenantContextKey struct{}
+type tenantBypassKey struct{}
+
+// WithTenant returns a context whose database queries are scoped to tenantID
+func WithTenant(ctx context.Context, tenantID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant set by WithTenant
+func TenantFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	tenantID, ok := ctx.Value(tenantContextKey{}).(uuid.UUID)
+	return tenantID, ok && tenantID != uuid.Nil
+}
+
+// WithoutTenantScope marks a context as deliberately crossing tenants, for
+// system work such as migrations, provisioning and background jobs.
+func WithoutTenantScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantBypassKey{}, true)
+}
+
+func tenantScopeBypassed(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	bypass, _ := ctx.Value(tenantBypassKey{}).(bool)
+	return bypass
+}
+
+// RegisterTenantScope installs GORM callbacks that scope every query, update
+// and delete on a model with a TenantID field to the tenant in the statement
+// context, and stamp that tenant on every created row. Statements on tenant
+// models without a tenant in their context fail with ErrMissingTenant, so a
+// handler that forgets to pass the request context cannot read across tenants.
+// Raw SQL is not rewritten.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:scope_query", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:scope_row", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:scope_update", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:scope_delete", scopeToTenant); err != nil {
+		return err
+	}
+	return callbacks.Create().Before("gorm:create").Register("tenant:stamp_create", stampTenant)
+}
+
+func tenantField(db *gorm.DB) (*gorm.Statement, bool) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || tenantScopeBypassed(stmt.Context) {
+		return stmt, false
+	}
+	return stmt, stmt.Schema.LookUpField("TenantID") != nil
+}
+
+func scopeToTenant(db *gorm.DB) {
+	stmt, ok := tenantField(db)
+	if !ok {
+		return
+	}
+	tenantID, ok := TenantFromContext(stmt.Context)
+	if !ok {
+		db.AddError(ErrMissingTenant)
+		return
+	}
+
+	field := stmt.Schema.LookUpField("TenantID")
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+func stampTenant(db *gorm.DB) {
+	stmt, ok := tenantField(db)
+	if !ok {
+		return
+	}
+	tenantID, ok := TenantFromContext(stmt.Context)
+	if !ok {
+		db.AddError(ErrMissingTenant)
+		return
+	}
+
+	// The context tenant is authoritative; whatever the caller put in TenantID is overwritten
+	field := stmt.Schema.LookUpField("TenantID")
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			if err := field.Set(stmt.Context, reflect.Indirect(stmt.ReflectValue.Index(i)), tenantID); err != nil {
+				db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(stmt.Context, stmt.ReflectValue, tenantID); err != nil {
+			db.AddError(err)
+		}
+	}
+}
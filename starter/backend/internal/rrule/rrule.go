@@ -0,0 +1,469 @@
+// Package rrule parses iCalendar (RFC 5545) recurrence rules and computes
+// their occurrences. It supports the parts of the grammar that task schedules
+// need: FREQ from DAILY to YEARLY, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY,
+// BYMONTH and WKST. Rules using any other part are rejected.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Frequencies a rule can repeat at
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+	Yearly  = "YEARLY"
+)
+
+// horizon bounds the search for the next occurrence, so rules that can never
+// match again, such as the 30th of February, end instead of looping forever
+const horizon = 50
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Weekday is a BYDAY entry. N selects the Nth such day of the month, counting
+// from the end when negative; zero means every such day.
+type Weekday struct {
+	N   int
+	Day time.Weekday
+}
+
+func (w Weekday) String() string {
+	if w.N == 0 {
+		return weekdayNames[w.Day]
+	}
+	return strconv.Itoa(w.N) + weekdayNames[w.Day]
+}
+
+type Rule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	ByDay      []Weekday
+	ByMonthDay []int
+	ByMonth    []int
+	WeekStart  time.Weekday
+
+	// until is kept as written: a date, a UTC time or a floating local time
+	until string
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;BYDAY=MO,TH". A leading "RRULE:" is
+// accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrInvalidRule, key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch val {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = val
+			default:
+				err = fmt.Errorf("unsupported FREQ %s", val)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseInt(val, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseInt(val, 1, 10000)
+		case "UNTIL":
+			if _, _, err = parseUntil(val, time.UTC); err == nil {
+				rule.until = val
+			}
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(val, -31, 31)
+		case "BYMONTH":
+			rule.ByMonth, err = parseIntList(val, 1, 12)
+		case "WKST":
+			day, ok := weekdays[val]
+			if !ok {
+				err = fmt.Errorf("unknown WKST %s", val)
+			}
+			rule.WeekStart = day
+		default:
+			err = fmt.Errorf("unsupported part %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	}
+
+	if err := rule.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return rule, nil
+}
+
+func (r *Rule) validate() error {
+	if r.Freq == "" {
+		return errors.New("FREQ is required")
+	}
+	if r.Count > 0 && r.until != "" {
+		return errors.New("COUNT and UNTIL cannot both be given")
+	}
+	for _, day := range r.ByDay {
+		if day.N == 0 {
+			continue
+		}
+		if r.Freq != Monthly && r.Freq != Yearly {
+			return errors.New("numbered BYDAY needs FREQ=MONTHLY or FREQ=YEARLY")
+		}
+		if r.Freq == Yearly && len(r.ByMonth) == 0 {
+			return errors.New("numbered BYDAY with FREQ=YEARLY needs BYMONTH")
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+		return errors.New("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	return nil
+}
+
+// String formats the rule in a canonical order
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.until != "" {
+		parts = append(parts, "UNTIL="+r.until)
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = day.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// WithUntil returns a copy of the rule that ends at until, replacing any COUNT
+// or UNTIL the rule had
+func (r *Rule) WithUntil(until time.Time) *Rule {
+	copied := *r
+	copied.Count = 0
+	copied.until = until.UTC().Format("20060102T150405Z")
+	return &copied
+}
+
+// After returns the first occurrence of the rule later than after, for a
+// series starting at dtstart. The start is always the first occurrence and
+// counts towards COUNT. ok is false when the series has ended.
+func (r *Rule) After(dtstart, after time.Time) (next time.Time, ok bool) {
+	r.each(dtstart, func(t time.Time) bool {
+		if t.After(after) {
+			next, ok = t, true
+			return false
+		}
+		return true
+	})
+	return next, ok
+}
+
+// each calls yield with every occurrence in order until it returns false or
+// the series ends
+func (r *Rule) each(dtstart time.Time, yield func(time.Time) bool) {
+	until, hasUntil, _ := parseUntil(r.until, dtstart.Location())
+	count := 0
+	emit := func(t time.Time) bool {
+		if hasUntil && t.After(until) {
+			return false
+		}
+		count++
+		if r.Count > 0 && count > r.Count {
+			return false
+		}
+		return yield(t)
+	}
+
+	if !emit(dtstart) {
+		return
+	}
+	end := dtstart.AddDate(horizon, 0, 0)
+	for period := 0; ; period++ {
+		candidates, periodStart := r.candidates(dtstart, period)
+		if periodStart.After(end) {
+			return
+		}
+		for _, t := range candidates {
+			if !t.After(dtstart) {
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+// candidates lists, in order, the occurrences in the nth period after the one
+// holding dtstart, along with the start of that period
+func (r *Rule) candidates(dtstart time.Time, n int) ([]time.Time, time.Time) {
+	year, month, day := dtstart.Date()
+	at := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location())
+	}
+
+	var times []time.Time
+	switch r.Freq {
+	case Daily:
+		t := at(year, month, day+n*r.Interval)
+		if r.monthAllowed(t.Month()) && r.monthDayAllowed(t) && r.weekdayAllowed(t.Weekday()) {
+			times = append(times, t)
+		}
+		return times, t
+
+	case Weekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := at(year, month, day-offset+n*r.Interval*7)
+		for i := 0; i < 7; i++ {
+			t := weekStart.AddDate(0, 0, i)
+			matches := t.Weekday() == dtstart.Weekday()
+			if len(r.ByDay) > 0 {
+				matches = r.weekdayAllowed(t.Weekday())
+			}
+			if matches && r.monthAllowed(t.Month()) {
+				times = append(times, t)
+			}
+		}
+		return times, weekStart
+
+	case Monthly:
+		first := at(year, month+time.Month(n*r.Interval), 1)
+		if r.monthAllowed(first.Month()) {
+			for _, d := range r.monthDays(first.Year(), first.Month(), day) {
+				times = append(times, at(first.Year(), first.Month(), d))
+			}
+		}
+		return times, first
+
+	default:
+		y := year + n*r.Interval
+		months := r.ByMonth
+		if len(months) == 0 {
+			if len(r.ByDay) > 0 || len(r.ByMonthDay) > 0 {
+				months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			} else {
+				months = []int{int(month)}
+			}
+		}
+		sorted := append([]int(nil), months...)
+		sort.Ints(sorted)
+		for _, m := range sorted {
+			for _, d := range r.monthDays(y, time.Month(m), day) {
+				times = append(times, at(y, time.Month(m), d))
+			}
+		}
+		return times, at(y, 1, 1)
+	}
+}
+
+// monthDays lists the days of a month matching BYMONTHDAY and BYDAY. Without
+// either, the series repeats on the start's day, skipping months too short
+// to have it.
+func (r *Rule) monthDays(year int, month time.Month, startDay int) []int {
+	length := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if startDay <= length {
+			return []int{startDay}
+		}
+		return nil
+	}
+
+	byMonthDay := map[int]bool{}
+	for _, md := range r.ByMonthDay {
+		if md < 0 {
+			md = length + md + 1
+		}
+		if md >= 1 && md <= length {
+			byMonthDay[md] = true
+		}
+	}
+
+	byDay := map[int]bool{}
+	firstWeekday := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	for _, wd := range r.ByDay {
+		first := 1 + (int(wd.Day)-int(firstWeekday)+7)%7
+		switch {
+		case wd.N == 0:
+			for d := first; d <= length; d += 7 {
+				byDay[d] = true
+			}
+		case wd.N > 0:
+			if d := first + (wd.N-1)*7; d <= length {
+				byDay[d] = true
+			}
+		default:
+			last := first + (length-first)/7*7
+			if d := last + (wd.N+1)*7; d >= 1 {
+				byDay[d] = true
+			}
+		}
+	}
+
+	var days []int
+	for d := 1; d <= length; d++ {
+		inMonthDay := len(r.ByMonthDay) == 0 || byMonthDay[d]
+		inDay := len(r.ByDay) == 0 || byDay[d]
+		if inMonthDay && inDay {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+func (r *Rule) monthAllowed(month time.Month) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if time.Month(m) == month {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) monthDayAllowed(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	length := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, md := range r.ByMonthDay {
+		if md == t.Day() || length+md+1 == t.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) weekdayAllowed(day time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// parseUntil reads an UNTIL value. A bare date includes the whole day and a
+// time without a Z is read in loc, as RFC 5545 prescribes for floating times.
+func parseUntil(value string, loc *time.Location) (time.Time, bool, error) {
+	switch {
+	case value == "":
+		return time.Time{}, false, nil
+	case len(value) == 8:
+		date, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("malformed UNTIL %s", value)
+		}
+		return date.AddDate(0, 0, 1).Add(-time.Nanosecond), true, nil
+	case strings.HasSuffix(value, "Z"):
+		until, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("malformed UNTIL %s", value)
+		}
+		return until, true, nil
+	default:
+		until, err := time.ParseInLocation("20060102T150405", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("malformed UNTIL %s", value)
+		}
+		return until, true, nil
+	}
+}
+
+func parseByDay(value string) ([]Weekday, error) {
+	var days []Weekday
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("malformed BYDAY %s", item)
+		}
+		day, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("malformed BYDAY %s", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			if n, err = parseInt(strings.TrimPrefix(prefix, "+"), -5, 5); err != nil || n == 0 {
+				return nil, fmt.Errorf("malformed BYDAY %s", item)
+			}
+		}
+		days = append(days, Weekday{N: n, Day: day})
+	}
+	return days, nil
+}
+
+func parseIntList(value string, min, max int) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := parseInt(item, min, max)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("value %s out of range", item)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func parseInt(value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("value %s out of range", value)
+	}
+	return n, nil
+}
+
+func joinInts(values []int) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.Itoa(v)
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// Actor identifies the authenticated user a service call is made on behalf of
+type Actor struct {
+	UserID uuid.UUID
+	Roles  []string
+}
+
+// HasRole reports whether the actor carries the given global role
+func (a Actor) HasRole(role string) bool {
+	for _, r := range a.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin reports whether the actor has the global admin role
+func (a Actor) IsAdmin() bool {
+	return a.HasRole("admin")
+}
+
+// ActorForUser loads the actor for a user other than the caller, for example
+// to check whether someone mentioned in a comment can see the task
+func ActorForUser(db *gorm.DB, userID uuid.UUID) (Actor, error) {
+	var user models.User
+	if err := db.Preload("Roles").First(&user, "id = ?", userID).Error; err != nil {
+		return Actor{}, err
+	}
+
+	actor := Actor{UserID: user.ID}
+	for _, role := range user.Roles {
+		actor.Roles = append(actor.Roles, role.Name)
+	}
+	return actor, nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/storage"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttachmentTooLarge = apperr.New(apperr.KindTooLarge, "attachment_too_large", "attachment exceeds the maximum size")
+	ErrEmptyAttachment    = apperr.Invalid("file", "empty_attachment", "attachment is empty")
+)
+
+type AttachmentService interface {
+	Upload(db *gorm.DB, task *models.Task, uploadedBy uuid.UUID, fileName string, body io.Reader) (*models.Attachment, error)
+	GetAttachments(db *gorm.DB, taskID uuid.UUID) ([]models.Attachment, error)
+	GetAttachment(db *gorm.DB, taskID, attachmentID uuid.UUID) (*models.Attachment, error)
+	GetAttachmentByID(db *gorm.DB, attachmentID uuid.UUID) (*models.Attachment, error)
+	Open(db *gorm.DB, attachment *models.Attachment) (io.ReadCloser, error)
+	DeleteAttachment(db *gorm.DB, attachment *models.Attachment) error
+	MaxSize() int64
+}
+
+type AttachmentServiceImpl struct {
+	storage storage.Storage
+	maxSize int64
+}
+
+func NewAttachmentService(store storage.Storage, maxSize int64) *AttachmentServiceImpl {
+	return &AttachmentServiceImpl{storage: store, maxSize: maxSize}
+}
+
+// Upload stores the file and records it on the task. The content type is
+// sniffed from the bytes rather than trusted from the client, and a SHA-256
+// checksum is kept so downloads can be verified.
+func (s *AttachmentServiceImpl) Upload(db *gorm.DB, task *models.Task, uploadedBy uuid.UUID, fileName string, body io.Reader) (*models.Attachment, error) {
+	data, err := io.ReadAll(io.LimitReader(body, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	if len(data) == 0 {
+		return nil, ErrEmptyAttachment
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	attachment := models.Attachment{
+		ID:          id,
+		TaskID:      task.ID,
+		UploadedBy:  uploadedBy,
+		FileName:    cleanFileName(fileName),
+		ContentType: http.DetectContentType(data),
+		Size:        int64(len(data)),
+		Checksum:    hex.EncodeToString(sum[:]),
+		StorageKey:  "tasks/" + task.ID.String() + "/" + id.String(),
+	}
+
+	ctx := db.Statement.Context
+	if err := s.storage.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.Size, attachment.ContentType); err != nil {
+		return nil, err
+	}
+	if err := db.Create(&attachment).Error; err != nil {
+		if cleanupErr := s.storage.Delete(ctx, attachment.StorageKey); cleanupErr != nil {
+			slog.ErrorContext(ctx, "removing orphaned attachment failed", "storage_key", attachment.StorageKey, "error", cleanupErr)
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (s *AttachmentServiceImpl) GetAttachments(db *gorm.DB, taskID uuid.UUID) ([]models.Attachment, error) {
+	attachments := []models.Attachment{}
+	if err := db.Where("task_id = ?", taskID).Order("created_at").Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (s *AttachmentServiceImpl) GetAttachment(db *gorm.DB, taskID, attachmentID uuid.UUID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := db.First(&attachment, "id = ? AND task_id = ?", attachmentID, taskID).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (s *AttachmentServiceImpl) GetAttachmentByID(db *gorm.DB, attachmentID uuid.UUID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := db.First(&attachment, "id = ?", attachmentID).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (s *AttachmentServiceImpl) Open(db *gorm.DB, attachment *models.Attachment) (io.ReadCloser, error) {
+	return s.storage.Get(db.Statement.Context, attachment.StorageKey)
+}
+
+// DeleteAttachment removes the record first, so a storage failure can only
+// leave an unreachable object behind, never a record without its file
+func (s *AttachmentServiceImpl) DeleteAttachment(db *gorm.DB, attachment *models.Attachment) error {
+	if err := db.Delete(&models.Attachment{}, "id = ?", attachment.ID).Error; err != nil {
+		return err
+	}
+	if err := s.storage.Delete(db.Statement.Context, attachment.StorageKey); err != nil {
+		slog.ErrorContext(db.Statement.Context, "removing attachment from storage failed", "storage_key", attachment.StorageKey, "error", err)
+	}
+	return nil
+}
+
+func (s *AttachmentServiceImpl) MaxSize() int64 {
+	return s.maxSize
+}
+
+// cleanFileName keeps the base name of an uploaded file without control
+// characters, as it ends up in a Content-Disposition header
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/repositories"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// auditGenesisHash is the PrevHash of the first entry of every chain
+var auditGenesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// auditIgnoredFields are left out of change diffs: updated_at and a task's
+// version change with every write, and labels are managed through their own
+// endpoints
+var auditIgnoredFields = map[string]bool{"updated_at": true, "version": true, "labels": true}
+
+// auditBatch is how many entries verification and export read at a time
+const auditBatch = 500
+
+// RequestInfo describes the request a change is made in, for the audit log
+type RequestInfo struct {
+	ActorID   *uuid.UUID
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo attaches info to ctx; database calls made with the context
+// record it in the audit entries they write
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns the info set by WithRequestInfo. Background
+// jobs have none, and their entries have no actor.
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	if ctx == nil {
+		return RequestInfo{}
+	}
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
+
+// AuditFilter narrows an audit log listing. Zero-valued fields are ignored.
+type AuditFilter struct {
+	Action     string
+	ActorID    *uuid.UUID
+	TargetType string
+	TargetID   *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+// Scope applies the filter to an audit entry query
+func (f AuditFilter) Scope(query *gorm.DB) *gorm.DB {
+	if f.Action != "" {
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.ActorID != nil {
+		query = query.Where("actor_id = ?", *f.ActorID)
+	}
+	if f.TargetType != "" {
+		query = query.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != nil {
+		query = query.Where("target_id = ?", *f.TargetID)
+	}
+	if f.From != nil {
+		query = query.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("created_at < ?", *f.To)
+	}
+	return query
+}
+
+// AuditVerification is the outcome of checking one tenant's chain. When the
+// chain is broken, BrokenAt is the sequence number of the first entry that
+// does not match.
+type AuditVerification struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	Entries  int64     `json:"entries"`
+	Valid    bool      `json:"valid"`
+	BrokenAt *int64    `json:"broken_at,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+type AuditService interface {
+	GetEntries(db *gorm.DB, filter AuditFilter, page Page) ([]models.AuditEntry, int64, error)
+	ExportEntries(db *gorm.DB, filter AuditFilter, write func(*models.AuditEntry) error) error
+	VerifyChain(db *gorm.DB, tenantID uuid.UUID) (*AuditVerification, error)
+	VerifyChains(db *gorm.DB) ([]AuditVerification, error)
+}
+
+type AuditServiceImpl struct{}
+
+func NewAuditService() *AuditServiceImpl {
+	return &AuditServiceImpl{}
+}
+
+// GetEntries lists matching entries, newest first and paginated
+func (s *AuditServiceImpl) GetEntries(db *gorm.DB, filter AuditFilter, page Page) ([]models.AuditEntry, int64, error) {
+	query := db.Model(&models.AuditEntry{}).Scopes(filter.Scope)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	entries := []models.AuditEntry{}
+	err := query.Order("seq DESC").Offset(page.Offset()).Limit(page.Size).Find(&entries).Error
+	return entries, total, err
+}
+
+// ExportEntries passes every matching entry to write, oldest first, reading
+// them in batches so that the whole log is never held in memory
+func (s *AuditServiceImpl) ExportEntries(db *gorm.DB, filter AuditFilter, write func(*models.AuditEntry) error) error {
+	return eachAuditEntry(db.Scopes(filter.Scope), write)
+}
+
+// VerifyChain recomputes the tenant's chain from its first entry and reports
+// the first entry that was altered, removed or inserted out of order
+func (s *AuditServiceImpl) VerifyChain(db *gorm.DB, tenantID uuid.UUID) (*AuditVerification, error) {
+	result := &AuditVerification{TenantID: tenantID, Valid: true}
+	prevHash := auditGenesisHash
+	errBroken := errors.New("chain broken")
+	err := eachAuditEntry(db.Where("tenant_id = ?", tenantID), func(entry *models.AuditEntry) error {
+		result.Entries++
+		reason := ""
+		switch {
+		case entry.Seq != result.Entries:
+			reason = fmt.Sprintf("expected entry %d", result.Entries)
+		case entry.PrevHash != prevHash:
+			reason = "previous hash does not match"
+		default:
+			hash, err := auditHash(entry)
+			if err != nil {
+				return err
+			}
+			if hash != entry.Hash {
+				reason = "entry does not match its hash"
+			}
+		}
+		if reason != "" {
+			seq := entry.Seq
+			result.Valid, result.BrokenAt, result.Reason = false, &seq, reason
+			return errBroken
+		}
+		prevHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBroken) {
+		return nil, err
+	}
+	return result, nil
+}
+
+// VerifyChains verifies the chain of every tenant that has audit entries. It
+// needs a database handle that is not scoped to a tenant.
+func (s *AuditServiceImpl) VerifyChains(db *gorm.DB) ([]AuditVerification, error) {
+	var tenantIDs []uuid.UUID
+	if err := db.Model(&models.AuditEntry{}).Distinct("tenant_id").Order("tenant_id").Pluck("tenant_id", &tenantIDs).Error; err != nil {
+		return nil, err
+	}
+	results := make([]AuditVerification, 0, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		result, err := s.VerifyChain(db, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// auditRecord is an entry to append. The tenant, when the request has one,
+// and the actor, IP, user agent and request ID come from the context.
+type auditRecord struct {
+	TenantID   uuid.UUID
+	Action     string
+	ActorID    *uuid.UUID
+	TargetType string
+	TargetID   *uuid.UUID
+	Before     interface{}
+	After      interface{}
+	Detail     string
+}
+
+// recordAudit appends an entry to the tenant's chain. Called with the
+// transaction that makes the change, the entry commits with it. On Postgres
+// appends to one chain are serialized with an advisory lock that is held until
+// the transaction ends.
+func recordAudit(tx *gorm.DB, record auditRecord) error {
+	ctx := tx.Statement.Context
+	info := RequestInfoFromContext(ctx)
+	if tenantID, ok := repositories.TenantFromContext(ctx); ok {
+		record.TenantID = tenantID
+	}
+	if record.ActorID == nil {
+		record.ActorID = info.ActorID
+	}
+	changes, err := auditChanges(record.Before, record.After)
+	if err != nil {
+		return err
+	}
+
+	return tx.Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "audit:"+record.TenantID.String()).Error; err != nil {
+				return err
+			}
+		}
+
+		var last models.AuditEntry
+		err := tx.Select("seq", "hash").Where("tenant_id = ?", record.TenantID).Order("seq DESC").Take(&last).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		prevHash := auditGenesisHash
+		if err == nil {
+			prevHash = last.Hash
+		}
+
+		id, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+		entry := models.AuditEntry{
+			ID:         id,
+			TenantID:   record.TenantID,
+			Seq:        last.Seq + 1,
+			Action:     record.Action,
+			ActorID:    record.ActorID,
+			TargetType: record.TargetType,
+			TargetID:   record.TargetID,
+			Changes:    changes,
+			Detail:     record.Detail,
+			IP:         info.IP,
+			UserAgent:  info.UserAgent,
+			RequestID:  info.RequestID,
+			PrevHash:   prevHash,
+			// Postgres keeps microseconds; the hash must cover the stored time
+			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		}
+		if entry.Hash, err = auditHash(&entry); err != nil {
+			return err
+		}
+		return tx.Create(&entry).Error
+	})
+}
+
+// recordTaskAudit records a change to a task. Before is nil for created
+// tasks, after for deleted ones.
+func recordTaskAudit(tx *gorm.DB, action string, before, after *models.Task) error {
+	task := after
+	if task == nil {
+		task = before
+	}
+	record := auditRecord{TenantID: task.TenantID, Action: action, TargetType: models.AggregateTask, TargetID: &task.ID}
+	if before != nil {
+		record.Before = before
+	}
+	if after != nil {
+		record.After = after
+	}
+	return recordAudit(tx, record)
+}
+
+// auditHash is the hex SHA-256 of the entry's canonical JSON form, which
+// includes PrevHash and leaves out Hash itself
+func auditHash(entry *models.AuditEntry) (string, error) {
+	canonical, err := json.Marshal(struct {
+		ID         uuid.UUID                     `json:"id"`
+		TenantID   uuid.UUID                     `json:"tenant_id"`
+		Seq        int64                         `json:"seq"`
+		Action     string                        `json:"action"`
+		ActorID    *uuid.UUID                    `json:"actor_id"`
+		TargetType string                        `json:"target_type"`
+		TargetID   *uuid.UUID                    `json:"target_id"`
+		Changes    map[string]models.FieldChange `json:"changes"`
+		Detail     string                        `json:"detail"`
+		IP         string                        `json:"ip"`
+		UserAgent  string                        `json:"user_agent"`
+		RequestID  string                        `json:"request_id"`
+		PrevHash   string                        `json:"prev_hash"`
+		CreatedAt  string                        `json:"created_at"`
+	}{
+		entry.ID, entry.TenantID, entry.Seq, entry.Action, entry.ActorID, entry.TargetType, entry.TargetID,
+		entry.Changes, entry.Detail, entry.IP, entry.UserAgent, entry.RequestID, entry.PrevHash,
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// auditChanges diffs the JSON forms of before and after field by field.
+// Either may be nil, in which case every field of the other is listed.
+func auditChanges(before, after interface{}) (map[string]models.FieldChange, error) {
+	if before == nil && after == nil {
+		return nil, nil
+	}
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]models.FieldChange{}
+	for name, value := range afterFields {
+		if !bytes.Equal(value, beforeFields[name]) {
+			changes[name] = models.FieldChange{Before: beforeFields[name], After: value}
+		}
+	}
+	for name, value := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = models.FieldChange{Before: value}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(value interface{}) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if value == nil {
+		return fields, nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for name, field := range fields {
+		if auditIgnoredFields[name] {
+			delete(fields, name)
+			continue
+		}
+		// Compact, so that equal values compare equal
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, field); err != nil {
+			return nil, err
+		}
+		fields[name] = compact.Bytes()
+	}
+	return fields, nil
+}
+
+// eachAuditEntry calls fn for every entry of query in chain order, a batch
+// at a time. Batches continue after the last sequence number seen, so they
+// stay consistent while entries are appended.
+func eachAuditEntry(query *gorm.DB, fn func(*models.AuditEntry) error) error {
+	lastTenant, lastSeq := uuid.Nil, int64(0)
+	for {
+		var batch []models.AuditEntry
+		err := query.Session(&gorm.Session{}).
+			Where("tenant_id > ? OR (tenant_id = ? AND seq > ?)", lastTenant, lastTenant, lastSeq).
+			Order("tenant_id, seq").
+			Limit(auditBatch).
+			Find(&batch).Error
+		if err != nil {
+			return err
+		}
+		for i := range batch {
+			if err := fn(&batch[i]); err != nil {
+				return err
+			}
+		}
+		if len(batch) < auditBatch {
+			return nil
+		}
+		lastTenant, lastSeq = batch[len(batch)-1].TenantID, batch[len(batch)-1].Seq
+	}
+}
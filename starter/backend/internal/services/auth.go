@@ -2,7 +2,10 @@ package services
 
 import (
 	"errors"
-	"log"
+	"log/slog"
+	"strconv"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/metrics"
 	"task-manager/backend/internal/models"
 	"task-manager/backend/internal/utils"
 	"time"
@@ -12,6 +15,12 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidCredentials  = apperr.New(apperr.KindUnauthorized, "invalid_credentials", "invalid username or password")
+	ErrInvalidRefreshToken = apperr.New(apperr.KindUnauthorized, "invalid_refresh_token", "invalid refresh token")
+	ErrRefreshTokenExpired = apperr.New(apperr.KindUnauthorized, "refresh_token_expired", "refresh token expired")
+)
+
 type AuthService interface {
 	LoginUser(db *gorm.DB, username, password string) (*models.User, error)
 	GenerateToken(db *gorm.DB, userID uuid.UUID, username string) (string, string, error)
@@ -30,20 +39,27 @@ func VerifyPassword(hashedPassword, plainPassword string) bool {
 	return err == nil
 }
 
+// LoginUser checks the credentials. Successful and failed attempts are both
+// added to the audit log and counted in the login metrics.
 func (s *AuthServiceImpl) LoginUser(db *gorm.DB, username, password string) (*models.User, error) {
 	var user models.User
 	if err := db.Preload("Roles.Permissions").Where("username = ?", username).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("invalid username or password")
+			s.audit(db, auditRecord{Action: models.AuditLoginFailed, TargetType: models.AggregateUser, Detail: "unknown username " + strconv.Quote(username)})
+			metrics.RecordLogin(false)
+			return nil, ErrInvalidCredentials
 		}
-		log.Printf("Database error during login: %v", err)
-		return nil, errors.New("internal server error")
+		return nil, apperr.Internal(err, "failed to log in")
 	}
 
 	if !VerifyPassword(user.Password, password) {
-		return nil, errors.New("invalid username or password")
+		s.audit(db, auditRecord{TenantID: user.TenantID, Action: models.AuditLoginFailed, TargetType: models.AggregateUser, TargetID: &user.ID, Detail: "wrong password"})
+		metrics.RecordLogin(false)
+		return nil, ErrInvalidCredentials
 	}
 
+	s.audit(db, auditRecord{TenantID: user.TenantID, Action: models.AuditLogin, ActorID: &user.ID, TargetType: models.AggregateUser, TargetID: &user.ID})
+	metrics.RecordLogin(true)
 	return &user, nil
 }
 
@@ -71,17 +87,15 @@ func (s *AuthServiceImpl) GenerateToken(db *gorm.DB, userID uuid.UUID, username
 	}
 
 	// Generate access token with roles and permissions
-	accessToken, err := utils.GenerateAccessToken(userID, username, roles, permissions)
+	accessToken, err := utils.GenerateAccessToken(userID, user.TenantID, username, roles, permissions)
 	if err != nil {
-		log.Printf("Error generating access token: %v", err)
-		return "", "", errors.New("failed to generate access token")
+		return "", "", apperr.Internal(err, "failed to generate access token")
 	}
 
 	// Generate refresh token
 	refreshToken, err := uuid.NewV4()
 	if err != nil {
-		log.Printf("Error generating refresh token: %v", err)
-		return "", "", errors.New("failed to generate refresh token")
+		return "", "", apperr.Internal(err, "failed to generate refresh token")
 	}
 
 	// Store refresh token in database
@@ -93,8 +107,7 @@ func (s *AuthServiceImpl) GenerateToken(db *gorm.DB, userID uuid.UUID, username
 	}
 
 	if err := db.Create(&token).Error; err != nil {
-		log.Printf("Error storing refresh token: %v", err)
-		return "", "", errors.New("failed to store refresh token")
+		return "", "", apperr.Internal(err, "failed to store refresh token")
 	}
 
 	return accessToken, refreshToken.String(), nil
@@ -104,30 +117,30 @@ func (s *AuthServiceImpl) RefreshToken(db *gorm.DB, refreshToken string) (string
 	// Parse the refresh token
 	tokenUUID, err := uuid.FromString(refreshToken)
 	if err != nil {
-		log.Printf("Invalid refresh token format: %v", err)
-		return "", "", errors.New("invalid refresh token")
+		return "", "", ErrInvalidRefreshToken
 	}
 
 	// Find the token in the database
 	var token models.Token
 	if err := db.Where("refresh_token = ?", tokenUUID).First(&token).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return "", "", errors.New("invalid refresh token")
+			return "", "", ErrInvalidRefreshToken
 		}
-		log.Printf("Database error during token refresh: %v", err)
-		return "", "", errors.New("internal server error")
+		return "", "", apperr.Internal(err, "failed to refresh token")
 	}
 
 	// Check if token is expired
 	if time.Now().After(token.ExpiresAt) {
-		return "", "", errors.New("refresh token expired")
+		return "", "", ErrRefreshTokenExpired
 	}
 
 	// Get the user
 	var user models.User
 	if err := db.First(&user, token.UserId).Error; err != nil {
-		log.Printf("Error finding user: %v", err)
-		return "", "", errors.New("user not found")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", ErrInvalidRefreshToken
+		}
+		return "", "", apperr.Internal(err, "failed to refresh token")
 	}
 
 	// Generate new tokens
@@ -138,9 +151,20 @@ func (s *AuthServiceImpl) RefreshToken(db *gorm.DB, refreshToken string) (string
 
 	// Delete the old token
 	if err := db.Delete(&token).Error; err != nil {
-		log.Printf("Error deleting old token: %v", err)
+		slog.ErrorContext(db.Statement.Context, "deleting old refresh token failed", "error", err)
 		// Continue anyway as we've already generated new tokens
 	}
 
+	s.audit(db, auditRecord{TenantID: user.TenantID, Action: models.AuditRefresh, ActorID: &user.ID, TargetType: models.AggregateUser, TargetID: &user.ID})
+	metrics.RecordRefreshRotation()
+
 	return accessToken, newRefreshToken, nil
 }
+
+// audit records an authentication attempt. Failing to record one is logged
+// rather than failing the attempt, since there is no change to roll back.
+func (s *AuthServiceImpl) audit(db *gorm.DB, record auditRecord) {
+	if err := recordAudit(db, record); err != nil {
+		slog.ErrorContext(db.Statement.Context, "recording audit entry failed", "action", record.Action, "error", err)
+	}
+}
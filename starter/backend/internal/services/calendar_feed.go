@@ -0,0 +1,97 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrCalendarFeedNotFound = apperr.New(apperr.KindNotFound, "calendar_feed_not_found", "calendar feed not found")
+
+type CalendarFeedService interface {
+	CreateFeed(db *gorm.DB, userID uuid.UUID) (string, *models.CalendarFeed, error)
+	GetFeed(db *gorm.DB, userID uuid.UUID) (*models.CalendarFeed, error)
+	RevokeFeed(db *gorm.DB, userID uuid.UUID) error
+	ResolveFeed(db *gorm.DB, token string) (*models.CalendarFeed, error)
+}
+
+type CalendarFeedServiceImpl struct{}
+
+func NewCalendarFeedService() *CalendarFeedServiceImpl {
+	return &CalendarFeedServiceImpl{}
+}
+
+// CreateFeed gives the user a new feed and returns its token, which is not
+// stored and cannot be shown again. A feed the user already had is revoked.
+func (s *CalendarFeedServiceImpl) CreateFeed(db *gorm.DB, userID uuid.UUID) (string, *models.CalendarFeed, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	token := hex.EncodeToString(secret)
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", nil, err
+	}
+
+	feed := models.CalendarFeed{ID: id, UserID: userID, TokenHash: hashFeedToken(token), CreatedAt: time.Now()}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.CalendarFeed{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&feed).Error
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &feed, nil
+}
+
+// GetFeed returns the user's feed
+func (s *CalendarFeedServiceImpl) GetFeed(db *gorm.DB, userID uuid.UUID) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := db.Where("user_id = ?", userID).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarFeedNotFound
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// RevokeFeed deletes the user's feed, so its URL stops working
+func (s *CalendarFeedServiceImpl) RevokeFeed(db *gorm.DB, userID uuid.UUID) error {
+	result := db.Where("user_id = ?", userID).Delete(&models.CalendarFeed{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// ResolveFeed finds the feed a token belongs to. Feed URLs carry no tenant, so
+// callers look the token up across tenants.
+func (s *CalendarFeedServiceImpl) ResolveFeed(db *gorm.DB, token string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := db.Where("token_hash = ?", hashFeedToken(token)).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarFeedNotFound
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
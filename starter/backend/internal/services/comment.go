@@ -0,0 +1,245 @@
+package services
+
+import (
+	"regexp"
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmptyComment     = apperr.Invalid("body", "required", "comment body cannot be empty")
+	ErrNotCommentAuthor = apperr.New(apperr.KindForbidden, "not_comment_author", "only the author can edit a comment")
+)
+
+// mentionPattern matches @username where the @ does not follow a word
+// character, so email addresses in a comment are not taken for mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]+)`)
+
+type CommentService interface {
+	CreateComment(db *gorm.DB, task *models.Task, comment *models.Comment) error
+	GetComments(db *gorm.DB, taskID uuid.UUID, page Page) ([]models.Comment, int64, error)
+	GetComment(db *gorm.DB, taskID, commentID uuid.UUID) (*models.Comment, error)
+	UpdateComment(db *gorm.DB, task *models.Task, comment *models.Comment, body string, editorID uuid.UUID) error
+	DeleteComment(db *gorm.DB, commentID uuid.UUID) error
+	GetRevisions(db *gorm.DB, commentID uuid.UUID) ([]models.CommentRevision, error)
+}
+
+type CommentServiceImpl struct {
+	taskService   TaskService
+	notifications NotificationPublisher
+}
+
+func NewCommentService() *CommentServiceImpl {
+	return &CommentServiceImpl{taskService: NewTaskService(), notifications: NewNotificationService()}
+}
+
+// CreateComment posts a comment on the task and records its mentions. Mentioned
+// users and the task's owner are notified.
+func (s *CommentServiceImpl) CreateComment(db *gorm.DB, task *models.Task, comment *models.Comment) error {
+	if strings.TrimSpace(comment.Body) == "" {
+		return ErrEmptyComment
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	comment.ID = id
+	comment.TaskID = task.ID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Mentions").Create(comment).Error; err != nil {
+			return err
+		}
+		mentions, err := s.saveMentions(tx, task, comment)
+		comment.Mentions = mentions
+		if err != nil {
+			return err
+		}
+		return s.announceComment(tx, task, comment)
+	})
+}
+
+// announceComment notifies everyone mentioned in a new comment, and the task's
+// owner of the comment itself unless they were mentioned too
+func (s *CommentServiceImpl) announceComment(tx *gorm.DB, task *models.Task, comment *models.Comment) error {
+	ownerMentioned := false
+	for _, mention := range comment.Mentions {
+		ownerMentioned = ownerMentioned || mention.UserID == task.UserID
+		err := s.notifications.Publish(tx, NotificationEvent{
+			Type:     models.NotificationTaskMentioned,
+			TenantID: task.TenantID,
+			UserID:   mention.UserID,
+			ActorID:  comment.AuthorID,
+			TaskID:   &task.ID,
+			Title:    "You were mentioned on " + task.Title,
+			Body:     comment.Body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if ownerMentioned {
+		return nil
+	}
+	return s.notifications.Publish(tx, NotificationEvent{
+		Type:     models.NotificationTaskCommented,
+		TenantID: task.TenantID,
+		UserID:   task.UserID,
+		ActorID:  comment.AuthorID,
+		TaskID:   &task.ID,
+		Title:    "New comment on " + task.Title,
+		Body:     comment.Body,
+	})
+}
+
+// GetComments returns one page of the task's comments, oldest first, and the total count
+func (s *CommentServiceImpl) GetComments(db *gorm.DB, taskID uuid.UUID, page Page) ([]models.Comment, int64, error) {
+	var total int64
+	if err := db.Model(&models.Comment{}).Where("task_id = ?", taskID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	comments := []models.Comment{}
+	err := db.Preload("Mentions").
+		Where("task_id = ?", taskID).
+		Order("created_at, id").
+		Offset(page.Offset()).
+		Limit(page.Size).
+		Find(&comments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+func (s *CommentServiceImpl) GetComment(db *gorm.DB, taskID, commentID uuid.UUID) (*models.Comment, error) {
+	var comment models.Comment
+	if err := db.Preload("Mentions").First(&comment, "id = ? AND task_id = ?", commentID, taskID).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// UpdateComment replaces the body of a comment, keeping the previous body as a
+// revision. Mentions are recomputed from the new body.
+func (s *CommentServiceImpl) UpdateComment(db *gorm.DB, task *models.Task, comment *models.Comment, body string, editorID uuid.UUID) error {
+	if comment.AuthorID != editorID {
+		return ErrNotCommentAuthor
+	}
+	if strings.TrimSpace(body) == "" {
+		return ErrEmptyComment
+	}
+	if body == comment.Body {
+		return nil
+	}
+
+	revisionID, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		revision := models.CommentRevision{ID: revisionID, CommentID: comment.ID, Body: comment.Body, EditedBy: editorID}
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		err := tx.Model(&models.Comment{}).Where("id = ?", comment.ID).Updates(map[string]interface{}{
+			"body":      body,
+			"edited_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		comment.Body = body
+		comment.EditedAt = &now
+
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentMention{}).Error; err != nil {
+			return err
+		}
+		mentions, err := s.saveMentions(tx, task, comment)
+		comment.Mentions = mentions
+		return err
+	})
+}
+
+// DeleteComment soft deletes the comment. Its revisions are kept.
+func (s *CommentServiceImpl) DeleteComment(db *gorm.DB, commentID uuid.UUID) error {
+	result := db.Delete(&models.Comment{}, "id = ?", commentID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetRevisions returns the earlier bodies of a comment, oldest first
+func (s *CommentServiceImpl) GetRevisions(db *gorm.DB, commentID uuid.UUID) ([]models.CommentRevision, error) {
+	revisions := []models.CommentRevision{}
+	if err := db.Where("comment_id = ?", commentID).Order("created_at").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// saveMentions records the users @mentioned in the comment body. Users who
+// cannot see the task are not recorded, so a mention never reveals the task.
+func (s *CommentServiceImpl) saveMentions(db *gorm.DB, task *models.Task, comment *models.Comment) ([]models.CommentMention, error) {
+	mentions := []models.CommentMention{}
+	usernames := MentionedUsernames(comment.Body)
+	if len(usernames) == 0 {
+		return mentions, nil
+	}
+
+	var users []models.User
+	if err := db.Where("username IN ?", usernames).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		actor, err := ActorForUser(db, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		level, err := s.taskService.GetTaskAccess(db, task, actor)
+		if err != nil {
+			return nil, err
+		}
+		if level == models.TaskAccessNone {
+			continue
+		}
+		mentions = append(mentions, models.CommentMention{CommentID: comment.ID, UserID: user.ID})
+	}
+
+	if len(mentions) == 0 {
+		return mentions, nil
+	}
+	if err := db.Create(&mentions).Error; err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
+
+// MentionedUsernames returns the distinct usernames @mentioned in a Markdown body
+func MentionedUsernames(body string) []string {
+	seen := map[string]bool{}
+	var usernames []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// Punctuation closing a sentence is not part of the username
+		username := strings.TrimRight(match[1], ".-")
+		if username != "" && !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+	return usernames
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIdempotencyKeyReused     = apperr.New(apperr.KindUnprocessable, "idempotency_key_reused", "idempotency key was already used for a different request")
+	ErrIdempotencyKeyInProgress = apperr.New(apperr.KindConflict, "idempotency_key_in_progress", "a request with this idempotency key is still in progress")
+)
+
+type IdempotencyService interface {
+	Begin(db *gorm.DB, userID uuid.UUID, key, requestHash, method, path string) (*models.IdempotencyKey, error)
+	Complete(db *gorm.DB, record *models.IdempotencyKey, statusCode int, contentType string, body []byte) error
+	Release(db *gorm.DB, record *models.IdempotencyKey) error
+	DeleteExpired(db *gorm.DB, now time.Time) (int64, error)
+	Run(ctx context.Context, db *gorm.DB, interval time.Duration)
+}
+
+// IdempotencyServiceImpl remembers the responses of requests sent with an
+// idempotency key for ttl, so retries of them can be answered without running
+// them twice
+type IdempotencyServiceImpl struct {
+	ttl time.Duration
+	// lockTimeout is how long a key stays claimed by a request that has not
+	// finished. Past it the request is taken to have died with its replica,
+	// and a retry may claim the key again, so it must be longer than the
+	// slowest request: a retry of one still running past it runs twice.
+	lockTimeout time.Duration
+}
+
+func NewIdempotencyService(ttl time.Duration) *IdempotencyServiceImpl {
+	return &IdempotencyServiceImpl{ttl: ttl, lockTimeout: time.Minute}
+}
+
+// WithLockTimeout changes how long an unfinished request holds its key
+func (s *IdempotencyServiceImpl) WithLockTimeout(timeout time.Duration) *IdempotencyServiceImpl {
+	s.lockTimeout = timeout
+	return s
+}
+
+// Begin claims the user's key for a request. When the key is new, its record
+// has expired or its request has held it past the lock timeout, an
+// in-progress record is created and returned for Complete or Release. When
+// the same request already finished under the key, its completed record is
+// returned to be replayed. A key used for a request with another hash gives
+// ErrIdempotencyKeyReused, and one whose request has not finished yet gives
+// ErrIdempotencyKeyInProgress.
+func (s *IdempotencyServiceImpl) Begin(db *gorm.DB, userID uuid.UUID, key, requestHash, method, path string) (*models.IdempotencyKey, error) {
+	now := time.Now()
+	existing, err := s.find(db, userID, key)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && (!existing.ExpiresAt.After(now) || s.stale(existing, now)) {
+		if err := db.Where("id = ?", existing.ID).Delete(&models.IdempotencyKey{}).Error; err != nil {
+			return nil, err
+		}
+		existing = nil
+	}
+	if existing != nil {
+		return claimed(existing, requestHash)
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	record := models.IdempotencyKey{
+		ID:          id,
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		Method:      method,
+		Path:        path,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+	if err := db.Create(&record).Error; err != nil {
+		// A concurrent request claimed the key first
+		existing, findErr := s.find(db, userID, key)
+		if findErr != nil || existing == nil {
+			return nil, err
+		}
+		return claimed(existing, requestHash)
+	}
+	return &record, nil
+}
+
+func (s *IdempotencyServiceImpl) find(db *gorm.DB, userID uuid.UUID, key string) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	if err := db.Where("user_id = ? AND key = ?", userID, key).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// stale reports whether the record belongs to a request that never finished
+func (s *IdempotencyServiceImpl) stale(record *models.IdempotencyKey, now time.Time) bool {
+	return !record.Completed() && !record.CreatedAt.After(now.Add(-s.lockTimeout))
+}
+
+// claimed says what a request may do with a key another request holds
+func claimed(existing *models.IdempotencyKey, requestHash string) (*models.IdempotencyKey, error) {
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if !existing.Completed() {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return existing, nil
+}
+
+// Complete stores the response of the request that claimed the key
+func (s *IdempotencyServiceImpl) Complete(db *gorm.DB, record *models.IdempotencyKey, statusCode int, contentType string, body []byte) error {
+	record.StatusCode, record.ContentType, record.ResponseBody = statusCode, contentType, body
+	return db.Model(&models.IdempotencyKey{}).Where("id = ?", record.ID).Updates(map[string]interface{}{
+		"status_code":   statusCode,
+		"content_type":  contentType,
+		"response_body": body,
+	}).Error
+}
+
+// Release gives up the key without storing a response, so the request can be
+// retried under it
+func (s *IdempotencyServiceImpl) Release(db *gorm.DB, record *models.IdempotencyKey) error {
+	return db.Where("id = ?", record.ID).Delete(&models.IdempotencyKey{}).Error
+}
+
+// DeleteExpired removes the records whose keys have expired
+func (s *IdempotencyServiceImpl) DeleteExpired(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
+
+// Run deletes expired idempotency keys every interval until ctx is cancelled.
+// Begin ignores expired keys on its own; this only reclaims their space.
+func (s *IdempotencyServiceImpl) Run(ctx context.Context, db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.DeleteExpired(db, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "deleting expired idempotency keys failed", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "deleted expired idempotency keys", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
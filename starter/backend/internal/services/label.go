@@ -0,0 +1,291 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidLabelName  = apperr.Invalid("name", "invalid_label_name", "label name must be 1 to 50 characters without commas")
+	ErrInvalidLabelColor = apperr.Invalid("color", "invalid_label_color", "color must be a hex color such as #1f883d")
+	ErrLabelExists       = apperr.New(apperr.KindConflict, "label_exists", "a label with this name already exists")
+)
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// LabelUsage is a label together with the number of tasks carrying it
+type LabelUsage struct {
+	models.Label
+	TaskCount int64 `json:"task_count"`
+}
+
+type LabelService interface {
+	CreateLabel(db *gorm.DB, label *models.Label) error
+	GetLabels(db *gorm.DB, actor Actor) ([]models.Label, error)
+	GetLabelByID(db *gorm.DB, labelID uuid.UUID) (*models.Label, error)
+	UpdateLabel(db *gorm.DB, labelID uuid.UUID, label *models.Label) error
+	DeleteLabel(db *gorm.DB, labelID uuid.UUID) error
+	GetLabelAccess(db *gorm.DB, label *models.Label, actor Actor) (string, error)
+	GetTaskLabels(db *gorm.DB, taskID uuid.UUID) ([]models.Label, error)
+	ApplyLabels(db *gorm.DB, taskIDs, labelIDs []uuid.UUID, createdBy uuid.UUID) error
+	RemoveLabels(db *gorm.DB, taskIDs, labelIDs []uuid.UUID) error
+	GetLabelUsage(db *gorm.DB) ([]LabelUsage, error)
+}
+
+type LabelServiceImpl struct{}
+
+func NewLabelService() *LabelServiceImpl {
+	return &LabelServiceImpl{}
+}
+
+// CreateLabel stores a personal label for label.UserID, or a team label when
+// label.TeamID is set. The color defaults to grey.
+func (s *LabelServiceImpl) CreateLabel(db *gorm.DB, label *models.Label) error {
+	if err := normalizeLabel(label); err != nil {
+		return err
+	}
+	if label.TeamID != nil {
+		label.UserID = nil
+	}
+	if err := checkLabelName(db, label, uuid.Nil); err != nil {
+		return err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	label.ID = id
+	return db.Create(label).Error
+}
+
+// GetLabels returns the labels the actor can apply: their own and those of
+// their teams. Admins get every label.
+func (s *LabelServiceImpl) GetLabels(db *gorm.DB, actor Actor) ([]models.Label, error) {
+	var labels []models.Label
+	query := db.Order("name")
+	if !actor.IsAdmin() {
+		query = query.Where("user_id = ? OR team_id IN (?)", actor.UserID, memberTeamIDs(db, actor.UserID))
+	}
+	if err := query.Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (s *LabelServiceImpl) GetLabelByID(db *gorm.DB, labelID uuid.UUID) (*models.Label, error) {
+	var label models.Label
+	if err := db.First(&label, "id = ?", labelID).Error; err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// UpdateLabel renames or recolors a label. The owner cannot be changed.
+func (s *LabelServiceImpl) UpdateLabel(db *gorm.DB, labelID uuid.UUID, label *models.Label) error {
+	existing, err := s.GetLabelByID(db, labelID)
+	if err != nil {
+		return err
+	}
+	existing.Name, existing.Color = label.Name, label.Color
+	if err := normalizeLabel(existing); err != nil {
+		return err
+	}
+	if err := checkLabelName(db, existing, labelID); err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Label{}).Where("id = ?", labelID).
+			Updates(map[string]interface{}{"name": existing.Name, "color": existing.Color}).Error; err != nil {
+			return err
+		}
+		// The tasks carrying the label show its new name and color
+		return tx.Model(&models.Task{}).Where("id IN (?)", labelledTasks(tx, labelID)).UpdateColumn("version", nextVersion).Error
+	})
+	if err != nil {
+		return err
+	}
+	*label = *existing
+	return nil
+}
+
+// DeleteLabel removes a label and takes it off every task
+func (s *LabelServiceImpl) DeleteLabel(db *gorm.DB, labelID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Label{}, "id = ?", labelID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Model(&models.Task{}).Where("id IN (?)", labelledTasks(tx, labelID)).UpdateColumn("version", nextVersion).Error; err != nil {
+			return err
+		}
+		return tx.Where("label_id = ?", labelID).Delete(&models.TaskLabel{}).Error
+	})
+}
+
+// labelledTasks selects the IDs of the tasks carrying the label
+func labelledTasks(db *gorm.DB, labelID uuid.UUID) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Model(&models.TaskLabel{}).Select("task_id").Where("label_id = ?", labelID)
+}
+
+// GetLabelAccess reports what the actor may do with a label. Owner access
+// allows renaming and deleting it and belongs to the user of a personal label,
+// to team admins on a team label and to admins. Viewer access allows applying
+// it to tasks and belongs to the other team members.
+func (s *LabelServiceImpl) GetLabelAccess(db *gorm.DB, label *models.Label, actor Actor) (string, error) {
+	if actor.IsAdmin() {
+		return models.TaskAccessOwner, nil
+	}
+	if label.TeamID == nil {
+		if label.UserID != nil && *label.UserID == actor.UserID {
+			return models.TaskAccessOwner, nil
+		}
+		return models.TaskAccessNone, nil
+	}
+
+	var member models.TeamMember
+	err := db.Where("team_id = ? AND user_id = ?", *label.TeamID, actor.UserID).First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.TaskAccessNone, nil
+		}
+		return models.TaskAccessNone, err
+	}
+	if member.Role == models.TeamRoleAdmin {
+		return models.TaskAccessOwner, nil
+	}
+	return models.TaskAccessViewer, nil
+}
+
+func (s *LabelServiceImpl) GetTaskLabels(db *gorm.DB, taskID uuid.UUID) ([]models.Label, error) {
+	var labels []models.Label
+	err := db.Where("id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.TaskLabel{}).Select("label_id").Where("task_id = ?", taskID)).
+		Order("name").Find(&labels).Error
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// ApplyLabels puts every label on every task. Labels a task already carries
+// are left alone, so applying twice is harmless.
+func (s *LabelServiceImpl) ApplyLabels(db *gorm.DB, taskIDs, labelIDs []uuid.UUID, createdBy uuid.UUID) error {
+	links := make([]models.TaskLabel, 0, len(taskIDs)*len(labelIDs))
+	for _, taskID := range taskIDs {
+		for _, labelID := range labelIDs {
+			links = append(links, models.TaskLabel{TaskID: taskID, LabelID: labelID, CreatedBy: createdBy})
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&links, 500).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Task{}).Where("id IN ?", taskIDs).UpdateColumn("version", nextVersion).Error
+	})
+}
+
+// RemoveLabels takes every label off every task
+func (s *LabelServiceImpl) RemoveLabels(db *gorm.DB, taskIDs, labelIDs []uuid.UUID) error {
+	if len(taskIDs) == 0 || len(labelIDs) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("task_id IN ? AND label_id IN ?", taskIDs, labelIDs).Delete(&models.TaskLabel{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&models.Task{}).Where("id IN ?", taskIDs).UpdateColumn("version", nextVersion).Error
+	})
+}
+
+// GetLabelUsage counts the tasks carrying each label, most used first. Deleted
+// tasks are not counted.
+func (s *LabelServiceImpl) GetLabelUsage(db *gorm.DB) ([]LabelUsage, error) {
+	var labels []models.Label
+	if err := db.Order("name").Find(&labels).Error; err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		LabelID   uuid.UUID
+		TaskCount int64
+	}
+	err := db.Model(&models.TaskLabel{}).
+		Select("label_id, COUNT(*) AS task_count").
+		Where("task_id IN (?)", db.Session(&gorm.Session{NewDB: true}).Model(&models.Task{}).Select("id")).
+		Group("label_id").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	byLabel := make(map[uuid.UUID]int64, len(counts))
+	for _, count := range counts {
+		byLabel[count.LabelID] = count.TaskCount
+	}
+
+	usage := make([]LabelUsage, len(labels))
+	for i, label := range labels {
+		usage[i] = LabelUsage{Label: label, TaskCount: byLabel[label.ID]}
+	}
+	sort.SliceStable(usage, func(i, j int) bool { return usage[i].TaskCount > usage[j].TaskCount })
+	return usage, nil
+}
+
+// withLabels loads the labels of the tasks a query returns
+func withLabels(query *gorm.DB) *gorm.DB {
+	return query.Preload("Labels", func(labels *gorm.DB) *gorm.DB {
+		return labels.Order("labels.name")
+	})
+}
+
+// normalizeLabel trims the name and validates the name and color
+func normalizeLabel(label *models.Label) error {
+	label.Name = strings.TrimSpace(label.Name)
+	if label.Name == "" || len(label.Name) > 50 || strings.Contains(label.Name, ",") {
+		return ErrInvalidLabelName
+	}
+	if label.Color == "" {
+		label.Color = models.DefaultLabelColor
+	}
+	if !labelColorPattern.MatchString(label.Color) {
+		return ErrInvalidLabelColor
+	}
+	label.Color = strings.ToLower(label.Color)
+	return nil
+}
+
+// checkLabelName makes sure no other label of the same owner has the same
+// name, ignoring case
+func checkLabelName(db *gorm.DB, label *models.Label, exceptID uuid.UUID) error {
+	query := db.Model(&models.Label{}).Where("LOWER(name) = LOWER(?) AND id <> ?", label.Name, exceptID)
+	if label.TeamID != nil {
+		query = query.Where("team_id = ?", *label.TeamID)
+	} else {
+		query = query.Where("team_id IS NULL AND user_id = ?", label.UserID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrLabelExists
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AcquireLease takes the named lease for holder, or renews it when holder
+// already has it, until now+ttl. It returns false while another holder's
+// lease has not expired. Replicas compare expiry times against their own
+// clocks, so ttl should comfortably exceed any clock skew between them.
+func AcquireLease(db *gorm.DB, name, holder string, ttl time.Duration, now time.Time) (bool, error) {
+	lease := models.Lease{Name: name, Holder: holder, ExpiresAt: now.Add(ttl)}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&lease)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	result = db.Model(&models.Lease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", name, holder, now).
+		Updates(map[string]interface{}{"holder": holder, "expires_at": lease.ExpiresAt})
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReleaseLease gives up the named lease if holder has it, so that another
+// replica can take over without waiting for it to expire
+func ReleaseLease(db *gorm.DB, name, holder string) error {
+	return db.Where("name = ? AND holder = ?", name, holder).Delete(&models.Lease{}).Error
+}
+
+// leaseHolder names this process as a lease holder, unique even among
+// processes on one host
+func leaseHolder() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s-%s", host, uuid.Must(uuid.NewV4()))
+}
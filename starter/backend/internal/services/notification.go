@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/notify"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// maxNotificationBody caps how much of a comment is copied into a notification
+const maxNotificationBody = 280
+
+// NotificationEvent is something that happened which a user should hear about.
+// ActorID is the user who caused it, or uuid.Nil for the system; users are
+// never notified of their own actions.
+type NotificationEvent struct {
+	Type     string
+	TenantID uuid.UUID
+	UserID   uuid.UUID
+	ActorID  uuid.UUID
+	TaskID   *uuid.UUID
+	Title    string
+	Body     string
+}
+
+// NotificationPublisher is how services announce events to users. Publishing
+// with the transaction that made the change keeps the two together.
+type NotificationPublisher interface {
+	Publish(db *gorm.DB, event NotificationEvent) error
+}
+
+type NotificationService interface {
+	NotificationPublisher
+	GetNotifications(db *gorm.DB, userID uuid.UUID, unreadOnly bool, page Page) ([]models.Notification, int64, error)
+	CountUnread(db *gorm.DB, userID uuid.UUID) (int64, error)
+	MarkRead(db *gorm.DB, userID, notificationID uuid.UUID) error
+	MarkAllRead(db *gorm.DB, userID uuid.UUID) (int64, error)
+	DeleteExpired(db *gorm.DB, before time.Time) (int64, error)
+	RunRetention(ctx context.Context, db *gorm.DB, retention, interval time.Duration)
+}
+
+type NotificationServiceImpl struct{}
+
+func NewNotificationService() *NotificationServiceImpl {
+	return &NotificationServiceImpl{}
+}
+
+// Publish puts the event in the recipient's inbox
+func (s *NotificationServiceImpl) Publish(db *gorm.DB, event NotificationEvent) error {
+	if event.UserID == uuid.Nil || event.UserID == event.ActorID {
+		return nil
+	}
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	notification := models.Notification{
+		ID:       id,
+		TenantID: event.TenantID,
+		UserID:   event.UserID,
+		Type:     event.Type,
+		TaskID:   event.TaskID,
+		Title:    event.Title,
+		Body:     truncate(event.Body, maxNotificationBody),
+	}
+	if event.ActorID != uuid.Nil {
+		actorID := event.ActorID
+		notification.ActorID = &actorID
+	}
+	return db.Create(&notification).Error
+}
+
+// GetNotifications returns one page of the user's notifications, newest first,
+// and the total count
+func (s *NotificationServiceImpl) GetNotifications(db *gorm.DB, userID uuid.UUID, unreadOnly bool, page Page) ([]models.Notification, int64, error) {
+	query := db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	notifications := []models.Notification{}
+	err := query.Order("created_at DESC, id").Offset(page.Offset()).Limit(page.Size).Find(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (s *NotificationServiceImpl) CountUnread(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	var unread int64
+	err := db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unread).Error
+	return unread, err
+}
+
+// MarkRead marks one of the user's notifications read. Notifications of other
+// users are reported as gorm.ErrRecordNotFound.
+func (s *NotificationServiceImpl) MarkRead(db *gorm.DB, userID, notificationID uuid.UUID) error {
+	var notification models.Notification
+	if err := db.First(&notification, "id = ? AND user_id = ?", notificationID, userID).Error; err != nil {
+		return err
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	return db.Model(&notification).Where("id = ?", notificationID).Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead marks every unread notification of the user read and returns how many changed
+func (s *NotificationServiceImpl) MarkAllRead(db *gorm.DB, userID uuid.UUID) (int64, error) {
+	result := db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpired removes notifications created before the cutoff, read or not
+func (s *NotificationServiceImpl) DeleteExpired(db *gorm.DB, before time.Time) (int64, error) {
+	result := db.Where("created_at < ?", before).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// RunRetention deletes notifications older than retention every interval until
+// ctx is cancelled. Deleting is idempotent, so every replica may run it.
+func (s *NotificationServiceImpl) RunRetention(ctx context.Context, db *gorm.DB, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.DeleteExpired(db, time.Now().Add(-retention)); err != nil {
+			slog.ErrorContext(ctx, "deleting expired notifications failed", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "deleted expired notifications", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// InAppNotifier delivers messages from notify channels, such as reminders, to
+// the user's in-app inbox
+type InAppNotifier struct {
+	db        *gorm.DB
+	publisher NotificationPublisher
+}
+
+func NewInAppNotifier(db *gorm.DB, publisher NotificationPublisher) *InAppNotifier {
+	return &InAppNotifier{db: db, publisher: publisher}
+}
+
+func (n *InAppNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	return n.publisher.Publish(n.db.WithContext(ctx), NotificationEvent{
+		Type:     msg.Event,
+		TenantID: msg.TenantID,
+		UserID:   msg.UserID,
+		TaskID:   msg.TaskID,
+		Title:    msg.Subject,
+		Body:     msg.Text,
+	})
+}
+
+// truncate shortens s to at most max runes, marking the cut with an ellipsis
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/outbox"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// A failed event is retried after outboxBackoff, doubling up to
+	// maxOutboxBackoff. Events are never given up: a sink that is down only
+	// delays them.
+	outboxBackoff    = 5 * time.Second
+	maxOutboxBackoff = 10 * time.Minute
+	// outboxBatch caps how many events one pass of the relay publishes
+	outboxBatch = 100
+
+	outboxLease = "outbox"
+
+	roleScopeTeam    = "team"
+	roleScopeProject = "project"
+)
+
+// roleChange is the data of a role.changed event: the user's role in the team
+// or project ScopeID names. Role is empty when the user was removed.
+type roleChange struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Scope   string    `json:"scope"`
+	ScopeID uuid.UUID `json:"scope_id"`
+	Role    string    `json:"role"`
+}
+
+// recordEvent writes a domain event to the outbox. It must be called with the
+// transaction that makes the change, so the event exists if and only if the
+// change commits.
+func recordEvent(tx *gorm.DB, eventType string, tenantID uuid.UUID, aggregateType string, aggregateID uuid.UUID, data interface{}) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.OutboxEvent{
+		ID:            id,
+		TenantID:      tenantID,
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(payload),
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// recordTaskEvent records a change to task in the outbox, with the task as it
+// now is, queues it for webhooks and adds it to the audit log and, unless it
+// was deleted, to the task's history. Updates pass the task as it was before
+// in before.
+func recordTaskEvent(tx *gorm.DB, webhooks WebhookPublisher, eventType string, before, task *models.Task) error {
+	// Deleted tasks are still loaded, since deletions are events too
+	var current models.Task
+	if err := tx.Unscoped().Scopes(withLabels).First(&current, "id = ?", task.ID).Error; err != nil {
+		return err
+	}
+	if err := recordEvent(tx, eventType, current.TenantID, models.AggregateTask, current.ID, &current); err != nil {
+		return err
+	}
+	if err := webhooks.Enqueue(tx, taskWebhookEvent(eventType, &current)); err != nil {
+		return err
+	}
+	switch eventType {
+	case models.EventTaskCreated:
+		before = nil
+	case models.EventTaskDeleted:
+		return recordTaskAudit(tx, eventType, &current, nil)
+	}
+	if err := recordTaskAudit(tx, eventType, before, &current); err != nil {
+		return err
+	}
+	return recordTaskRevision(tx, eventType, before, &current)
+}
+
+// recordUserEvent records a change to the user in the outbox and queues it
+// for webhooks
+func recordUserEvent(tx *gorm.DB, webhooks WebhookPublisher, eventType string, userID uuid.UUID) error {
+	var user models.User
+	if err := tx.Unscoped().First(&user, "id = ?", userID).Error; err != nil {
+		return err
+	}
+	data := webhookUser{ID: user.ID, Username: user.Username, Email: user.Email}
+	if err := recordEvent(tx, eventType, user.TenantID, models.AggregateUser, user.ID, data); err != nil {
+		return err
+	}
+	return webhooks.Enqueue(tx, WebhookEvent{Type: eventType, TenantID: user.TenantID, UserID: &user.ID})
+}
+
+// recordRoleChange records that the user's role in a team or project changed
+// from previous, both in the outbox and in the audit log
+func recordRoleChange(tx *gorm.DB, tenantID uuid.UUID, change roleChange, previous string) error {
+	if err := recordEvent(tx, models.EventRoleChanged, tenantID, models.AggregateUser, change.UserID, change); err != nil {
+		return err
+	}
+	return recordAudit(tx, auditRecord{
+		TenantID:   tenantID,
+		Action:     models.AuditRoleChanged,
+		TargetType: models.AggregateUser,
+		TargetID:   &change.UserID,
+		Before:     map[string]string{"role": previous},
+		After:      map[string]string{"role": change.Role},
+		Detail:     change.Scope + " " + change.ScopeID.String(),
+	})
+}
+
+type OutboxService interface {
+	RelayPending(db *gorm.DB, now time.Time) (int, error)
+	DeletePublished(db *gorm.DB, before time.Time) (int64, error)
+	Run(ctx context.Context, db *gorm.DB, interval, retention time.Duration)
+}
+
+// OutboxServiceImpl relays outbox events to its sinks
+type OutboxServiceImpl struct {
+	sinks  []outbox.Sink
+	holder string
+}
+
+func NewOutboxService(sinks ...outbox.Sink) *OutboxServiceImpl {
+	return &OutboxServiceImpl{sinks: sinks, holder: leaseHolder()}
+}
+
+// RelayPending publishes the unpublished events that are due at now, oldest
+// first, and returns how many every sink has taken. A sink that fails only
+// gets the event again on the retry; the sinks that took it are not repeated.
+// A crash between publishing and recording can still repeat an event, which
+// is why consumers deduplicate by event ID.
+func (s *OutboxServiceImpl) RelayPending(db *gorm.DB, now time.Time) (int, error) {
+	var pending []models.OutboxEvent
+	err := db.Where("published_at IS NULL AND next_attempt_at <= ?", now).
+		Order("created_at, id").
+		Limit(outboxBatch).
+		Find(&pending).Error
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for i := range pending {
+		ok, err := s.relay(db, &pending[i], now)
+		if err != nil {
+			return published, err
+		}
+		if ok {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// DeletePublished deletes events published before the given time, together
+// with their delivery records
+func (s *OutboxServiceImpl) DeletePublished(db *gorm.DB, before time.Time) (int64, error) {
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		published := tx.Model(&models.OutboxEvent{}).Select("id").Where("published_at < ?", before)
+		if err := tx.Where("event_id IN (?)", published).Delete(&models.OutboxDelivery{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("published_at < ?", before).Delete(&models.OutboxEvent{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// Run relays pending events every interval until ctx is cancelled, and deletes
+// published events once they are older than retention. Only the replica
+// holding the outbox lease relays, which keeps replicas from publishing the
+// same event at the same time.
+func (s *OutboxServiceImpl) Run(ctx context.Context, db *gorm.DB, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer ReleaseLease(db, outboxLease, s.holder)
+	for {
+		held, err := AcquireLease(db, outboxLease, s.holder, 3*interval, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "acquiring the outbox lease failed", "error", err)
+		} else if held {
+			if n, err := s.RelayPending(db, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "relaying outbox events failed", "error", err)
+			} else if n > 0 {
+				slog.InfoContext(ctx, "published outbox events", "count", n)
+			}
+			if _, err := s.DeletePublished(db, time.Now().Add(-retention)); err != nil {
+				slog.ErrorContext(ctx, "deleting published outbox events failed", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay hands the event to every sink that has not taken it yet and records
+// the outcome. It returns true once all sinks have it.
+func (s *OutboxServiceImpl) relay(db *gorm.DB, event *models.OutboxEvent, now time.Time) (bool, error) {
+	var delivered []string
+	if err := db.Model(&models.OutboxDelivery{}).Where("event_id = ?", event.ID).Pluck("consumer_id", &delivered).Error; err != nil {
+		return false, err
+	}
+	taken := make(map[string]bool, len(delivered))
+	for _, id := range delivered {
+		taken[id] = true
+	}
+
+	message := outbox.Event{
+		ID:            event.ID,
+		Type:          event.Type,
+		TenantID:      event.TenantID,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		OccurredAt:    event.CreatedAt,
+		Data:          json.RawMessage(event.Payload),
+	}
+	var failures []error
+	for _, sink := range s.sinks {
+		if taken[sink.ID()] {
+			continue
+		}
+		if err := sink.Publish(db.Statement.Context, message); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", sink.ID(), err))
+			continue
+		}
+		delivery := models.OutboxDelivery{EventID: event.ID, ConsumerID: sink.ID(), DeliveredAt: now}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&delivery).Error; err != nil {
+			return false, err
+		}
+	}
+
+	if len(failures) > 0 {
+		return false, db.Model(event).Updates(map[string]interface{}{
+			"attempts":        event.Attempts + 1,
+			"last_error":      errors.Join(failures...).Error(),
+			"next_attempt_at": now.Add(retryDelay(event.Attempts+1, outboxBackoff, maxOutboxBackoff)),
+		}).Error
+	}
+	return true, db.Model(event).Updates(map[string]interface{}{
+		"attempts":     event.Attempts + 1,
+		"last_error":   "",
+		"published_at": now,
+	}).Error
+}
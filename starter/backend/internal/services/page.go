@@ -0,0 +1,11 @@
+package services
+
+// Page selects one page of a listing. Number starts at 1.
+type Page struct {
+	Number int
+	Size   int
+}
+
+func (p Page) Offset() int {
+	return (p.Number - 1) * p.Size
+}
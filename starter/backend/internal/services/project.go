@@ -0,0 +1,279 @@
+package services
+
+import (
+	"errors"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProjectArchived      = apperr.New(apperr.KindConflict, "project_archived", "project is archived")
+	ErrInboxProject         = apperr.New(apperr.KindConflict, "inbox_project", "the inbox project cannot be deleted or archived")
+	ErrLastProjectOwner     = apperr.New(apperr.KindConflict, "last_project_owner", "a project must keep at least one owner")
+	ErrNotProjectMember     = apperr.New(apperr.KindNotFound, "not_project_member", "user is not a member of this project")
+	ErrInvalidProjectAccess = apperr.Invalid("permission", "invalid_permission", "permission must be viewer, editor or owner")
+)
+
+type ProjectService interface {
+	CreateProject(db *gorm.DB, project *models.Project, ownerID uuid.UUID) error
+	CreateInbox(db *gorm.DB, userID uuid.UUID) (*models.Project, error)
+	GetProjectByID(db *gorm.DB, projectID uuid.UUID) (*models.Project, error)
+	GetProjects(db *gorm.DB, actor Actor, includeArchived bool) ([]models.Project, error)
+	UpdateProject(db *gorm.DB, projectID uuid.UUID, project *models.Project) error
+	DeleteProject(db *gorm.DB, projectID uuid.UUID) error
+	GetMembers(db *gorm.DB, projectID uuid.UUID) ([]models.ProjectMember, error)
+	SetMember(db *gorm.DB, projectID, userID uuid.UUID, permission string) (*models.ProjectMember, error)
+	RemoveMember(db *gorm.DB, projectID, userID uuid.UUID) error
+	GetProjectAccess(db *gorm.DB, projectID uuid.UUID, actor Actor) (string, error)
+	GetProjectTasks(db *gorm.DB, projectID uuid.UUID, filter TaskFilter) ([]models.Task, error)
+}
+
+type ProjectServiceImpl struct {
+	notifications NotificationPublisher
+}
+
+func NewProjectService() *ProjectServiceImpl {
+	return &ProjectServiceImpl{notifications: NewNotificationService()}
+}
+
+// CreateProject stores a new project with its creator as the first owner
+func (s *ProjectServiceImpl) CreateProject(db *gorm.DB, project *models.Project, ownerID uuid.UUID) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	project.ID = id
+	project.OwnerID = ownerID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Create(project).Error; err != nil {
+			return err
+		}
+		member := models.ProjectMember{ProjectID: project.ID, UserID: ownerID, Permission: models.TaskAccessOwner}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		project.Members = []models.ProjectMember{member}
+		return nil
+	})
+}
+
+// CreateInbox creates the default project personal tasks are filed into
+func (s *ProjectServiceImpl) CreateInbox(db *gorm.DB, userID uuid.UUID) (*models.Project, error) {
+	project := models.Project{Name: models.InboxProjectName, Inbox: true}
+	if err := s.CreateProject(db, &project, userID); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (s *ProjectServiceImpl) GetProjectByID(db *gorm.DB, projectID uuid.UUID) (*models.Project, error) {
+	var project models.Project
+	if err := db.Preload("Members").First(&project, "id = ?", projectID).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// GetProjects returns every project for admins and the actor's own projects for everyone else
+func (s *ProjectServiceImpl) GetProjects(db *gorm.DB, actor Actor, includeArchived bool) ([]models.Project, error) {
+	var projects []models.Project
+	query := db.Order("inbox DESC, name")
+	if !actor.IsAdmin() {
+		query = query.Where("id IN (?)", memberProjectIDs(db, actor.UserID))
+	}
+	if !includeArchived {
+		query = query.Where("archived = ?", false)
+	}
+	if err := query.Find(&projects).Error; err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func (s *ProjectServiceImpl) UpdateProject(db *gorm.DB, projectID uuid.UUID, project *models.Project) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing models.Project
+		if err := tx.First(&existing, "id = ?", projectID).Error; err != nil {
+			return err
+		}
+		if existing.Inbox && project.Archived {
+			return ErrInboxProject
+		}
+		return tx.Model(&existing).Updates(map[string]interface{}{
+			"name":        project.Name,
+			"description": project.Description,
+			"archived":    project.Archived,
+		}).Error
+	})
+}
+
+// DeleteProject removes the project and its memberships. Its tasks are kept
+// and simply no longer belong to a project.
+func (s *ProjectServiceImpl) DeleteProject(db *gorm.DB, projectID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		if err := tx.First(&project, "id = ?", projectID).Error; err != nil {
+			return err
+		}
+		if project.Inbox {
+			return ErrInboxProject
+		}
+		if err := tx.Delete(&project).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ?", projectID).Delete(&models.ProjectMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Task{}).Where("project_id = ?", projectID).
+			Updates(map[string]interface{}{"project_id": nil, "version": nextVersion}).Error
+	})
+}
+
+func (s *ProjectServiceImpl) GetMembers(db *gorm.DB, projectID uuid.UUID) ([]models.ProjectMember, error) {
+	var members []models.ProjectMember
+	if err := db.Where("project_id = ?", projectID).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// SetMember adds a user to the project or changes their permission, and tells
+// the user about their new access
+func (s *ProjectServiceImpl) SetMember(db *gorm.DB, projectID, userID uuid.UUID, permission string) (*models.ProjectMember, error) {
+	if !models.ValidTaskAccess(permission) {
+		return nil, ErrInvalidProjectAccess
+	}
+
+	var member models.ProjectMember
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			member = models.ProjectMember{ProjectID: projectID, UserID: userID, Permission: permission}
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+			return s.announceAccess(tx, projectID, userID, "", permission)
+		}
+		if err != nil {
+			return err
+		}
+
+		if member.Permission == models.TaskAccessOwner && permission != models.TaskAccessOwner {
+			if err := ensureOtherProjectOwner(tx, projectID, userID); err != nil {
+				return err
+			}
+		}
+		previous := member.Permission
+		member.Permission = permission
+		if err := tx.Model(&member).Where("project_id = ? AND user_id = ?", projectID, userID).Update("permission", permission).Error; err != nil {
+			return err
+		}
+		if previous == permission {
+			return nil
+		}
+		return s.announceAccess(tx, projectID, userID, previous, permission)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *ProjectServiceImpl) announceAccess(tx *gorm.DB, projectID, userID uuid.UUID, previous, permission string) error {
+	var project models.Project
+	if err := tx.Select("id", "tenant_id", "name").First(&project, "id = ?", projectID).Error; err != nil {
+		return err
+	}
+	change := roleChange{UserID: userID, Scope: roleScopeProject, ScopeID: projectID, Role: permission}
+	if err := recordRoleChange(tx, project.TenantID, change, previous); err != nil {
+		return err
+	}
+	return s.notifications.Publish(tx, NotificationEvent{
+		Type:     models.NotificationRoleChanged,
+		TenantID: project.TenantID,
+		UserID:   userID,
+		Title:    "Your access to project " + project.Name + " is now " + permission,
+	})
+}
+
+func (s *ProjectServiceImpl) RemoveMember(db *gorm.DB, projectID, userID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var member models.ProjectMember
+		if err := tx.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotProjectMember
+			}
+			return err
+		}
+		if member.Permission == models.TaskAccessOwner {
+			if err := ensureOtherProjectOwner(tx, projectID, userID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("project_id = ? AND user_id = ?", projectID, userID).Delete(&models.ProjectMember{}).Error; err != nil {
+			return err
+		}
+		return recordRoleChange(tx, member.TenantID, roleChange{UserID: userID, Scope: roleScopeProject, ScopeID: projectID}, member.Permission)
+	})
+}
+
+// GetProjectAccess resolves the actor's permission on a project. Admins are treated as owners.
+func (s *ProjectServiceImpl) GetProjectAccess(db *gorm.DB, projectID uuid.UUID, actor Actor) (string, error) {
+	if actor.IsAdmin() {
+		return models.TaskAccessOwner, nil
+	}
+	return ProjectMemberPermission(db, projectID, actor.UserID)
+}
+
+func (s *ProjectServiceImpl) GetProjectTasks(db *gorm.DB, projectID uuid.UUID, filter TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := db.Scopes(filter.Scope, withLabels).Where("project_id = ?", projectID).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ProjectMemberPermission returns the user's permission on a project, or
+// TaskAccessNone when they are not a member
+func ProjectMemberPermission(db *gorm.DB, projectID, userID uuid.UUID) (string, error) {
+	var member models.ProjectMember
+	err := db.Where("project_id = ? AND user_id = ?", projectID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.TaskAccessNone, nil
+	}
+	if err != nil {
+		return models.TaskAccessNone, err
+	}
+	return member.Permission, nil
+}
+
+// memberProjectIDs is a subquery selecting the IDs of every project the user belongs to
+func memberProjectIDs(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.ProjectMember{}).
+		Select("project_id").
+		Where("user_id = ?", userID)
+}
+
+func ensureOtherProjectOwner(tx *gorm.DB, projectID, userID uuid.UUID) error {
+	var owners int64
+	err := tx.Model(&models.ProjectMember{}).
+		Where("project_id = ? AND permission = ? AND user_id <> ?", projectID, models.TaskAccessOwner, userID).
+		Count(&owners).Error
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastProjectOwner
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/events"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/rrule"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidRecurrence      = rrule.ErrInvalidRule
+	ErrRecurrenceNeedsDueDate = apperr.Invalid("due_date", "required_for_recurrence", "a recurring task needs a due_date")
+)
+
+// maxCatchUp limits how many missed occurrences of one series a single
+// generator pass creates
+const maxCatchUp = 100
+
+type RecurrenceService interface {
+	SetRecurrence(db *gorm.DB, taskID uuid.UUID, recurrence string) (*models.Task, error)
+	GenerateDue(db *gorm.DB, now time.Time) (int, error)
+	Run(ctx context.Context, db *gorm.DB, interval time.Duration)
+}
+
+type RecurrenceServiceImpl struct {
+	lead     time.Duration
+	webhooks WebhookPublisher
+	events   events.Broker
+}
+
+// NewRecurrenceService returns a service that creates each occurrence lead
+// ahead of its due date
+func NewRecurrenceService(lead time.Duration) *RecurrenceServiceImpl {
+	return &RecurrenceServiceImpl{lead: lead, webhooks: NewWebhookService()}
+}
+
+// WithEvents makes the service announce the occurrences it creates and deletes on broker
+func (s *RecurrenceServiceImpl) WithEvents(broker events.Broker) *RecurrenceServiceImpl {
+	s.events = broker
+	return s
+}
+
+// SetRecurrence changes the schedule of a task and of all occurrences after
+// it. The series the task belonged to ends just before it, open occurrences
+// already generated after it are deleted, and the task starts a new series
+// with the new rule. An empty rule stops the series at this task.
+func (s *RecurrenceServiceImpl) SetRecurrence(db *gorm.DB, taskID uuid.UUID, recurrence string) (*models.Task, error) {
+	var task models.Task
+	var successor *models.Task
+	var deleted []models.Task
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&task, "id = ?", taskID).Error; err != nil {
+			return err
+		}
+		before := task
+
+		updates := map[string]interface{}{"recurrence": "", "series_id": nil, "occurrence_at": nil, "version": nextVersion}
+		if recurrence != "" {
+			rule, err := parseRecurrence(recurrence)
+			if err != nil {
+				return err
+			}
+			if task.DueDate == nil {
+				return ErrRecurrenceNeedsDueDate
+			}
+			seriesID, err := uuid.NewV4()
+			if err != nil {
+				return err
+			}
+			updates = map[string]interface{}{"recurrence": rule.String(), "series_id": seriesID, "occurrence_at": *task.DueDate, "version": nextVersion}
+		}
+
+		if task.SeriesID != nil && task.OccurrenceAt != nil {
+			var err error
+			if deleted, err = endSeries(tx, &task); err != nil {
+				return err
+			}
+			for i := range deleted {
+				if err := recordTaskEvent(tx, s.webhooks, models.EventTaskDeleted, nil, &deleted[i]); err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Model(&models.Task{}).Where("id = ?", taskID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&task, "id = ?", taskID).Error; err != nil {
+			return err
+		}
+		if err := recordTaskEvent(tx, s.webhooks, models.EventTaskUpdated, &before, &task); err != nil {
+			return err
+		}
+
+		// A closed task will not be completed again, so its successor is due now
+		if task.SeriesID != nil && models.TaskClosed(task.Status) {
+			var err error
+			if successor, err = generateSuccessor(tx, &task); err != nil || successor == nil {
+				return err
+			}
+			return recordTaskEvent(tx, s.webhooks, models.EventTaskCreated, nil, successor)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := db.Statement.Context
+	publishTaskEvent(ctx, s.events, events.TaskUpdated, &task, nil)
+	publishTaskEvent(ctx, s.events, events.TaskCreated, successor, nil)
+	for i := range deleted {
+		publishTaskEvent(ctx, s.events, events.TaskDeleted, &deleted[i], nil)
+	}
+	return &task, nil
+}
+
+// GenerateDue creates every occurrence that falls within the lead time of now,
+// catching up on any that were missed. It returns how many it created. It is
+// safe to run on several replicas at once: an occurrence that another replica
+// created first is skipped.
+func (s *RecurrenceServiceImpl) GenerateDue(db *gorm.DB, now time.Time) (int, error) {
+	var seriesIDs []uuid.UUID
+	err := db.Model(&models.Task{}).
+		Where("series_id IS NOT NULL AND recurrence <> ''").
+		Distinct("series_id").
+		Pluck("series_id", &seriesIDs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	horizon := now.Add(s.lead)
+	created := 0
+	for _, seriesID := range seriesIDs {
+		for i := 0; i < maxCatchUp; i++ {
+			var next *models.Task
+			err := db.Transaction(func(tx *gorm.DB) error {
+				var err error
+				if next, err = generateNextOccurrence(tx, seriesID, &horizon); err != nil || next == nil {
+					return err
+				}
+				return recordTaskEvent(tx, s.webhooks, models.EventTaskCreated, nil, next)
+			})
+			if err != nil {
+				return created, err
+			}
+			if next == nil {
+				break
+			}
+			publishTaskEvent(db.Statement.Context, s.events, events.TaskCreated, next, nil)
+			created++
+		}
+	}
+	return created, nil
+}
+
+// Run calls GenerateDue every interval until ctx is cancelled
+func (s *RecurrenceServiceImpl) Run(ctx context.Context, db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.GenerateDue(db, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "generating recurring tasks failed", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "created task occurrences", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// parseRecurrence parses a rule sent by a client, reporting a bad rule as an
+// invalid recurrence field. The error still matches ErrInvalidRecurrence.
+func parseRecurrence(raw string) (*rrule.Rule, error) {
+	rule, err := rrule.Parse(raw)
+	if err != nil {
+		return nil, apperr.Invalid("recurrence", "invalid_recurrence", err.Error()).Wrap(err)
+	}
+	return rule, nil
+}
+
+// prepareRecurrence validates the rule of a new task and makes the task the
+// first occurrence of a new series
+func prepareRecurrence(task *models.Task) error {
+	task.SeriesID, task.OccurrenceAt = nil, nil
+	if task.Recurrence == "" {
+		return nil
+	}
+	rule, err := parseRecurrence(task.Recurrence)
+	if err != nil {
+		return err
+	}
+	if task.DueDate == nil {
+		return ErrRecurrenceNeedsDueDate
+	}
+	task.Recurrence = rule.String()
+	seriesID := task.ID
+	occurrenceAt := *task.DueDate
+	task.SeriesID, task.OccurrenceAt = &seriesID, &occurrenceAt
+	return nil
+}
+
+// endSeries stops the task's series just before the task's occurrence and
+// deletes the open occurrences the series already produced after it, returning
+// them. The rule is cut short on every other occurrence, deleted ones included,
+// so that the generator finds nothing left to create whichever occurrence is
+// the latest.
+func endSeries(tx *gorm.DB, task *models.Task) ([]models.Task, error) {
+	if rule, err := rrule.Parse(task.Recurrence); err == nil {
+		ended := rule.WithUntil(task.OccurrenceAt.Add(-time.Second)).String()
+		err := tx.Unscoped().Model(&models.Task{}).
+			Where("series_id = ? AND id <> ?", *task.SeriesID, task.ID).
+			Updates(map[string]interface{}{"recurrence": ended, "version": nextVersion}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var open []models.Task
+	err := tx.Where("series_id = ? AND occurrence_at > ? AND status NOT IN ?",
+		*task.SeriesID, *task.OccurrenceAt, []string{models.TaskStatusCompleted, models.TaskStatusCancelled}).
+		Find(&open).Error
+	if err != nil || len(open) == 0 {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(open))
+	for i := range open {
+		ids[i] = open[i].ID
+	}
+	return open, tx.Where("id IN ?", ids).Delete(&models.Task{}).Error
+}
+
+// updateFollowing copies the title, description and priority among the written
+// fields to the open occurrences after existing in its series
+func updateFollowing(tx *gorm.DB, existing, task *models.Task, fields map[string]bool) error {
+	updates := map[string]interface{}{}
+	if fields["title"] {
+		updates["title"] = task.Title
+	}
+	if fields["description"] {
+		updates["description"] = task.Description
+	}
+	if fields["priority"] {
+		updates["priority"] = task.Priority
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	updates["version"] = nextVersion
+	return tx.Model(&models.Task{}).
+		Where("series_id = ? AND occurrence_at > ? AND status NOT IN ?",
+			*existing.SeriesID, *existing.OccurrenceAt, []string{models.TaskStatusCompleted, models.TaskStatusCancelled}).
+		Updates(updates).Error
+}
+
+// generateSuccessor creates the occurrence after task when task is the latest
+// of its series and returns it. Closing an earlier occurrence creates nothing,
+// since its successor already exists.
+func generateSuccessor(tx *gorm.DB, task *models.Task) (*models.Task, error) {
+	var later int64
+	err := tx.Unscoped().Model(&models.Task{}).
+		Where("series_id = ? AND occurrence_at > ?", *task.SeriesID, *task.OccurrenceAt).
+		Count(&later).Error
+	if err != nil || later > 0 {
+		return nil, err
+	}
+	return generateNextOccurrence(tx, *task.SeriesID, nil)
+}
+
+// generateNextOccurrence creates the occurrence following the latest one of a
+// series, copying the latest occurrence's details and labels. It returns nil
+// when the series has ended, when the next occurrence falls after notAfter,
+// when the series' project is archived or when the occurrence already exists.
+// Deleted occurrences count as existing, so deleting one does not bring it
+// back.
+func generateNextOccurrence(tx *gorm.DB, seriesID uuid.UUID, notAfter *time.Time) (*models.Task, error) {
+	var first, latest models.Task
+	if err := tx.Unscoped().Where("series_id = ?", seriesID).Order("occurrence_at").First(&first).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Where("series_id = ?", seriesID).Order("occurrence_at DESC").First(&latest).Error; err != nil {
+		return nil, err
+	}
+	if latest.Recurrence == "" {
+		return nil, nil
+	}
+	rule, err := rrule.Parse(latest.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	occurrenceAt, ok := rule.After(*first.OccurrenceAt, *latest.OccurrenceAt)
+	if !ok || (notAfter != nil && occurrenceAt.After(*notAfter)) {
+		return nil, nil
+	}
+
+	if latest.ProjectID != nil {
+		var project models.Project
+		if err := tx.Select("archived").First(&project, "id = ?", *latest.ProjectID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if project.Archived {
+			return nil, nil
+		}
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	next := models.Task{
+		ID:           id,
+		TenantID:     latest.TenantID,
+		Title:        latest.Title,
+		Description:  latest.Description,
+		Status:       models.TaskStatusPending,
+		Priority:     latest.Priority,
+		DueDate:      &occurrenceAt,
+		UserID:       latest.UserID,
+		TeamID:       latest.TeamID,
+		ProjectID:    latest.ProjectID,
+		Recurrence:   latest.Recurrence,
+		SeriesID:     &seriesID,
+		OccurrenceAt: &occurrenceAt,
+		Version:      1,
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Labels").Create(&next)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var labels []models.TaskLabel
+	if err := tx.Where("task_id = ?", latest.ID).Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	for i := range labels {
+		labels[i].TaskID = next.ID
+		labels[i].CreatedAt = time.Time{}
+	}
+	if len(labels) > 0 {
+		if err := tx.Create(&labels).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &next, nil
+}
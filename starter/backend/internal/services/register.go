@@ -12,10 +12,13 @@ type RegisterService interface {
 	RegisterUser(db *gorm.DB, user models.User) error
 }
 
-type RegisterServiceImpl struct{}
+type RegisterServiceImpl struct {
+	projectService ProjectService
+	webhooks       WebhookPublisher
+}
 
 func NewRegisterService() *RegisterServiceImpl {
-	return &RegisterServiceImpl{}
+	return &RegisterServiceImpl{projectService: NewProjectService(), webhooks: NewWebhookService()}
 }
 
 func (s *RegisterServiceImpl) RegisterUser(db *gorm.DB, user models.User) error {
@@ -42,7 +45,14 @@ func (s *RegisterServiceImpl) RegisterUser(db *gorm.DB, user models.User) error
 	// Assign the default user role
 	user.Roles = []models.Role{userRole}
 
-	// Create the user in the database
-	result := db.Create(&user)
-	return result.Error
+	// Create the user together with their Inbox project
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if _, err := s.projectService.CreateInbox(tx, user.ID); err != nil {
+			return err
+		}
+		return recordUserEvent(tx, s.webhooks, models.EventUserCreated, user.ID)
+	})
 }
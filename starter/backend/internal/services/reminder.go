@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/notify"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidReminderLead    = apperr.Invalid("lead_minutes", "out_of_range", "lead_minutes must be between 1 and 10080")
+	ErrInvalidReminderChannel = apperr.Invalid("channels", "invalid_channel", "unknown or unavailable reminder channel")
+	ErrInvalidWebhookURL      = apperr.Invalid("webhook_url", "invalid_url", "webhook_url must be an http or https URL")
+)
+
+const (
+	// maxReminderLead is the earliest a reminder can be asked for
+	maxReminderLead = 7 * 24 * time.Hour
+	// overdueWindow limits overdue reminders to recently missed due dates, so
+	// that old tasks do not all fire at once when reminders are switched on
+	overdueWindow = 7 * 24 * time.Hour
+
+	reminderLease = "reminders"
+)
+
+type ReminderService interface {
+	GetPreference(db *gorm.DB, userID uuid.UUID) (*models.ReminderPreference, error)
+	SetPreference(db *gorm.DB, pref *models.ReminderPreference) error
+	SendDue(db *gorm.DB, now time.Time) (int, error)
+	Run(ctx context.Context, db *gorm.DB, interval time.Duration)
+}
+
+type ReminderServiceImpl struct {
+	notifiers   map[string]notify.Notifier
+	defaultLead time.Duration
+	holder      string
+}
+
+// NewReminderService returns a service that sends reminders over the given
+// channels, keyed by channel name. Users who have not set a preference are
+// reminded in-app defaultLead before a task is due.
+func NewReminderService(notifiers map[string]notify.Notifier, defaultLead time.Duration) *ReminderServiceImpl {
+	return &ReminderServiceImpl{
+		notifiers:   notifiers,
+		defaultLead: defaultLead,
+		holder:      leaseHolder(),
+	}
+}
+
+// GetPreference returns the user's reminder preference, or the defaults when
+// they have not set one
+func (s *ReminderServiceImpl) GetPreference(db *gorm.DB, userID uuid.UUID) (*models.ReminderPreference, error) {
+	var pref models.ReminderPreference
+	err := db.First(&pref, "user_id = ?", userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.ReminderPreference{
+			UserID:      userID,
+			Enabled:     true,
+			LeadMinutes: int(s.defaultLead / time.Minute),
+			Overdue:     true,
+			Channels:    []string{models.ReminderChannelInApp},
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (s *ReminderServiceImpl) SetPreference(db *gorm.DB, pref *models.ReminderPreference) error {
+	if pref.LeadMinutes < 1 || time.Duration(pref.LeadMinutes)*time.Minute > maxReminderLead {
+		return ErrInvalidReminderLead
+	}
+	channels := make([]string, 0, len(pref.Channels))
+	seen := make(map[string]bool, len(pref.Channels))
+	for _, channel := range pref.Channels {
+		if _, ok := s.notifiers[channel]; !ok {
+			return fmt.Errorf("%w: %s", ErrInvalidReminderChannel, channel)
+		}
+		if !seen[channel] {
+			seen[channel] = true
+			channels = append(channels, channel)
+		}
+	}
+	pref.Channels = channels
+	if (pref.WebhookURL != "" || seen[models.ReminderChannelWebhook]) && !validWebhookURL(pref.WebhookURL) {
+		return ErrInvalidWebhookURL
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "lead_minutes", "overdue", "channels", "webhook_url", "updated_at"}),
+	}).Create(pref).Error
+}
+
+// SendDue sends the reminders that are due at now and returns how many went
+// out. A reminder is claimed in task_reminders before it is sent and the claim
+// is dropped again if sending fails, so every reminder is sent once per
+// channel and failed ones are retried on the next pass.
+func (s *ReminderServiceImpl) SendDue(db *gorm.DB, now time.Time) (int, error) {
+	var tasks []models.Task
+	err := db.Where("due_date > ? AND due_date <= ? AND status NOT IN ?",
+		now.Add(-overdueWindow), now.Add(maxReminderLead), []string{models.TaskStatusCompleted, models.TaskStatusCancelled}).
+		Find(&tasks).Error
+	if err != nil {
+		return 0, err
+	}
+
+	prefs := make(map[uuid.UUID]*models.ReminderPreference)
+	sent := 0
+	for _, task := range tasks {
+		pref, ok := prefs[task.UserID]
+		if !ok {
+			if pref, err = s.GetPreference(db, task.UserID); err != nil {
+				return sent, err
+			}
+			prefs[task.UserID] = pref
+		}
+
+		kind := reminderKind(&task, pref, now)
+		if kind == "" {
+			continue
+		}
+		var user models.User
+		if err := db.First(&user, "id = ?", task.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return sent, err
+		}
+
+		msg := reminderMessage(&task, &user, pref, kind, now)
+		for _, channel := range pref.Channels {
+			notifier, ok := s.notifiers[channel]
+			if !ok {
+				continue
+			}
+			ok, err := s.send(db, notifier, channel, &task, kind, msg, now)
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// Run sends due reminders every interval until ctx is cancelled. Only the
+// replica holding the reminder lease sends; the others keep trying to take
+// it over, which they can once its holder has stopped renewing it for a few
+// intervals.
+func (s *ReminderServiceImpl) Run(ctx context.Context, db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer ReleaseLease(db, reminderLease, s.holder)
+	for {
+		held, err := AcquireLease(db, reminderLease, s.holder, 3*interval, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "acquiring the reminder lease failed", "error", err)
+		} else if held {
+			if n, err := s.SendDue(db, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "sending reminders failed", "error", err)
+			} else if n > 0 {
+				slog.InfoContext(ctx, "sent reminders", "count", n)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// send claims one reminder and delivers it. It returns false when the
+// reminder was already sent or delivery failed.
+func (s *ReminderServiceImpl) send(db *gorm.DB, notifier notify.Notifier, channel string, task *models.Task, kind string, msg notify.Message, now time.Time) (bool, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return false, err
+	}
+	claim := models.TaskReminder{
+		ID:       id,
+		TenantID: task.TenantID,
+		TaskID:   task.ID,
+		UserID:   task.UserID,
+		Kind:     kind,
+		Channel:  channel,
+		DueDate:  task.DueDate.UTC(),
+		SentAt:   now,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&claim)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	err = notifier.Notify(db.Statement.Context, msg)
+	if errors.Is(err, notify.ErrNoAddress) {
+		// Retrying cannot help a user with nowhere to send to
+		return false, nil
+	}
+	if err != nil {
+		slog.WarnContext(db.Statement.Context, "sending reminder failed", "kind", kind, "task_id", task.ID, "channel", channel, "error", err)
+		return false, db.Delete(&models.TaskReminder{}, "id = ?", claim.ID).Error
+	}
+	return true, nil
+}
+
+// reminderKind tells which reminder, if any, the task's owner wants at now
+func reminderKind(task *models.Task, pref *models.ReminderPreference, now time.Time) string {
+	switch {
+	case !pref.Enabled:
+		return ""
+	case !task.DueDate.After(now):
+		if pref.Overdue {
+			return models.ReminderOverdue
+		}
+		return ""
+	case task.DueDate.Sub(now) <= time.Duration(pref.LeadMinutes)*time.Minute:
+		return models.ReminderDueSoon
+	default:
+		return ""
+	}
+}
+
+func reminderMessage(task *models.Task, user *models.User, pref *models.ReminderPreference, kind string, now time.Time) notify.Message {
+	taskID := task.ID
+	msg := notify.Message{
+		TenantID:   task.TenantID,
+		UserID:     user.ID,
+		TaskID:     &taskID,
+		CreatedAt:  now,
+		Email:      user.Email,
+		WebhookURL: pref.WebhookURL,
+	}
+	due := task.DueDate.UTC().Format(time.RFC1123)
+	if kind == models.ReminderOverdue {
+		msg.Event = models.NotificationTaskOverdue
+		msg.Subject = "Task overdue: " + task.Title
+		msg.Text = fmt.Sprintf("%q was due %s.", task.Title, due)
+	} else {
+		msg.Event = models.NotificationTaskDueSoon
+		msg.Subject = "Task due soon: " + task.Title
+		msg.Text = fmt.Sprintf("%q is due %s.", task.Title, due)
+	}
+	return msg
+}
@@ -2,63 +2,605 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/events"
 	"task-manager/backend/internal/models"
 	"github.com/gofrs/uuid"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrTaskNotFound   = apperr.New(apperr.KindNotFound, "task_not_found", "task not found")
+	ErrParentNotFound = apperr.New(apperr.KindNotFound, "parent_not_found", "parent task not found")
+	ErrOpenBlockers   = apperr.New(apperr.KindConflict, "open_blockers", "task is blocked by tasks that are not completed")
+	ErrOpenSubtasks   = apperr.New(apperr.KindConflict, "open_subtasks", "task has subtasks that are not completed")
+	// ErrVersionMismatch means the task changed since the version the caller read
+	ErrVersionMismatch   = apperr.New(apperr.KindPreconditionFailed, "version_mismatch", "task has been modified since it was read")
+	ErrFieldNotUpdatable = apperr.New(apperr.KindBadRequest, "field_not_updatable", "field cannot be updated")
+	ErrOwnerRequired     = apperr.Invalid("user_id", "required", "a task must have an owner")
+)
+
+// UpdatableTaskFields are the fields UpdateTask writes, by their JSON and
+// column names. The others follow from them or have operations of their own.
+var UpdatableTaskFields = []string{"title", "description", "status", "priority", "due_date", "user_id", "team_id", "project_id", "parent_id"}
+
+// nextVersion advances a task's version. Every write to a task or to the
+// labels on it must include it, so that the version, which is the task's ETag,
+// changes whenever its representation does.
+var nextVersion = gorm.Expr("version + 1")
+
 type TaskService interface {
 	CreateTask(db *gorm.DB, task *models.Task) error
 	GetTaskByID(db *gorm.DB, taskID uuid.UUID) (*models.Task, error)
-	GetTasks(db *gorm.DB) ([]models.Task, error)
-	UpdateTask(db *gorm.DB, taskID uuid.UUID, task *models.Task) error
-	DeleteTask(db *gorm.DB, taskID uuid.UUID) error
+	GetTasks(db *gorm.DB, filter TaskFilter) ([]models.Task, error)
+	GetTasksByUser(db *gorm.DB, userID uuid.UUID, actor Actor, filter TaskFilter) ([]models.Task, error)
+	GetSharedTasks(db *gorm.DB, actor Actor, filter TaskFilter) ([]models.Task, error)
+	GetTaskAccess(db *gorm.DB, task *models.Task, actor Actor) (string, error)
+	GetTaskTree(db *gorm.DB, taskID uuid.UUID) (*TaskTree, error)
+	UpdateTask(db *gorm.DB, taskID uuid.UUID, task *models.Task, opts UpdateOptions) error
+	DeleteTask(db *gorm.DB, taskID uuid.UUID, ifVersion int) error
+	GetTaskHistory(db *gorm.DB, taskID uuid.UUID, page Page) ([]models.TaskRevision, int64, error)
+	RevertTask(db *gorm.DB, taskID uuid.UUID, revision int, opts UpdateOptions) error
+}
+
+// UpdateOptions adjust how UpdateTask applies a change
+type UpdateOptions struct {
+	// Force completes a task even while blockers or subtasks are still open
+	Force bool
+	// Following also applies the title, description and priority to the open
+	// occurrences after this one in a recurring series
+	Following bool
+	// IfVersion applies the change only while the task is still at this
+	// version, and fails with ErrVersionMismatch otherwise. 0 applies it
+	// regardless.
+	IfVersion int
+	// Fields names the fields to write, from UpdatableTaskFields, zero values
+	// included. When empty, the task's non-zero fields are written.
+	Fields []string
+
+	// revertedFrom is the revision a revert restores
+	revertedFrom int
 }
 
-type TaskServiceImpl struct{}
+type TaskServiceImpl struct {
+	workflow      *Workflow
+	notifications NotificationPublisher
+	webhooks      WebhookPublisher
+	events        events.Broker
+}
 
 func NewTaskService() *TaskServiceImpl {
-	return &TaskServiceImpl{}
+	return NewTaskServiceWithWorkflow(DefaultWorkflow())
+}
+
+func NewTaskServiceWithWorkflow(workflow *Workflow) *TaskServiceImpl {
+	return &TaskServiceImpl{workflow: workflow, notifications: NewNotificationService(), webhooks: NewWebhookService()}
 }
 
+// WithEvents makes the service announce created, updated and deleted tasks on broker
+func (s *TaskServiceImpl) WithEvents(broker events.Broker) *TaskServiceImpl {
+	s.events = broker
+	return s
+}
+
+// CreateTask stores a task. Subtasks created without a project or team inherit
+// their parent's. Personal tasks created without a project land in the owner's
+// Inbox project when they have one. Archived projects accept no new tasks.
+// Status and priority default to pending and medium. A task with a recurrence
+// rule starts a new series.
 func (s *TaskServiceImpl) CreateTask(db *gorm.DB, task *models.Task) error {
-	return db.Create(task).Error
+	if task.Status == "" {
+		task.Status = models.TaskStatusPending
+	}
+	if task.Priority == "" {
+		task.Priority = models.TaskPriorityMedium
+	}
+	if !models.ValidTaskStatus(task.Status) {
+		return ErrInvalidStatus
+	}
+	if !models.ValidTaskPriority(task.Priority) {
+		return ErrInvalidPriority
+	}
+	task.StartedAt, task.CompletedAt = nil, nil
+	task.Version = 1
+	stampStatusTimes(task, nil, time.Now())
+	if err := prepareRecurrence(task); err != nil {
+		return err
+	}
+
+	if task.ParentID != nil {
+		var parent models.Task
+		if err := db.First(&parent, "id = ?", *task.ParentID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrParentNotFound
+			}
+			return err
+		}
+		if task.ProjectID == nil && task.TeamID == nil {
+			task.ProjectID = parent.ProjectID
+			task.TeamID = parent.TeamID
+		}
+	}
+
+	if task.ProjectID != nil {
+		var project models.Project
+		if err := db.First(&project, "id = ?", *task.ProjectID).Error; err != nil {
+			return err
+		}
+		if project.Archived {
+			return ErrProjectArchived
+		}
+	} else if task.TeamID == nil {
+		inboxID, err := inboxProjectID(db, task.UserID)
+		if err != nil {
+			return err
+		}
+		task.ProjectID = inboxID
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Labels").Create(task).Error; err != nil {
+			return err
+		}
+		return recordTaskEvent(tx, s.webhooks, models.EventTaskCreated, nil, task)
+	})
+	if err != nil {
+		return err
+	}
+	publishTaskEvent(db.Statement.Context, s.events, events.TaskCreated, task, nil)
+	return nil
+}
+
+// inboxProjectID returns the ID of the user's Inbox project, or nil when they
+// have none
+func inboxProjectID(db *gorm.DB, userID uuid.UUID) (*uuid.UUID, error) {
+	var inbox models.Project
+	err := db.Where("owner_id = ? AND inbox = ?", userID, true).First(&inbox).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inbox.ID, nil
 }
 
 func (s *TaskServiceImpl) GetTaskByID(db *gorm.DB, taskID uuid.UUID) (*models.Task, error) {
 	var task models.Task
-	if err := db.First(&task, "id = ?", taskID).Error; err != nil {
+	if err := db.Scopes(withLabels).First(&task, "id = ?", taskID).Error; err != nil {
 		return nil, err
 	}
 	return &task, nil
 }
 
-func (s *TaskServiceImpl) GetTasks(db *gorm.DB) ([]models.Task, error) {
+func (s *TaskServiceImpl) GetTasks(db *gorm.DB, filter TaskFilter) ([]models.Task, error) {
 	var tasks []models.Task
-	if err := db.Find(&tasks).Error; err != nil {
+	if err := db.Scopes(filter.Scope, withLabels).Find(&tasks).Error; err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
-func (s *TaskServiceImpl) UpdateTask(db *gorm.DB, taskID uuid.UUID, task *models.Task) error {
-	result := db.Model(&models.Task{}).Where("id = ?", taskID).Updates(task)
-	if result.Error != nil {
-		return result.Error
+// GetTasksByUser returns the tasks owned by userID that the actor is allowed to see
+func (s *TaskServiceImpl) GetTasksByUser(db *gorm.DB, userID uuid.UUID, actor Actor, filter TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	query := db.Where("user_id = ?", userID).Scopes(filter.Scope, withLabels)
+	if userID != actor.UserID {
+		query = query.Scopes(VisibleTasks(db, actor))
 	}
-	if result.RowsAffected == 0 {
-		return errors.New("task not found")
+	if err := query.Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetSharedTasks returns tasks owned by someone else that were shared with the actor
+func (s *TaskServiceImpl) GetSharedTasks(db *gorm.DB, actor Actor, filter TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	err := db.Scopes(filter.Scope, withLabels).
+		Where("user_id <> ?", actor.UserID).
+		Where("id IN (?)", sharedTaskIDs(db, actor)).
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetTaskAccess resolves the strongest access level the actor holds on a task.
+// Admins and the task owner get owner access. On team tasks team admins get
+// owner access and members editor access, and on project tasks project members
+// get their project permission. Everyone else gets whatever has been granted
+// to them directly, through one of their roles or through a team.
+func (s *TaskServiceImpl) GetTaskAccess(db *gorm.DB, task *models.Task, actor Actor) (string, error) {
+	return taskAccess(db, task, actor)
+}
+
+// taskAccess implements GetTaskAccess for services that hold no TaskService
+func taskAccess(db *gorm.DB, task *models.Task, actor Actor) (string, error) {
+	if actor.IsAdmin() || task.UserID == actor.UserID {
+		return models.TaskAccessOwner, nil
+	}
+
+	level := models.TaskAccessNone
+	if task.TeamID != nil {
+		role, err := TeamMemberRole(db, *task.TeamID, actor.UserID)
+		switch {
+		case err == nil && role == models.TeamRoleAdmin:
+			return models.TaskAccessOwner, nil
+		case err == nil:
+			level = models.TaskAccessEditor
+		case !errors.Is(err, ErrNotTeamMember):
+			return models.TaskAccessNone, err
+		}
+	}
+	if task.ProjectID != nil {
+		projectLevel, err := ProjectMemberPermission(db, *task.ProjectID, actor.UserID)
+		if err != nil {
+			return models.TaskAccessNone, err
+		}
+		if models.TaskAccessRank(projectLevel) > models.TaskAccessRank(level) {
+			level = projectLevel
+		}
+	}
+
+	var shares []models.TaskShare
+	err := db.Where("task_id = ?", task.ID).
+		Where(shareSubjectCondition(db, actor)).
+		Find(&shares).Error
+	if err != nil {
+		return models.TaskAccessNone, err
+	}
+
+	for _, share := range shares {
+		if models.TaskAccessRank(share.Permission) > models.TaskAccessRank(level) {
+			level = share.Permission
+		}
+	}
+	return level, nil
+}
+
+// GetTaskTree returns the task with all of its descendants and their progress
+func (s *TaskServiceImpl) GetTaskTree(db *gorm.DB, taskID uuid.UUID) (*TaskTree, error) {
+	task, err := s.GetTaskByID(db, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return buildTaskTree(db, task)
+}
+
+// UpdateTask writes the fields of task named in opts.Fields, or its non-zero
+// fields when opts.Fields is empty. Status changes must follow the workflow
+// and stamp started_at and completed_at. A task cannot be completed while it
+// has open blockers or subtasks unless force is set, and cannot be moved under
+// a parent that would make it wait on itself. Closing an occurrence of a
+// recurring task creates the next one. A new owner is notified of the
+// assignment.
+func (s *TaskServiceImpl) UpdateTask(db *gorm.DB, taskID uuid.UUID, task *models.Task, opts UpdateOptions) error {
+	fields, err := updatedFields(task, opts.Fields)
+	if err != nil {
+		return err
+	}
+	if fields["priority"] && !models.ValidTaskPriority(task.Priority) {
+		return ErrInvalidPriority
+	}
+	if fields["status"] && !models.ValidTaskStatus(task.Status) {
+		return ErrInvalidStatus
+	}
+	if fields["user_id"] && task.UserID == uuid.Nil {
+		return ErrOwnerRequired
+	}
+
+	var existing models.Task
+	var successor *models.Task
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&existing, "id = ?", taskID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+
+		if fields["parent_id"] && task.ParentID != nil {
+			if err := checkParent(tx, taskID, *task.ParentID); err != nil {
+				return err
+			}
+		}
+
+		// Compare and advance the version first: the row stays locked until
+		// commit, so a concurrent update waits and then finds it stale
+		if err := bumpVersion(tx, taskID, opts.IfVersion); err != nil {
+			return err
+		}
+
+		columns := make([]string, 0, len(fields)+3)
+		for _, field := range UpdatableTaskFields {
+			if fields[field] {
+				columns = append(columns, field)
+			}
+		}
+		// The timestamps follow the status and are never taken from the client
+		task.StartedAt, task.CompletedAt = nil, nil
+		statusChanged := fields["status"] && task.Status != existing.Status
+		if statusChanged {
+			if err := s.workflow.CheckTransition(existing.Status, task.Status); err != nil {
+				return err
+			}
+			if task.Status == models.TaskStatusCompleted && !opts.Force {
+				if err := checkCompletable(tx, taskID); err != nil {
+					return err
+				}
+			}
+			stampStatusTimes(task, existing.StartedAt, time.Now())
+			// Reopening a task clears completed_at
+			columns = append(columns, "completed_at")
+			if task.StartedAt != nil {
+				columns = append(columns, "started_at")
+			}
+		}
+		columns = append(columns, "updated_at")
+
+		if err := tx.Model(&models.Task{}).Where("id = ?", taskID).Select(columns).Updates(task).Error; err != nil {
+			return err
+		}
+
+		if fields["user_id"] && task.UserID != existing.UserID {
+			title := existing.Title
+			if fields["title"] && task.Title != "" {
+				title = task.Title
+			}
+			err := s.notifications.Publish(tx, NotificationEvent{
+				Type:     models.NotificationTaskAssigned,
+				TenantID: existing.TenantID,
+				UserID:   task.UserID,
+				TaskID:   &existing.ID,
+				Title:    "Task assigned to you: " + title,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		var latest int
+		if opts.revertedFrom > 0 {
+			var err error
+			if latest, err = lastRevision(tx, taskID); err != nil {
+				return err
+			}
+		}
+		if err := recordTaskEvent(tx, s.webhooks, models.EventTaskUpdated, &existing, &existing); err != nil {
+			return err
+		}
+		if opts.revertedFrom > 0 {
+			// Only a revert that changed something has a revision to mark
+			err := tx.Model(&models.TaskRevision{}).Where("task_id = ? AND revision > ?", taskID, latest).Update("reverted_from", opts.revertedFrom).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if existing.SeriesID == nil {
+			return nil
+		}
+		if opts.Following {
+			if err := updateFollowing(tx, &existing, task, fields); err != nil {
+				return err
+			}
+		}
+		if statusChanged && models.TaskClosed(task.Status) {
+			var err error
+			if successor, err = generateSuccessor(tx, &existing); err != nil || successor == nil {
+				return err
+			}
+			return recordTaskEvent(tx, s.webhooks, models.EventTaskCreated, nil, successor)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx := db.Statement.Context
+	publishTaskEvent(ctx, s.events, events.TaskUpdated, &existing, nil)
+	publishTaskEvent(ctx, s.events, events.TaskCreated, successor, nil)
+	return nil
+}
+
+// updatedFields returns the set of fields an update writes: the named ones,
+// which must all be updatable, or else the non-zero fields of task
+func updatedFields(task *models.Task, names []string) (map[string]bool, error) {
+	fields := make(map[string]bool, len(UpdatableTaskFields))
+	if len(names) > 0 {
+		for _, name := range names {
+			if !slices.Contains(UpdatableTaskFields, name) {
+				return nil, fmt.Errorf("%w: %s", ErrFieldNotUpdatable, name)
+			}
+			fields[name] = true
+		}
+		return fields, nil
+	}
+
+	fields["title"] = task.Title != ""
+	fields["description"] = task.Description != ""
+	fields["status"] = task.Status != ""
+	fields["priority"] = task.Priority != ""
+	fields["due_date"] = task.DueDate != nil
+	fields["user_id"] = task.UserID != uuid.Nil
+	fields["team_id"] = task.TeamID != nil
+	fields["project_id"] = task.ProjectID != nil
+	fields["parent_id"] = task.ParentID != nil
+	return fields, nil
+}
+
+// DeleteTask removes the task with its shares, dependencies and labels. Its subtasks
+// move up to the deleted task's parent. A non-zero ifVersion deletes the task
+// only while it is still at that version, like UpdateOptions.IfVersion.
+func (s *TaskServiceImpl) DeleteTask(db *gorm.DB, taskID uuid.UUID, ifVersion int) error {
+	var task models.Task
+	var shares []events.ShareRef
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&task, "id = ?", taskID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+		var err error
+		if shares, err = shareRefs(tx, taskID); err != nil {
+			return err
+		}
+		deleted := tx
+		if ifVersion > 0 {
+			deleted = deleted.Where("version = ?", ifVersion)
+		}
+		result := deleted.Delete(&task)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionMismatch
+		}
+		// Queued before the shares go, so that users the task was shared with still see it
+		if err := recordTaskEvent(tx, s.webhooks, models.EventTaskDeleted, nil, &task); err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ? OR blocked_by_id = ?", taskID, taskID).Delete(&models.TaskDependency{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskLabel{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Task{}).Where("parent_id = ?", taskID).
+			Updates(map[string]interface{}{"parent_id": task.ParentID, "version": nextVersion}).Error
+	})
+	if err != nil {
+		return err
+	}
+	publishTaskEvent(db.Statement.Context, s.events, events.TaskDeleted, &task, shares)
+	return nil
+}
+
+// checkParent verifies that parentID exists and can hold taskID as a subtask.
+// A parent waits on its subtasks, so the parent must not already be something
+// taskID waits on.
+func checkParent(db *gorm.DB, taskID, parentID uuid.UUID) error {
+	var parent models.Task
+	if err := db.Select("id").First(&parent, "id = ?", parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrParentNotFound
+		}
+		return err
+	}
+
+	cycle, err := waitsOn(db, taskID, parentID)
+	if err != nil {
+		return err
+	}
+	if cycle {
+		return ErrTaskCycle
 	}
 	return nil
 }
 
-func (s *TaskServiceImpl) DeleteTask(db *gorm.DB, taskID uuid.UUID) error {
-	result := db.Delete(&models.Task{}, "id = ?", taskID)
+// bumpVersion advances the task's version. When expected is not 0 it does so
+// only while the task is at that version and returns ErrVersionMismatch when
+// it is not.
+func bumpVersion(tx *gorm.DB, taskID uuid.UUID, expected int) error {
+	query := tx.Model(&models.Task{}).Where("id = ?", taskID)
+	if expected > 0 {
+		query = query.Where("version = ?", expected)
+	}
+	result := query.UpdateColumn("version", nextVersion)
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("task not found")
+		return ErrVersionMismatch
 	}
 	return nil
 }
+
+// stampStatusTimes sets started_at the first time a task moves past pending and
+// completed_at whenever it is completed
+func stampStatusTimes(task *models.Task, startedAt *time.Time, now time.Time) {
+	switch task.Status {
+	case models.TaskStatusInProgress, models.TaskStatusReview, models.TaskStatusCompleted:
+		if startedAt == nil {
+			task.StartedAt = &now
+		}
+	}
+	if task.Status == models.TaskStatusCompleted {
+		task.CompletedAt = &now
+	}
+}
+
+// checkCompletable verifies that a task has no open blockers and no open
+// subtasks. Completed and cancelled tasks count as closed.
+func checkCompletable(db *gorm.DB, taskID uuid.UUID) error {
+	closed := []string{models.TaskStatusCompleted, models.TaskStatusCancelled}
+	blockerIDs := db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.TaskDependency{}).
+		Select("blocked_by_id").
+		Where("task_id = ?", taskID)
+
+	var openBlockers int64
+	err := db.Model(&models.Task{}).
+		Where("id IN (?) AND status NOT IN ?", blockerIDs, closed).
+		Count(&openBlockers).Error
+	if err != nil {
+		return err
+	}
+	if openBlockers > 0 {
+		return ErrOpenBlockers
+	}
+
+	var openSubtasks int64
+	err = db.Model(&models.Task{}).
+		Where("parent_id = ? AND status NOT IN ?", taskID, closed).
+		Count(&openSubtasks).Error
+	if err != nil {
+		return err
+	}
+	if openSubtasks > 0 {
+		return ErrOpenSubtasks
+	}
+	return nil
+}
+
+// VisibleTasks limits a task query to the tasks the actor owns, that belong to
+// one of the actor's teams or projects or that were shared with them. Admins
+// see everything.
+func VisibleTasks(db *gorm.DB, actor Actor) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		if actor.IsAdmin() {
+			return query
+		}
+		return query.Where("user_id = ? OR team_id IN (?) OR project_id IN (?) OR id IN (?)",
+			actor.UserID, memberTeamIDs(db, actor.UserID), memberProjectIDs(db, actor.UserID), sharedTaskIDs(db, actor))
+	}
+}
+
+// sharedTaskIDs is a subquery selecting the IDs of tasks shared with the actor
+func sharedTaskIDs(db *gorm.DB, actor Actor) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.TaskShare{}).
+		Select("task_id").
+		Where(shareSubjectCondition(db, actor))
+}
+
+// shareSubjectCondition matches shares granted to the actor, to one of the
+// actor's roles or to one of the actor's teams
+func shareSubjectCondition(db *gorm.DB, actor Actor) *gorm.DB {
+	fresh := db.Session(&gorm.Session{NewDB: true})
+	cond := fresh.Where("subject_type = ? AND subject_id = ?", models.ShareSubjectUser, actor.UserID).
+		Or("subject_type = ? AND subject_id IN (?)", models.ShareSubjectTeam, memberTeamIDs(db, actor.UserID))
+	if len(actor.Roles) > 0 {
+		roleIDs := fresh.Model(&models.Role{}).Select("id").Where("name IN ?", actor.Roles)
+		cond = cond.Or("subject_type = ? AND subject_id IN (?)", models.ShareSubjectRole, roleIDs)
+	}
+	return cond
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBlockerNotFound = apperr.New(apperr.KindNotFound, "blocker_not_found", "blocking task not found")
+	ErrTaskCycle       = apperr.New(apperr.KindBadRequest, "task_cycle", "tasks cannot wait on each other in a cycle")
+)
+
+type TaskDependencyService interface {
+	AddDependency(db *gorm.DB, taskID, blockedByID, createdBy uuid.UUID) (*models.TaskDependency, error)
+	GetDependencies(db *gorm.DB, taskID uuid.UUID) (*TaskDependencies, error)
+	RemoveDependency(db *gorm.DB, taskID, blockedByID uuid.UUID) error
+}
+
+// TaskDependencies lists the tasks a task is blocked by and the tasks it blocks
+type TaskDependencies struct {
+	BlockedBy []models.Task `json:"blocked_by"`
+	Blocking  []models.Task `json:"blocking"`
+}
+
+type TaskDependencyServiceImpl struct{}
+
+func NewTaskDependencyService() *TaskDependencyServiceImpl {
+	return &TaskDependencyServiceImpl{}
+}
+
+// AddDependency marks taskID as blocked by blockedByID. Adding an existing
+// dependency again is a no-op. Dependencies that would make a task wait on
+// itself, directly or through subtasks, are rejected with ErrTaskCycle.
+func (s *TaskDependencyServiceImpl) AddDependency(db *gorm.DB, taskID, blockedByID, createdBy uuid.UUID) (*models.TaskDependency, error) {
+	var dependency models.TaskDependency
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var blocker models.Task
+		if err := tx.Select("id").First(&blocker, "id = ?", blockedByID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBlockerNotFound
+			}
+			return err
+		}
+
+		err := tx.Where("task_id = ? AND blocked_by_id = ?", taskID, blockedByID).First(&dependency).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		cycle, err := waitsOn(tx, blockedByID, taskID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrTaskCycle
+		}
+
+		dependency = models.TaskDependency{TaskID: taskID, BlockedByID: blockedByID, CreatedBy: createdBy}
+		return tx.Create(&dependency).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dependency, nil
+}
+
+func (s *TaskDependencyServiceImpl) GetDependencies(db *gorm.DB, taskID uuid.UUID) (*TaskDependencies, error) {
+	fresh := db.Session(&gorm.Session{NewDB: true})
+	deps := TaskDependencies{BlockedBy: []models.Task{}, Blocking: []models.Task{}}
+
+	blockerIDs := fresh.Model(&models.TaskDependency{}).Select("blocked_by_id").Where("task_id = ?", taskID)
+	if err := db.Where("id IN (?)", blockerIDs).Find(&deps.BlockedBy).Error; err != nil {
+		return nil, err
+	}
+
+	blockedIDs := fresh.Model(&models.TaskDependency{}).Select("task_id").Where("blocked_by_id = ?", taskID)
+	if err := db.Where("id IN (?)", blockedIDs).Find(&deps.Blocking).Error; err != nil {
+		return nil, err
+	}
+	return &deps, nil
+}
+
+func (s *TaskDependencyServiceImpl) RemoveDependency(db *gorm.DB, taskID, blockedByID uuid.UUID) error {
+	result := db.Where("task_id = ? AND blocked_by_id = ?", taskID, blockedByID).Delete(&models.TaskDependency{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// waitsOn reports whether completing from transitively waits on target, either
+// because target blocks it or because target is one of its subtasks
+func waitsOn(db *gorm.DB, from, target uuid.UUID) (bool, error) {
+	if from == target {
+		return true, nil
+	}
+
+	visited := map[uuid.UUID]bool{from: true}
+	frontier := []uuid.UUID{from}
+	for len(frontier) > 0 {
+		var blockers, subtasks []uuid.UUID
+		if err := db.Model(&models.TaskDependency{}).Where("task_id IN ?", frontier).Pluck("blocked_by_id", &blockers).Error; err != nil {
+			return false, err
+		}
+		if err := db.Model(&models.Task{}).Where("parent_id IN ?", frontier).Pluck("id", &subtasks).Error; err != nil {
+			return false, err
+		}
+
+		var next []uuid.UUID
+		for _, id := range append(blockers, subtasks...) {
+			if id == target {
+				return true, nil
+			}
+			if !visited[id] {
+				visited[id] = true
+				next = append(next, id)
+			}
+		}
+		frontier = next
+	}
+	return false, nil
+}
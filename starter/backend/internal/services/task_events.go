@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"task-manager/backend/internal/events"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// publishTaskEvent announces a committed change to a task. Streams are best
+// effort, so a failure is logged and the change stands.
+func publishTaskEvent(ctx context.Context, broker events.Broker, eventType string, task *models.Task, shares []events.ShareRef) {
+	if broker == nil || task == nil {
+		return
+	}
+	id, err := uuid.NewV4()
+	if err != nil {
+		slog.ErrorContext(ctx, "generating task event ID failed", "error", err)
+		return
+	}
+	event := events.Event{ID: id, Type: eventType, TenantID: task.TenantID, TaskID: task.ID, Shares: shares, At: time.Now()}
+	publish := func() {
+		if err := broker.Publish(ctx, event); err != nil {
+			slog.ErrorContext(ctx, "publishing task event failed", "event", eventType, "task_id", task.ID, "error", err)
+		}
+	}
+	if held, ok := ctx.Value(heldEventsKey{}).(*heldEvents); ok {
+		held.add(publish)
+		return
+	}
+	publish()
+}
+
+type heldEventsKey struct{}
+
+type heldEvents struct {
+	mu      sync.Mutex
+	publish []func()
+}
+
+func (h *heldEvents) add(publish func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publish = append(h.publish, publish)
+}
+
+// HoldTaskEvents returns a context under which task events are held back
+// instead of published, for changes made inside a transaction the caller
+// opened: the services only see their own, nested, transaction commit. Once
+// the outer transaction is done, release publishes the held events when it
+// committed and drops them when it rolled back.
+func HoldTaskEvents(ctx context.Context) (context.Context, func(committed bool)) {
+	held := &heldEvents{}
+	release := func(committed bool) {
+		held.mu.Lock()
+		publish := held.publish
+		held.publish = nil
+		held.mu.Unlock()
+		if committed {
+			for _, p := range publish {
+				p()
+			}
+		}
+	}
+	return context.WithValue(ctx, heldEventsKey{}, held), release
+}
+
+// shareRefs lists the subjects a task is shared with, for the deletion event
+func shareRefs(db *gorm.DB, taskID uuid.UUID) ([]events.ShareRef, error) {
+	var shares []models.TaskShare
+	if err := db.Where("task_id = ?", taskID).Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	refs := make([]events.ShareRef, 0, len(shares))
+	for _, share := range shares {
+		refs = append(refs, events.ShareRef{Type: share.SubjectType, ID: share.SubjectID})
+	}
+	return refs, nil
+}
+
+// VisibleTaskEvent returns the task an event is about when the actor may see
+// it. Created and updated tasks are loaded as they are now; a deleted task is
+// judged as it was, including the shares it had before deletion removed them.
+func VisibleTaskEvent(db *gorm.DB, taskService TaskService, event events.Event, actor Actor) (*models.Task, bool, error) {
+	var task *models.Task
+	if event.Type == events.TaskDeleted {
+		var deleted models.Task
+		if err := db.Unscoped().First(&deleted, "id = ?", event.TaskID).Error; err != nil {
+			return nil, false, ignoreNotFound(err)
+		}
+		task = &deleted
+	} else {
+		found, err := taskService.GetTaskByID(db, event.TaskID)
+		if err != nil {
+			return nil, false, ignoreNotFound(err)
+		}
+		task = found
+	}
+
+	level, err := taskService.GetTaskAccess(db, task, actor)
+	if err != nil {
+		return nil, false, err
+	}
+	if level != models.TaskAccessNone {
+		return task, true, nil
+	}
+	if event.Type != events.TaskDeleted {
+		return nil, false, nil
+	}
+	granted, err := sharesGrantAccess(db, event.Shares, actor)
+	return task, granted, err
+}
+
+// sharesGrantAccess reports whether any of the shares covers the actor
+func sharesGrantAccess(db *gorm.DB, shares []events.ShareRef, actor Actor) (bool, error) {
+	for _, share := range shares {
+		switch share.Type {
+		case models.ShareSubjectUser:
+			if share.ID == actor.UserID {
+				return true, nil
+			}
+		case models.ShareSubjectTeam:
+			_, err := TeamMemberRole(db, share.ID, actor.UserID)
+			if err == nil {
+				return true, nil
+			}
+			if !errors.Is(err, ErrNotTeamMember) {
+				return false, err
+			}
+		case models.ShareSubjectRole:
+			var role models.Role
+			if err := db.Select("name").First(&role, "id = ?", share.ID).Error; err != nil {
+				if err := ignoreNotFound(err); err != nil {
+					return false, err
+				}
+				continue
+			}
+			if actor.HasRole(role.Name) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func ignoreNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
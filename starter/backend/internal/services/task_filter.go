@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaskFilter narrows a task listing. Zero-valued fields are ignored.
+type TaskFilter struct {
+	Status    string
+	Priority  string
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	Search    string
+	Labels    []string
+	LabelMode string
+}
+
+// Ways of matching the Labels of a TaskFilter
+const (
+	LabelMatchAny = "any"
+	LabelMatchAll = "all"
+)
+
+// Scope applies the filter to a task query
+func (f TaskFilter) Scope(query *gorm.DB) *gorm.DB {
+	if f.Status != "" {
+		query = query.Where("status = ?", f.Status)
+	}
+	if f.Priority != "" {
+		query = query.Where("priority = ?", f.Priority)
+	}
+	if f.DueBefore != nil {
+		query = query.Where("due_date < ?", *f.DueBefore)
+	}
+	if f.DueAfter != nil {
+		query = query.Where("due_date >= ?", *f.DueAfter)
+	}
+	if f.Search != "" {
+		pattern := "%" + f.Search + "%"
+		query = query.Where("LOWER(title) LIKE LOWER(?) OR LOWER(description) LIKE LOWER(?)", pattern, pattern)
+	}
+	if len(f.Labels) > 0 {
+		query = query.Where("id IN (?)", f.labelledTaskIDs(query.Session(&gorm.Session{NewDB: true})))
+	}
+	return query
+}
+
+// labelledTaskIDs is a subquery selecting the tasks carrying any or, with
+// LabelMatchAll, all of the filter's label names. Names are matched without
+// regard to case and across every label owner, since a task shows all of its
+// labels to anyone who can see it.
+func (f TaskFilter) labelledTaskIDs(db *gorm.DB) *gorm.DB {
+	names := make([]string, len(f.Labels))
+	for i, name := range f.Labels {
+		names[i] = strings.ToLower(name)
+	}
+
+	query := db.Model(&models.TaskLabel{}).
+		Select("task_labels.task_id").
+		Joins("JOIN labels ON labels.id = task_labels.label_id").
+		Where("LOWER(labels.name) IN ?", names)
+	if f.LabelMode == LabelMatchAll {
+		query = query.Group("task_labels.task_id").Having("COUNT(DISTINCT LOWER(labels.name)) = ?", len(names))
+	}
+	return query
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var ErrRevisionNotFound = apperr.New(apperr.KindNotFound, "revision_not_found", "revision not found")
+
+// GetTaskHistory lists the task's revisions, newest first and paginated
+func (s *TaskServiceImpl) GetTaskHistory(db *gorm.DB, taskID uuid.UUID, page Page) ([]models.TaskRevision, int64, error) {
+	query := db.Model(&models.TaskRevision{}).Where("task_id = ?", taskID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	revisions := []models.TaskRevision{}
+	err := query.Order("revision DESC").Offset(page.Offset()).Limit(page.Size).Find(&revisions).Error
+	return revisions, total, err
+}
+
+// RevertTask restores the title, description, status, priority and due date
+// the task had at the given revision. It goes through UpdateTask, so the
+// workflow and completion rules apply as they would to the same edit made by
+// hand, and the revert is itself recorded as a new revision. Owner, team,
+// project and parent are left as they are.
+func (s *TaskServiceImpl) RevertTask(db *gorm.DB, taskID uuid.UUID, revision int, opts UpdateOptions) error {
+	var target models.TaskRevision
+	if err := db.Where("task_id = ? AND revision = ?", taskID, revision).First(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRevisionNotFound
+		}
+		return err
+	}
+	var past models.Task
+	if err := json.Unmarshal([]byte(target.Snapshot), &past); err != nil {
+		return err
+	}
+
+	restored := models.Task{
+		Title:       past.Title,
+		Description: past.Description,
+		Status:      past.Status,
+		Priority:    past.Priority,
+		DueDate:     past.DueDate,
+	}
+	opts.Fields = []string{"title", "description", "status", "priority", "due_date"}
+	opts.revertedFrom = revision
+	return s.UpdateTask(db, taskID, &restored, opts)
+}
+
+// recordTaskRevision adds the next revision of the task with the fields that
+// changed since before. Updates that change nothing add no revision. Callers
+// have already written the task row, which holds its lock until commit and so
+// keeps concurrent changes from taking the same revision number.
+func recordTaskRevision(tx *gorm.DB, action string, before, current *models.Task) error {
+	var changes map[string]models.FieldChange
+	var err error
+	if before == nil {
+		changes, err = auditChanges(nil, current)
+	} else {
+		changes, err = auditChanges(before, current)
+	}
+	if err != nil {
+		return err
+	}
+	if before != nil && len(changes) == 0 {
+		return nil
+	}
+
+	snapshot, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	latest, err := lastRevision(tx, current.ID)
+	if err != nil {
+		return err
+	}
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.TaskRevision{
+		ID:        id,
+		TenantID:  current.TenantID,
+		TaskID:    current.ID,
+		Revision:  latest + 1,
+		ActorID:   RequestInfoFromContext(tx.Statement.Context).ActorID,
+		Action:    action,
+		Changes:   changes,
+		Snapshot:  string(snapshot),
+		CreatedAt: time.Now(),
+	}).Error
+}
+
+// lastRevision returns the task's newest revision number, or 0 when it has none
+func lastRevision(tx *gorm.DB, taskID uuid.UUID) (int, error) {
+	var latest *int
+	err := tx.Model(&models.TaskRevision{}).Where("task_id = ?", taskID).Select("MAX(revision)").Scan(&latest).Error
+	if err != nil || latest == nil {
+		return 0, err
+	}
+	return *latest, nil
+}
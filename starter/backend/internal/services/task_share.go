@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidShareSubject    = apperr.Invalid("subject_type", "invalid_subject_type", "subject_type must be user, role or team")
+	ErrInvalidSharePermission = apperr.Invalid("permission", "invalid_permission", "permission must be viewer, editor or owner")
+	ErrShareSubjectNotFound   = apperr.New(apperr.KindNotFound, "share_subject_not_found", "share subject not found")
+)
+
+type TaskShareService interface {
+	ShareTask(db *gorm.DB, share *models.TaskShare) error
+	GetShares(db *gorm.DB, taskID uuid.UUID) ([]models.TaskShare, error)
+	RevokeShare(db *gorm.DB, taskID, shareID uuid.UUID) error
+}
+
+type TaskShareServiceImpl struct {
+	notifications NotificationPublisher
+}
+
+func NewTaskShareService() *TaskShareServiceImpl {
+	return &TaskShareServiceImpl{notifications: NewNotificationService()}
+}
+
+// ShareTask grants a subject access to a task. Granting again to the same
+// subject replaces the previous permission level. Users gaining access, directly
+// or through a team, are notified; role shares are not announced.
+func (s *TaskShareServiceImpl) ShareTask(db *gorm.DB, share *models.TaskShare) error {
+	if !models.ValidTaskAccess(share.Permission) {
+		return ErrInvalidSharePermission
+	}
+
+	var subject interface{}
+	switch share.SubjectType {
+	case models.ShareSubjectUser:
+		subject = &models.User{}
+	case models.ShareSubjectRole:
+		subject = &models.Role{}
+	case models.ShareSubjectTeam:
+		subject = &models.Team{}
+	default:
+		return ErrInvalidShareSubject
+	}
+	if err := db.Where("id = ?", share.SubjectID).First(subject).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrShareSubjectNotFound
+		}
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing models.TaskShare
+		err := tx.Where("task_id = ? AND subject_type = ? AND subject_id = ?", share.TaskID, share.SubjectType, share.SubjectID).
+			First(&existing).Error
+		if err == nil {
+			share.ID = existing.ID
+			share.CreatedAt = existing.CreatedAt
+			previous := existing.Permission
+			err := tx.Model(&existing).Updates(map[string]interface{}{
+				"permission": share.Permission,
+				"granted_by": share.GrantedBy,
+			}).Error
+			if err != nil || previous == share.Permission {
+				return err
+			}
+			return s.announceShare(tx, share)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		id, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+		share.ID = id
+		if err := tx.Create(share).Error; err != nil {
+			return err
+		}
+		return s.announceShare(tx, share)
+	})
+}
+
+// announceShare notifies the users a share gives access to
+func (s *TaskShareServiceImpl) announceShare(tx *gorm.DB, share *models.TaskShare) error {
+	var recipients []uuid.UUID
+	switch share.SubjectType {
+	case models.ShareSubjectUser:
+		recipients = []uuid.UUID{share.SubjectID}
+	case models.ShareSubjectTeam:
+		if err := tx.Model(&models.TeamMember{}).Where("team_id = ?", share.SubjectID).Pluck("user_id", &recipients).Error; err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	var task models.Task
+	if err := tx.Select("id", "tenant_id", "title").First(&task, "id = ?", share.TaskID).Error; err != nil {
+		return err
+	}
+	for _, userID := range recipients {
+		err := s.notifications.Publish(tx, NotificationEvent{
+			Type:     models.NotificationTaskShared,
+			TenantID: task.TenantID,
+			UserID:   userID,
+			ActorID:  share.GrantedBy,
+			TaskID:   &task.ID,
+			Title:    "Task shared with you: " + task.Title,
+			Body:     "You have " + share.Permission + " access.",
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TaskShareServiceImpl) GetShares(db *gorm.DB, taskID uuid.UUID) ([]models.TaskShare, error) {
+	var shares []models.TaskShare
+	if err := db.Where("task_id = ?", taskID).Order("created_at").Find(&shares).Error; err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+func (s *TaskShareServiceImpl) RevokeShare(db *gorm.DB, taskID, shareID uuid.UUID) error {
+	result := db.Where("task_id = ? AND id = ?", taskID, shareID).Delete(&models.TaskShare{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"task-manager/backend/internal/events"
+	"task-manager/backend/internal/models"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// transferBatchSize is how many tasks an export reads, and an import inserts,
+// per statement
+const transferBatchSize = 500
+
+// ImportRow is one task read from an import file, with the problems found
+// while reading it. A row with a problem that names no field could not be
+// read at all, and is not validated further.
+type ImportRow struct {
+	// Line is where the task is in the file: the CSV record or JSON element, from 1
+	Line   int
+	Task   models.Task
+	Errors []ImportError
+}
+
+// ImportError is a problem with one row of an import
+type ImportError struct {
+	Line  int    `json:"line"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportReport says how an import went or, for a dry run, would go
+type ImportReport struct {
+	DryRun  bool          `json:"dry_run"`
+	Total   int           `json:"total"`
+	Valid   int           `json:"valid"`
+	Created int           `json:"created"`
+	Errors  []ImportError `json:"errors"`
+}
+
+type TaskTransferService interface {
+	ExportTasks(db *gorm.DB, scope func(*gorm.DB) *gorm.DB, write func(*models.Task) error) error
+	ImportTasks(db *gorm.DB, rows []ImportRow, dryRun bool) (*ImportReport, error)
+}
+
+// TaskTransferServiceImpl exports and imports tasks. Imported tasks are
+// announced through the task service's webhooks and events, like tasks
+// created one by one.
+type TaskTransferServiceImpl struct {
+	tasks *TaskServiceImpl
+}
+
+func NewTaskTransferService(taskService *TaskServiceImpl) *TaskTransferServiceImpl {
+	return &TaskTransferServiceImpl{tasks: taskService}
+}
+
+// ExportTasks calls write with each task the scope selects, labels included.
+// Tasks are read in batches, so an export of any size holds only one batch in
+// memory; an error from write stops it.
+func (s *TaskTransferServiceImpl) ExportTasks(db *gorm.DB, scope func(*gorm.DB) *gorm.DB, write func(*models.Task) error) error {
+	var batch []models.Task
+	return db.Model(&models.Task{}).Scopes(withLabels, scope).FindInBatches(&batch, transferBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if err := write(&batch[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// ImportTasks validates the rows and, unless this is a dry run or any row is
+// invalid, creates their tasks in one transaction, inserting them in batches.
+// Imported tasks get the status and priority defaults and the owner's Inbox
+// project that CreateTask gives new tasks.
+func (s *TaskTransferServiceImpl) ImportTasks(db *gorm.DB, rows []ImportRow, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{DryRun: dryRun, Total: len(rows), Errors: []ImportError{}}
+
+	owners := map[uuid.UUID]*uuid.UUID{}
+	for i := range rows {
+		owners[rows[i].Task.UserID] = nil
+	}
+	ownerIDs := make([]uuid.UUID, 0, len(owners))
+	for id := range owners {
+		ownerIDs = append(ownerIDs, id)
+	}
+	var known []uuid.UUID
+	if err := db.Model(&models.User{}).Where("id IN ?", ownerIDs).Pluck("id", &known).Error; err != nil {
+		return nil, err
+	}
+	knownOwners := map[uuid.UUID]bool{}
+	for _, id := range known {
+		knownOwners[id] = true
+	}
+
+	now := time.Now()
+	tasks := make([]models.Task, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		if unreadable(row) {
+			report.Errors = append(report.Errors, row.Errors...)
+			continue
+		}
+		errs := append([]ImportError{}, row.Errors...)
+		task := row.Task
+		if task.Title == "" {
+			errs = append(errs, ImportError{Line: row.Line, Field: "title", Error: "title is required"})
+		}
+		if task.Status == "" {
+			task.Status = models.TaskStatusPending
+		}
+		if task.Priority == "" {
+			task.Priority = models.TaskPriorityMedium
+		}
+		if !models.ValidTaskStatus(task.Status) {
+			errs = append(errs, ImportError{Line: row.Line, Field: "status", Error: ErrInvalidStatus.Error()})
+		}
+		if !models.ValidTaskPriority(task.Priority) {
+			errs = append(errs, ImportError{Line: row.Line, Field: "priority", Error: ErrInvalidPriority.Error()})
+		}
+		if !knownOwners[task.UserID] {
+			errs = append(errs, ImportError{Line: row.Line, Field: "user_id", Error: "user not found"})
+		}
+		if len(errs) > 0 {
+			report.Errors = append(report.Errors, errs...)
+			continue
+		}
+
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		task.ID, task.Version = id, 1
+		task.StartedAt, task.CompletedAt = nil, nil
+		stampStatusTimes(&task, nil, now)
+		tasks = append(tasks, task)
+	}
+	report.Valid = len(tasks)
+	if dryRun || len(report.Errors) > 0 || len(tasks) == 0 {
+		return report, nil
+	}
+
+	for owner := range owners {
+		if !knownOwners[owner] {
+			continue
+		}
+		inboxID, err := inboxProjectID(db, owner)
+		if err != nil {
+			return nil, err
+		}
+		owners[owner] = inboxID
+	}
+	for i := range tasks {
+		tasks[i].ProjectID = owners[tasks[i].UserID]
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Labels").CreateInBatches(tasks, transferBatchSize).Error; err != nil {
+			return err
+		}
+		for i := range tasks {
+			if err := recordTaskEvent(tx, s.tasks.webhooks, models.EventTaskCreated, nil, &tasks[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		publishTaskEvent(db.Statement.Context, s.tasks.events, events.TaskCreated, &tasks[i], nil)
+	}
+	report.Created = len(tasks)
+	return report, nil
+}
+
+func unreadable(row *ImportRow) bool {
+	for _, problem := range row.Errors {
+		if problem.Field == "" {
+			return true
+		}
+	}
+	return false
+}
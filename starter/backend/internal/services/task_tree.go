@@ -0,0 +1,68 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskTree is a task together with all of its descendants. Progress is the
+// share of the task's work that is done, from 0 to 100: a completed task is
+// at 100, an open task without subtasks at 0, and any other task averages the
+// progress of its subtasks. Cancelled subtasks are left out of the average.
+type TaskTree struct {
+	models.Task
+	Progress int         `json:"progress"`
+	Subtasks []*TaskTree `json:"subtasks"`
+}
+
+// buildTaskTree loads every descendant of root, one level at a time
+func buildTaskTree(db *gorm.DB, root *models.Task) (*TaskTree, error) {
+	tree := &TaskTree{Task: *root, Subtasks: []*TaskTree{}}
+	nodes := map[uuid.UUID]*TaskTree{root.ID: tree}
+	frontier := []uuid.UUID{root.ID}
+
+	for len(frontier) > 0 {
+		var children []models.Task
+		if err := db.Where("parent_id IN ?", frontier).Order("created_at").Find(&children).Error; err != nil {
+			return nil, err
+		}
+
+		frontier = frontier[:0]
+		for _, child := range children {
+			if _, seen := nodes[child.ID]; seen {
+				continue
+			}
+			node := &TaskTree{Task: child, Subtasks: []*TaskTree{}}
+			parent := nodes[*child.ParentID]
+			parent.Subtasks = append(parent.Subtasks, node)
+			nodes[child.ID] = node
+			frontier = append(frontier, child.ID)
+		}
+	}
+
+	tree.rollUp()
+	return tree, nil
+}
+
+func (t *TaskTree) rollUp() int {
+	total, counted := 0, 0
+	for _, subtask := range t.Subtasks {
+		progress := subtask.rollUp()
+		if subtask.Status != models.TaskStatusCancelled {
+			total += progress
+			counted++
+		}
+	}
+
+	switch {
+	case t.Status == models.TaskStatusCompleted:
+		t.Progress = 100
+	case counted == 0:
+		t.Progress = 0
+	default:
+		t.Progress = total / counted
+	}
+	return t.Progress
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidTeamRole = apperr.Invalid("role", "invalid_team_role", "role must be admin or member")
+	ErrLastTeamAdmin   = apperr.New(apperr.KindConflict, "last_team_admin", "a team must keep at least one admin")
+	ErrNotTeamMember   = apperr.New(apperr.KindNotFound, "not_team_member", "user is not a member of this team")
+)
+
+type TeamService interface {
+	CreateTeam(db *gorm.DB, team *models.Team, creatorID uuid.UUID) error
+	GetTeamByID(db *gorm.DB, teamID uuid.UUID) (*models.Team, error)
+	GetTeams(db *gorm.DB, actor Actor) ([]models.Team, error)
+	UpdateTeam(db *gorm.DB, teamID uuid.UUID, team *models.Team) error
+	DeleteTeam(db *gorm.DB, teamID uuid.UUID) error
+	GetMembers(db *gorm.DB, teamID uuid.UUID) ([]models.TeamMember, error)
+	SetMember(db *gorm.DB, teamID, userID uuid.UUID, role string) (*models.TeamMember, error)
+	RemoveMember(db *gorm.DB, teamID, userID uuid.UUID) error
+	GetMemberRole(db *gorm.DB, teamID, userID uuid.UUID) (string, error)
+	GetTeamTasks(db *gorm.DB, teamID uuid.UUID, filter TaskFilter) ([]models.Task, error)
+}
+
+type TeamServiceImpl struct {
+	notifications NotificationPublisher
+}
+
+func NewTeamService() *TeamServiceImpl {
+	return &TeamServiceImpl{notifications: NewNotificationService()}
+}
+
+// CreateTeam stores a new team and makes its creator the first team admin
+func (s *TeamServiceImpl) CreateTeam(db *gorm.DB, team *models.Team, creatorID uuid.UUID) error {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	team.ID = id
+	team.CreatedBy = creatorID
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Create(team).Error; err != nil {
+			return err
+		}
+		member := models.TeamMember{TeamID: team.ID, UserID: creatorID, Role: models.TeamRoleAdmin}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		team.Members = []models.TeamMember{member}
+		return nil
+	})
+}
+
+func (s *TeamServiceImpl) GetTeamByID(db *gorm.DB, teamID uuid.UUID) (*models.Team, error) {
+	var team models.Team
+	if err := db.Preload("Members").First(&team, "id = ?", teamID).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// GetTeams returns every team for admins and the actor's own teams for everyone else
+func (s *TeamServiceImpl) GetTeams(db *gorm.DB, actor Actor) ([]models.Team, error) {
+	var teams []models.Team
+	query := db.Order("name")
+	if !actor.IsAdmin() {
+		query = query.Where("id IN (?)", memberTeamIDs(db, actor.UserID))
+	}
+	if err := query.Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
+func (s *TeamServiceImpl) UpdateTeam(db *gorm.DB, teamID uuid.UUID, team *models.Team) error {
+	result := db.Model(&models.Team{}).Where("id = ?", teamID).
+		Select("name", "description").
+		Updates(map[string]interface{}{"name": team.Name, "description": team.Description})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteTeam removes the team and its memberships. Tasks the team owned fall
+// back to being owned by the user who created them.
+func (s *TeamServiceImpl) DeleteTeam(db *gorm.DB, teamID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Team{}, "id = ?", teamID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Where("team_id = ?", teamID).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("subject_type = ? AND subject_id = ?", models.ShareSubjectTeam, teamID).Delete(&models.TaskShare{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Task{}).Where("team_id = ?", teamID).
+			Updates(map[string]interface{}{"team_id": nil, "version": nextVersion}).Error
+	})
+}
+
+func (s *TeamServiceImpl) GetMembers(db *gorm.DB, teamID uuid.UUID) ([]models.TeamMember, error) {
+	var members []models.TeamMember
+	if err := db.Where("team_id = ?", teamID).Order("created_at").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// SetMember adds a user to the team or changes their team role, and tells the
+// user about their new role
+func (s *TeamServiceImpl) SetMember(db *gorm.DB, teamID, userID uuid.UUID, role string) (*models.TeamMember, error) {
+	if !models.ValidTeamRole(role) {
+		return nil, ErrInvalidTeamRole
+	}
+
+	var member models.TeamMember
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			member = models.TeamMember{TeamID: teamID, UserID: userID, Role: role}
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+			return s.announceRole(tx, teamID, userID, "", role)
+		}
+		if err != nil {
+			return err
+		}
+
+		if member.Role == models.TeamRoleAdmin && role != models.TeamRoleAdmin {
+			if err := ensureOtherTeamAdmin(tx, teamID, userID); err != nil {
+				return err
+			}
+		}
+		previous := member.Role
+		member.Role = role
+		if err := tx.Model(&member).Where("team_id = ? AND user_id = ?", teamID, userID).Update("role", role).Error; err != nil {
+			return err
+		}
+		if previous == role {
+			return nil
+		}
+		return s.announceRole(tx, teamID, userID, previous, role)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (s *TeamServiceImpl) announceRole(tx *gorm.DB, teamID, userID uuid.UUID, previous, role string) error {
+	var team models.Team
+	if err := tx.Select("id", "tenant_id", "name").First(&team, "id = ?", teamID).Error; err != nil {
+		return err
+	}
+	change := roleChange{UserID: userID, Scope: roleScopeTeam, ScopeID: teamID, Role: role}
+	if err := recordRoleChange(tx, team.TenantID, change, previous); err != nil {
+		return err
+	}
+	return s.notifications.Publish(tx, NotificationEvent{
+		Type:     models.NotificationRoleChanged,
+		TenantID: team.TenantID,
+		UserID:   userID,
+		Title:    "Your role in team " + team.Name + " is now " + role,
+	})
+}
+
+func (s *TeamServiceImpl) RemoveMember(db *gorm.DB, teamID, userID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var member models.TeamMember
+		if err := tx.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotTeamMember
+			}
+			return err
+		}
+		if member.Role == models.TeamRoleAdmin {
+			if err := ensureOtherTeamAdmin(tx, teamID, userID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		return recordRoleChange(tx, member.TenantID, roleChange{UserID: userID, Scope: roleScopeTeam, ScopeID: teamID}, member.Role)
+	})
+}
+
+// GetMemberRole returns the user's role in the team, or ErrNotTeamMember
+func (s *TeamServiceImpl) GetMemberRole(db *gorm.DB, teamID, userID uuid.UUID) (string, error) {
+	return TeamMemberRole(db, teamID, userID)
+}
+
+func (s *TeamServiceImpl) GetTeamTasks(db *gorm.DB, teamID uuid.UUID, filter TaskFilter) ([]models.Task, error) {
+	var tasks []models.Task
+	if err := db.Scopes(filter.Scope, withLabels).Where("team_id = ?", teamID).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// TeamMemberRole looks up the role a user holds in a team, or ErrNotTeamMember
+func TeamMemberRole(db *gorm.DB, teamID, userID uuid.UUID) (string, error) {
+	var member models.TeamMember
+	if err := db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrNotTeamMember
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// memberTeamIDs is a subquery selecting the IDs of every team the user belongs to
+func memberTeamIDs(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).
+		Model(&models.TeamMember{}).
+		Select("team_id").
+		Where("user_id = ?", userID)
+}
+
+func ensureOtherTeamAdmin(tx *gorm.DB, teamID, userID uuid.UUID) error {
+	var admins int64
+	err := tx.Model(&models.TeamMember{}).
+		Where("team_id = ? AND role = ? AND user_id <> ?", teamID, models.TeamRoleAdmin, userID).
+		Count(&admins).Error
+	if err != nil {
+		return err
+	}
+	if admins == 0 {
+		return ErrLastTeamAdmin
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"task-manager/backend/internal/models"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+type TenantService interface {
+	CreateTenant(db *gorm.DB, tenant *models.Tenant) error
+	GetTenantByID(db *gorm.DB, tenantID uuid.UUID) (*models.Tenant, error)
+}
+
+type TenantServiceImpl struct{}
+
+func NewTenantService() *TenantServiceImpl {
+	return &TenantServiceImpl{}
+}
+
+// CreateTenant provisions a tenant together with its own admin and user roles.
+// Admins get every permission, users get the task permissions, mirroring the
+// default tenant seeded by the migrations.
+func (s *TenantServiceImpl) CreateTenant(db *gorm.DB, tenant *models.Tenant) error {
+	if tenant.ID == uuid.Nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+		tenant.ID = id
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tenant).Error; err != nil {
+			return err
+		}
+
+		var permissions []models.Permission
+		if err := tx.Find(&permissions).Error; err != nil {
+			return err
+		}
+		var taskPermissions []models.Permission
+		for _, permission := range permissions {
+			if permission.Resource == "tasks" {
+				taskPermissions = append(taskPermissions, permission)
+			}
+		}
+
+		roles := []models.Role{
+			{ID: uuid.Must(uuid.NewV4()), TenantID: tenant.ID, Name: "admin", Permissions: permissions},
+			{ID: uuid.Must(uuid.NewV4()), TenantID: tenant.ID, Name: "user", Permissions: taskPermissions},
+		}
+		return tx.Create(&roles).Error
+	})
+}
+
+func (s *TenantServiceImpl) GetTenantByID(db *gorm.DB, tenantID uuid.UUID) (*models.Tenant, error) {
+	var tenant models.Tenant
+	if err := db.First(&tenant, "id = ?", tenantID).Error; err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
@@ -14,10 +14,11 @@ type UserService interface {
 }
 
 type UserServiceImpl struct {
+	webhooks WebhookPublisher
 }
 
 func NewUserService() *UserServiceImpl {
-	return &UserServiceImpl{}
+	return &UserServiceImpl{webhooks: NewWebhookService()}
 }
 
 func (s *UserServiceImpl) GetUserProfile(db *gorm.DB, userID uuid.UUID) (models.User, error) {
@@ -43,9 +44,24 @@ func (s *UserServiceImpl) GetUsers(db *gorm.DB) ([]models.User, error) {
 }
 
 func (s *UserServiceImpl) DeleteUser(db *gorm.DB, userId uuid.UUID) error {
-	result := db.Delete(&models.User{}, "id = ?", userId)
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
-	return result.Error
+	return db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("id", "tenant_id", "username", "email").First(&user, "id = ?", userId).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.User{}, "id = ?", userId)
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := recordUserEvent(tx, s.webhooks, models.EventUserDeleted, user.ID); err != nil {
+			return err
+		}
+		return recordAudit(tx, auditRecord{
+			TenantID:   user.TenantID,
+			Action:     models.AuditUserDeleted,
+			TargetType: models.AggregateUser,
+			TargetID:   &user.ID,
+			Before:     webhookUser{ID: user.ID, Username: user.Username, Email: user.Email},
+		})
+	})
 }
@@ -0,0 +1,490 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/notify"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidWebhookEndpoint = apperr.Invalid("url", "invalid_url", "url must be an http or https URL")
+	ErrInvalidWebhookEvents   = apperr.Invalid("events", "invalid_events", "events must list at least one known event type")
+	ErrInvalidWebhookScope    = apperr.Invalid("scope", "invalid_scope", "scope must be user or tenant")
+)
+
+const (
+	// maxWebhookAttempts is how often a delivery is tried before it is given up
+	maxWebhookAttempts = 8
+	// The first retry waits webhookBackoff; every further one waits twice as
+	// long as the one before, up to maxWebhookBackoff
+	webhookBackoff    = 30 * time.Second
+	maxWebhookBackoff = time.Hour
+	// webhookBatch caps how many deliveries one pass of the worker sends
+	webhookBatch = 100
+	// maxWebhookResponse is how much of a response body the delivery log keeps
+	maxWebhookResponse = 1024
+
+	webhookLease = "webhooks"
+)
+
+// WebhookEvent is a change webhooks can subscribe to. Task events name the
+// task in TaskID, user events the user in UserID.
+type WebhookEvent struct {
+	Type     string
+	TenantID uuid.UUID
+	TaskID   *uuid.UUID
+	UserID   *uuid.UUID
+}
+
+// WebhookPublisher queues events for the webhooks subscribed to them. Queueing
+// with the transaction that made the change means an event is delivered if and
+// only if the change commits.
+type WebhookPublisher interface {
+	Enqueue(db *gorm.DB, event WebhookEvent) error
+}
+
+type WebhookService interface {
+	WebhookPublisher
+	CreateWebhook(db *gorm.DB, webhook *models.Webhook) error
+	GetWebhooks(db *gorm.DB, actor Actor) ([]models.Webhook, error)
+	GetWebhook(db *gorm.DB, webhookID uuid.UUID) (*models.Webhook, error)
+	UpdateWebhook(db *gorm.DB, webhook *models.Webhook) error
+	DeleteWebhook(db *gorm.DB, webhookID uuid.UUID) error
+	GetDeliveries(db *gorm.DB, webhookID uuid.UUID, page Page) ([]models.WebhookDelivery, int64, error)
+	Redeliver(db *gorm.DB, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	DeliverDue(db *gorm.DB, now time.Time) (int, error)
+	Run(ctx context.Context, db *gorm.DB, interval time.Duration)
+}
+
+type WebhookServiceImpl struct {
+	client *http.Client
+	holder string
+}
+
+// NewWebhookService sends deliveries only to public addresses and does not
+// follow redirects, since any user can choose where their webhook points
+func NewWebhookService() *WebhookServiceImpl {
+	return &WebhookServiceImpl{client: notify.NewPublicClient(10 * time.Second), holder: leaseHolder()}
+}
+
+// WithClient makes the service send deliveries with client. The client is
+// trusted as given: use notify.NewPublicClient unless receivers are trusted.
+func (s *WebhookServiceImpl) WithClient(client *http.Client) *WebhookServiceImpl {
+	s.client = client
+	return s
+}
+
+// webhookPayload is the body of every delivery. ID identifies the event and
+// stays the same across retries and redeliveries, so receivers can use it to
+// drop duplicates.
+type webhookPayload struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	TenantID  uuid.UUID   `json:"tenant_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// webhookUser is what user events tell about the user
+type webhookUser struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+}
+
+// CreateWebhook validates the webhook and stores it with a new signing secret
+func (s *WebhookServiceImpl) CreateWebhook(db *gorm.DB, webhook *models.Webhook) error {
+	if webhook.Scope == "" {
+		webhook.Scope = models.WebhookScopeUser
+	}
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	webhook.ID = id
+	webhook.Secret = hex.EncodeToString(secret)
+	webhook.Active = true
+	return db.Create(webhook).Error
+}
+
+// GetWebhooks lists the actor's webhooks, or every webhook for admins
+func (s *WebhookServiceImpl) GetWebhooks(db *gorm.DB, actor Actor) ([]models.Webhook, error) {
+	query := db.Order("created_at, id")
+	if !actor.IsAdmin() {
+		query = query.Where("user_id = ?", actor.UserID)
+	}
+	webhooks := []models.Webhook{}
+	if err := query.Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (s *WebhookServiceImpl) GetWebhook(db *gorm.DB, webhookID uuid.UUID) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := db.First(&webhook, "id = ?", webhookID).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// UpdateWebhook saves the URL, events and active flag of the webhook
+func (s *WebhookServiceImpl) UpdateWebhook(db *gorm.DB, webhook *models.Webhook) error {
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+	return db.Model(webhook).Select("url", "events", "active", "updated_at").Updates(webhook).Error
+}
+
+// DeleteWebhook removes the webhook with its delivery log; pending deliveries
+// are dropped
+func (s *WebhookServiceImpl) DeleteWebhook(db *gorm.DB, webhookID uuid.UUID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhookID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Webhook{}, "id = ?", webhookID).Error
+	})
+}
+
+// GetDeliveries returns one page of the webhook's deliveries, newest first,
+// and the total count
+func (s *WebhookServiceImpl) GetDeliveries(db *gorm.DB, webhookID uuid.UUID, page Page) ([]models.WebhookDelivery, int64, error) {
+	query := db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	deliveries := []models.WebhookDelivery{}
+	err := query.Order("created_at DESC, id").Offset(page.Offset()).Limit(page.Size).Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// Redeliver queues the payload of an earlier delivery again, as a new delivery
+// that is sent on the worker's next pass
+func (s *WebhookServiceImpl) Redeliver(db *gorm.DB, webhookID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	var original models.WebhookDelivery
+	if err := db.First(&original, "id = ? AND webhook_id = ?", deliveryID, webhookID).Error; err != nil {
+		return nil, err
+	}
+	delivery, err := newWebhookDelivery(original.TenantID, webhookID, original.EventID, original.EventType, original.Payload, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	delivery.RedeliveryOf = &original.ID
+	if err := db.Create(delivery).Error; err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Enqueue queues a delivery of the event for every active webhook of the
+// tenant that subscribes to it and whose owner may see it. The payload is
+// taken now, so retries send the change as it was made.
+func (s *WebhookServiceImpl) Enqueue(db *gorm.DB, event WebhookEvent) error {
+	var webhooks []models.Webhook
+	if err := db.Where("tenant_id = ? AND active = ?", event.TenantID, true).Find(&webhooks).Error; err != nil {
+		return err
+	}
+	subscribed := webhooks[:0]
+	for _, webhook := range webhooks {
+		if slices.Contains(webhook.Events, event.Type) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	// Deleted tasks and users are still loaded, since deletions are events too
+	var task *models.Task
+	var data interface{}
+	switch {
+	case event.TaskID != nil:
+		task = &models.Task{}
+		if err := db.Unscoped().Scopes(withLabels).First(task, "id = ?", *event.TaskID).Error; err != nil {
+			return ignoreNotFound(err)
+		}
+		data = task
+	case event.UserID != nil:
+		var user models.User
+		if err := db.Unscoped().First(&user, "id = ?", *event.UserID).Error; err != nil {
+			return ignoreNotFound(err)
+		}
+		data = webhookUser{ID: user.ID, Username: user.Username, Email: user.Email}
+	default:
+		return nil
+	}
+
+	eventID, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	payload, err := json.Marshal(webhookPayload{ID: eventID, Type: event.Type, TenantID: event.TenantID, CreatedAt: now, Data: data})
+	if err != nil {
+		return err
+	}
+
+	for i := range subscribed {
+		visible, err := webhookSees(db, &subscribed[i], task)
+		if err != nil {
+			return err
+		}
+		if !visible {
+			continue
+		}
+		delivery, err := newWebhookDelivery(event.TenantID, subscribed[i].ID, eventID, event.Type, string(payload), now)
+		if err != nil {
+			return err
+		}
+		if err := db.Create(delivery).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverDue sends the pending deliveries whose attempt is due at now and
+// returns how many succeeded. Failed attempts are retried with exponential
+// backoff until maxWebhookAttempts is reached.
+func (s *WebhookServiceImpl) DeliverDue(db *gorm.DB, now time.Time) (int, error) {
+	var due []models.WebhookDelivery
+	err := db.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(webhookBatch).
+		Find(&due).Error
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range due {
+		ok, err := s.attempt(db, &due[i], now)
+		if err != nil {
+			return delivered, err
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// Run sends due deliveries every interval until ctx is cancelled. Like
+// reminders, only the replica holding the webhook lease sends.
+func (s *WebhookServiceImpl) Run(ctx context.Context, db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer ReleaseLease(db, webhookLease, s.holder)
+	for {
+		held, err := AcquireLease(db, webhookLease, s.holder, 3*interval, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "acquiring the webhook lease failed", "error", err)
+		} else if held {
+			if n, err := s.DeliverDue(db, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "delivering webhooks failed", "error", err)
+			} else if n > 0 {
+				slog.InfoContext(ctx, "delivered webhook events", "count", n)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome. It returns true when the
+// receiver accepted it.
+func (s *WebhookServiceImpl) attempt(db *gorm.DB, delivery *models.WebhookDelivery, now time.Time) (bool, error) {
+	var webhook models.Webhook
+	err := db.First(&webhook, "id = ?", delivery.WebhookID).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if err != nil || !webhook.Active {
+		return false, db.Model(delivery).Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryFailed,
+			"error":           "webhook is inactive",
+			"next_attempt_at": nil,
+		}).Error
+	}
+
+	code, body, sendErr := s.send(db.Statement.Context, &webhook, delivery, now)
+	updates := map[string]interface{}{
+		"attempts":      delivery.Attempts + 1,
+		"response_code": code,
+		"response_body": body,
+		"error":         "",
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.WebhookDeliverySucceeded
+		updates["delivered_at"] = now
+		updates["next_attempt_at"] = nil
+	case delivery.Attempts+1 >= maxWebhookAttempts:
+		updates["status"] = models.WebhookDeliveryFailed
+		updates["error"] = sendErr.Error()
+		updates["next_attempt_at"] = nil
+	default:
+		updates["error"] = sendErr.Error()
+		updates["next_attempt_at"] = now.Add(retryDelay(delivery.Attempts+1, webhookBackoff, maxWebhookBackoff))
+	}
+	if err := db.Model(delivery).Updates(updates).Error; err != nil {
+		return false, err
+	}
+	return sendErr == nil, nil
+}
+
+// send posts the payload, signed with the webhook's secret. A response other
+// than 2xx is an error; its status code and the start of its body are returned
+// for the delivery log.
+func (s *WebhookServiceImpl) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery, now time.Time) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TaskManager-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID.String())
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponse))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, string(body), fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// signWebhook is the HMAC-SHA256 of "timestamp.payload" under the secret, hex
+// encoded. Signing the timestamp lets receivers reject replayed deliveries.
+func signWebhook(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryDelay is the wait after the given number of failed attempts: first
+// after one, doubling with every further failure up to max
+func retryDelay(attempts int, first, max time.Duration) time.Duration {
+	delay := first
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
+
+// webhookSees reports whether the webhook receives an event about task, or
+// about a user when task is nil. Tenant webhooks see everything while their
+// owner is an admin; otherwise the owner must be able to see the task.
+func webhookSees(db *gorm.DB, webhook *models.Webhook, task *models.Task) (bool, error) {
+	owner, err := ActorForUser(db, webhook.UserID)
+	if err != nil {
+		return false, ignoreNotFound(err)
+	}
+	if webhook.Scope == models.WebhookScopeTenant && owner.IsAdmin() {
+		return true, nil
+	}
+	if task == nil {
+		return false, nil
+	}
+	level, err := taskAccess(db, task, owner)
+	return level != models.TaskAccessNone, err
+}
+
+func newWebhookDelivery(tenantID, webhookID, eventID uuid.UUID, eventType, payload string, now time.Time) (*models.WebhookDelivery, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	return &models.WebhookDelivery{
+		ID:            id,
+		TenantID:      tenantID,
+		WebhookID:     webhookID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	}, nil
+}
+
+func validateWebhook(webhook *models.Webhook) error {
+	if !validWebhookURL(webhook.URL) {
+		return ErrInvalidWebhookEndpoint
+	}
+	if webhook.Scope != models.WebhookScopeUser && webhook.Scope != models.WebhookScopeTenant {
+		return ErrInvalidWebhookScope
+	}
+	if len(webhook.Events) == 0 {
+		return ErrInvalidWebhookEvents
+	}
+	events := make([]string, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		if !slices.Contains(models.WebhookEventTypes, event) {
+			return fmt.Errorf("%w: %s", ErrInvalidWebhookEvents, event)
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	webhook.Events = events
+	return nil
+}
+
+// validWebhookURL reports whether raw is an absolute http or https URL
+func validWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// taskWebhookEvent is the webhook event of a change to task
+func taskWebhookEvent(eventType string, task *models.Task) WebhookEvent {
+	taskID := task.ID
+	return WebhookEvent{Type: eventType, TenantID: task.TenantID, TaskID: &taskID}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/utils"
+)
+
+var (
+	ErrInvalidStatus   = apperr.Invalid("status", "invalid_status", "status must be one of "+strings.Join(models.TaskStatuses, ", "))
+	ErrInvalidPriority = apperr.Invalid("priority", "invalid_priority", "priority must be one of "+strings.Join(models.TaskPriorities, ", "))
+)
+
+// DefaultWorkflowSpec moves work forward from pending through in_progress and
+// review to completed. Any open task can be cancelled, cancelled tasks can be
+// restored to pending and completed tasks can be reopened.
+const DefaultWorkflowSpec = "pending:in_progress,cancelled;" +
+	"in_progress:review,pending,cancelled;" +
+	"review:completed,in_progress,cancelled;" +
+	"completed:in_progress;" +
+	"cancelled:pending"
+
+// Workflow is the set of status transitions a task may take
+type Workflow struct {
+	transitions map[string][]string
+}
+
+// TransitionError reports a status change the workflow does not allow
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *TransitionError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("cannot move task from %s to %s: %s is a final status", e.From, e.To, e.From)
+	}
+	return fmt.Sprintf("cannot move task from %s to %s, allowed: %s", e.From, e.To, strings.Join(e.Allowed, ", "))
+}
+
+// AppError answers the refused transition with 422, naming the statuses the
+// task could move to instead
+func (e *TransitionError) AppError() *apperr.Error {
+	allowed := e.Allowed
+	if allowed == nil {
+		allowed = []string{}
+	}
+	return apperr.New(apperr.KindWorkflowViolation, "workflow_violation", e.Error()).
+		With("from", e.From).With("to", e.To).With("allowed", allowed)
+}
+
+func DefaultWorkflow() *Workflow {
+	workflow, err := ParseWorkflow(DefaultWorkflowSpec)
+	if err != nil {
+		panic(err)
+	}
+	return workflow
+}
+
+// LoadWorkflow reads the workflow from TASK_WORKFLOW, falling back to the default
+func LoadWorkflow() (*Workflow, error) {
+	return ParseWorkflow(utils.GetEnv("TASK_WORKFLOW", DefaultWorkflowSpec))
+}
+
+// ParseWorkflow reads transitions written as "from:to,to;from:to". Statuses
+// without an entry are final.
+func ParseWorkflow(spec string) (*Workflow, error) {
+	workflow := &Workflow{transitions: map[string][]string{}}
+	for _, rule := range strings.Split(spec, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		from, targets, ok := strings.Cut(rule, ":")
+		from = strings.TrimSpace(from)
+		if !ok || !models.ValidTaskStatus(from) {
+			return nil, fmt.Errorf("invalid workflow rule %q", rule)
+		}
+		for _, to := range strings.Split(targets, ",") {
+			to = strings.TrimSpace(to)
+			if !models.ValidTaskStatus(to) || to == from {
+				return nil, fmt.Errorf("invalid transition %q in workflow rule %q", to, rule)
+			}
+			workflow.transitions[from] = append(workflow.transitions[from], to)
+		}
+	}
+	return workflow, nil
+}
+
+// Allowed returns the statuses a task in the given status can move to
+func (w *Workflow) Allowed(from string) []string {
+	return w.transitions[from]
+}
+
+// CheckTransition returns a *TransitionError when a task may not move from one
+// status to the other. Staying in the same status is always allowed, and so is
+// leaving a status that predates the workflow.
+func (w *Workflow) CheckTransition(from, to string) error {
+	if !models.ValidTaskStatus(to) {
+		return ErrInvalidStatus
+	}
+	if from == to || !models.ValidTaskStatus(from) {
+		return nil
+	}
+	for _, allowed := range w.transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to, Allowed: w.Allowed(from)}
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"task-manager/backend/internal/utils"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// Storage keeps the bytes of uploaded files. Keys are slash-separated paths
+// chosen by the caller.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FromEnv builds the storage selected by STORAGE_DRIVER: "local" (the default)
+// writes below STORAGE_LOCAL_DIR, "s3" talks to any S3-compatible service.
+func FromEnv() (Storage, error) {
+	switch driver := utils.GetEnv("STORAGE_DRIVER", "local"); driver {
+	case "local":
+		return NewLocalStorage(utils.GetEnv("STORAGE_LOCAL_DIR", "./attachments"))
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:        utils.GetEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:          utils.GetEnv("S3_REGION", "us-east-1"),
+			Bucket:          utils.GetEnv("S3_BUCKET", ""),
+			AccessKeyID:     utils.GetEnv("S3_ACCESS_KEY_ID", ""),
+			SecretAccessKey: utils.GetEnv("S3_SECRET_ACCESS_KEY", ""),
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+}
+
+// cleanKey rejects keys that are empty, absolute or climb out of the storage root
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean(key)
+	if key == "" || cleaned != key || strings.HasPrefix(cleaned, "/") || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
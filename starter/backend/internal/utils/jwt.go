@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"os"
+	"task-manager/backend/internal/apperr"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -10,12 +11,13 @@ import (
 )
 
 var (
-	ErrInvalidToken = errors.New("invalid token")
-	ErrExpiredToken = errors.New("token has expired")
+	ErrInvalidToken = apperr.New(apperr.KindUnauthorized, "invalid_token", "invalid token")
+	ErrExpiredToken = apperr.New(apperr.KindUnauthorized, "token_expired", "token has expired")
 )
 
 type Claims struct {
 	UserID      uuid.UUID `json:"user_id"`
+	TenantID    uuid.UUID `json:"tenant_id"`
 	Username    string    `json:"username"`
 	Roles       []string  `json:"roles"`
 	Permissions []string  `json:"permissions"`
@@ -30,9 +32,10 @@ func getJWTSecret() []byte {
 	return []byte(secret)
 }
 
-func GenerateAccessToken(userID uuid.UUID, username string, roles []string, permissions []string) (string, error) {
+func GenerateAccessToken(userID, tenantID uuid.UUID, username string, roles []string, permissions []string) (string, error) {
 	claims := &Claims{
 		UserID:      userID,
+		TenantID:    tenantID,
 		Username:    username,
 		Roles:       roles,
 		Permissions: permissions,
@@ -1,60 +1,183 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"task-manager/backend/internal/events"
 	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/logging"
+	"task-manager/backend/internal/metrics"
 	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/notify"
+	"task-manager/backend/internal/outbox"
 	"task-manager/backend/internal/repositories"
 	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/storage"
+	"task-manager/backend/internal/utils"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
 )
 
 func main() {
+	// Logs are JSON lines on stdout; the standard log package writes through
+	// the same logger
+	logLevel, err := logging.ParseLevel(utils.GetEnv("LOG_LEVEL", "info"))
+	if err != nil {
+		fatal("Invalid LOG_LEVEL", err)
+	}
+	slog.SetDefault(logging.New(os.Stdout, logLevel))
 
 	dbCfg := repositories.NewDatabaseConfig()
 	db, err := dbCfg.Connect()
 	if err != nil {
-		log.Fatal("Database connection failed: ", err)
+		fatal("Database connection failed", err)
+	}
+
+	// "verify-audit" checks the audit log's hash chains instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "verify-audit" {
+		os.Exit(verifyAudit(db))
 	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("Failed to get database instance: ", err)
+		fatal("Failed to get database instance", err)
 	}
 	defer sqlDB.Close()
 
+	tenantService := services.NewTenantService()
+
 	registerService := services.NewRegisterService()
 	registrationHandler := handlers.NewRegisterHandler(db, registerService)
 
 	authService := services.NewAuthService()
 	authHandler := handlers.NewAuthHandler(db, authService)
 
-	taskService := services.NewTaskService()
-	taskHandler := handlers.NewTaskHandler(db, taskService)
+	workflow, err := services.LoadWorkflow()
+	if err != nil {
+		fatal("Invalid TASK_WORKFLOW", err)
+	}
+
+	// Task changes reach connected clients through the broker. The in-memory
+	// broker only reaches clients of this replica; with several replicas use
+	// postgres, which relays events over LISTEN/NOTIFY.
+	var broker events.Broker
+	switch kind := utils.GetEnv("EVENT_BROKER", "memory"); kind {
+	case "memory":
+		broker = events.NewMemoryBroker(64)
+	case "postgres":
+		pg := events.NewPostgresBroker(db, dbCfg.DSN(), "task_events", 64)
+		go pg.Listen(context.Background())
+		broker = pg
+	default:
+		fatal("Unknown EVENT_BROKER", fmt.Errorf("unknown broker %q", kind))
+	}
+
+	taskService := services.NewTaskServiceWithWorkflow(workflow).WithEvents(broker)
+	taskHandler := handlers.NewTaskHandler(db, taskService).WithRequireIfMatch(utils.GetEnv("TASK_REQUIRE_IF_MATCH", "false") == "true")
+	taskStreamHandler := handlers.NewTaskStreamHandler(db, taskService, broker)
+
+	taskShareService := services.NewTaskShareService()
+	taskShareHandler := handlers.NewTaskShareHandler(db, taskService, taskShareService)
+
+	taskDependencyService := services.NewTaskDependencyService()
+	taskDependencyHandler := handlers.NewTaskDependencyHandler(db, taskService, taskDependencyService)
+
+	commentService := services.NewCommentService()
+	commentHandler := handlers.NewCommentHandler(db, taskService, commentService)
+
+	attachmentStorage, err := storage.FromEnv()
+	if err != nil {
+		fatal("Attachment storage setup failed", err)
+	}
+	attachmentService := services.NewAttachmentService(attachmentStorage, int64(utils.GetEnvAsInt("ATTACHMENT_MAX_BYTES", 10<<20)))
+	attachmentHandler := handlers.NewAttachmentHandler(db, taskService, attachmentService, utils.GetEnvAsDuration("ATTACHMENT_URL_TTL", 5*time.Minute))
+
+	recurrenceService := services.NewRecurrenceService(utils.GetEnvAsDuration("RECURRENCE_LEAD", 24*time.Hour)).WithEvents(broker)
+	recurrenceHandler := handlers.NewRecurrenceHandler(db, taskService, recurrenceService)
+
+	notificationService := services.NewNotificationService()
+	notificationHandler := handlers.NewNotificationHandler(db, notificationService)
+
+	// In-app and webhook reminders are always available, email only once an SMTP relay is configured
+	notifiers := map[string]notify.Notifier{
+		models.ReminderChannelInApp:   services.NewInAppNotifier(db, notificationService),
+		models.ReminderChannelWebhook: notify.NewWebhookNotifier(utils.GetEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second)),
+	}
+	if host := utils.GetEnv("SMTP_HOST", ""); host != "" {
+		notifiers[models.ReminderChannelEmail] = notify.NewEmailNotifier(notify.SMTPConfig{
+			Host:     host,
+			Port:     utils.GetEnv("SMTP_PORT", "587"),
+			Username: utils.GetEnv("SMTP_USERNAME", ""),
+			Password: utils.GetEnv("SMTP_PASSWORD", ""),
+			From:     utils.GetEnv("SMTP_FROM", "taskmanager@localhost"),
+		})
+	}
+	reminderService := services.NewReminderService(notifiers, utils.GetEnvAsDuration("REMINDER_LEAD", 24*time.Hour))
+	reminderHandler := handlers.NewReminderHandler(db, reminderService)
+
+	webhookService := services.NewWebhookService().WithClient(notify.NewPublicClient(utils.GetEnvAsDuration("WEBHOOK_TIMEOUT", 10*time.Second)))
+	webhookHandler := handlers.NewWebhookHandler(db, webhookService)
+
+	outboxSinks, err := outbox.FromEnv()
+	if err != nil {
+		fatal("Outbox setup failed", err)
+	}
+	outboxService := services.NewOutboxService(outboxSinks...)
+
+	labelService := services.NewLabelService()
+	labelHandler := handlers.NewLabelHandler(db, labelService, taskService)
 
 	refreshHandler := handlers.NewRefreshHandler(db, authService)
 
+	teamService := services.NewTeamService()
+	teamHandler := handlers.NewTeamHandler(db, teamService, taskService)
+
+	projectService := services.NewProjectService()
+	projectHandler := handlers.NewProjectHandler(db, projectService, taskService)
+
 	userService := services.NewUserService()
 	userHandler := handlers.NewUserHandler(db, userService)
 
-	r := gin.Default()
+	auditHandler := handlers.NewAuditHandler(db, services.NewAuditService())
+
+	taskTransferService := services.NewTaskTransferService(taskService)
+	taskTransferHandler := handlers.NewTaskTransferHandler(db, taskTransferService)
+	calendarFeedHandler := handlers.NewCalendarFeedHandler(db, services.NewCalendarFeedService(), taskTransferService)
+
+	// Retried POSTs with the same Idempotency-Key get the first response back
+	idempotencyService := services.NewIdempotencyService(utils.GetEnvAsDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour)).
+		WithLockTimeout(utils.GetEnvAsDuration("IDEMPOTENCY_LOCK_TIMEOUT", time.Minute))
+	idempotency := middleware.Idempotency(db, idempotencyService)
+
+	r := gin.New()
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://host.docker.internal"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.TenantHeader, middleware.RequestIDHeader, "If-Match", "If-None-Match", middleware.IdempotencyKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", "ETag", middleware.RequestIDHeader, middleware.IdempotentReplayedHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	r.Use(middleware.RequestInfoMiddleware())
+	r.Use(middleware.RequestLogger())
+	r.Use(middleware.Metrics())
+	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.Recovery())
 	v1 := r.Group("/api/v1")
+	v1.Use(middleware.TenantMiddleware(db, tenantService))
 	{
 		authRoutes := v1.Group("/auth")
 		{
-			authRoutes.POST("/register", registrationHandler.Registration)
+			authRoutes.POST("/register", idempotency, registrationHandler.Registration)
 			authRoutes.POST("/login", authHandler.Token)
 			authRoutes.POST("/refresh", refreshHandler.Refresh)
 		}
@@ -63,20 +186,97 @@ func main() {
 		taskRoutes := v1.Group("/tasks")
 		taskRoutes.Use(middleware.AuthMiddleware())
 		{
-			// Create task - any authenticated user with task:create permission
-			taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+			// Create task - any authenticated user with task:create permission, retried safely with an Idempotency-Key
+			taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), idempotency, taskHandler.CreateTask)
 
-			// Update task - user must own the task or be admin with task:update permission
+			// Replace or patch task - user must own the task, hold editor access or be admin with task:update permission
 			taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+			taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+
+			// Bulk create, update and delete - each operation needs the permission and access of its single-task route
+			taskRoutes.POST("/bulk", taskHandler.BulkTasks)
+
+			// Export own tasks as CSV, NDJSON or iCalendar - admins can export any user's or the whole tenant's
+			taskRoutes.GET("/export", middleware.RequirePermission("tasks", "read"), taskTransferHandler.ExportTasks)
 
-			// Delete task - user must own the task or be admin with task:delete permission
+			// Import tasks from CSV or JSON, optionally as a dry run - any user with task:create permission
+			taskRoutes.POST("/import", middleware.RequirePermission("tasks", "create"), taskTransferHandler.ImportTasks)
+
+			// Delete task - user must own the task, hold owner access or be admin with task:delete permission
 			taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
 
-			// Get specific task - user must own the task or be admin with task:read permission
+			// Get specific task - user must own the task, have it shared with them or be admin with task:read permission
 			taskRoutes.GET("/:id", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskByID)
 
 			// Get all tasks - admin only with task:read permission
 			taskRoutes.GET("", middleware.RequireRoleAndPermission("admin", "tasks", "read"), taskHandler.GetTasks)
+
+			// Get tasks shared with me - any user with task:read permission
+			taskRoutes.GET("/shared", middleware.RequirePermission("tasks", "read"), taskHandler.GetSharedTasks)
+
+			// Task sharing - only the task owner or admin can manage who a task is shared with
+			taskRoutes.GET("/:id/shares", middleware.RequirePermission("tasks", "read"), taskShareHandler.GetShares)
+			taskRoutes.POST("/:id/shares", middleware.RequirePermission("tasks", "update"), taskShareHandler.ShareTask)
+			taskRoutes.DELETE("/:id/shares/:share_id", middleware.RequirePermission("tasks", "update"), taskShareHandler.RevokeShare)
+
+			// Get task with its subtasks and progress - same access as the task itself
+			taskRoutes.GET("/:id/tree", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskTree)
+
+			// Task dependencies - viewers can list, editors can add and remove blockers
+			taskRoutes.GET("/:id/dependencies", middleware.RequirePermission("tasks", "read"), taskDependencyHandler.GetDependencies)
+			taskRoutes.POST("/:id/dependencies", middleware.RequirePermission("tasks", "update"), taskDependencyHandler.AddDependency)
+			taskRoutes.DELETE("/:id/dependencies/:blocker_id", middleware.RequirePermission("tasks", "update"), taskDependencyHandler.RemoveDependency)
+
+			// Task comments - anyone who can see the task can read and post, authors edit their own comments
+			taskRoutes.GET("/:id/comments", middleware.RequirePermission("tasks", "read"), commentHandler.GetComments)
+			taskRoutes.POST("/:id/comments", middleware.RequirePermission("tasks", "read"), commentHandler.CreateComment)
+			taskRoutes.PUT("/:id/comments/:comment_id", middleware.RequirePermission("tasks", "read"), commentHandler.UpdateComment)
+			taskRoutes.DELETE("/:id/comments/:comment_id", middleware.RequirePermission("tasks", "read"), commentHandler.DeleteComment)
+			taskRoutes.GET("/:id/comments/:comment_id/history", middleware.RequirePermission("tasks", "read"), commentHandler.GetCommentHistory)
+
+			// Task attachments - viewers can list and get download links, editors can upload and delete
+			taskRoutes.GET("/:id/attachments", middleware.RequirePermission("tasks", "read"), attachmentHandler.GetAttachments)
+			taskRoutes.POST("/:id/attachments", middleware.RequirePermission("tasks", "update"), attachmentHandler.UploadAttachment)
+			taskRoutes.GET("/:id/attachments/:attachment_id", middleware.RequirePermission("tasks", "read"), attachmentHandler.GetAttachment)
+			taskRoutes.DELETE("/:id/attachments/:attachment_id", middleware.RequirePermission("tasks", "update"), attachmentHandler.DeleteAttachment)
+
+			// Task schedule - editors change the recurrence of this and all following occurrences
+			taskRoutes.PUT("/:id/recurrence", middleware.RequirePermission("tasks", "update"), recurrenceHandler.SetRecurrence)
+
+			// Task labels - editors can add labels they are allowed to use and remove any label
+			taskRoutes.PUT("/:id/labels/:label_id", middleware.RequirePermission("tasks", "update"), labelHandler.AddTaskLabel)
+			taskRoutes.DELETE("/:id/labels/:label_id", middleware.RequirePermission("tasks", "update"), labelHandler.RemoveTaskLabel)
+
+			// Task history - viewers see every revision with its field changes, editors can revert to one
+			taskRoutes.GET("/:id/history", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskHistory)
+			taskRoutes.POST("/:id/history/:revision/revert", middleware.RequirePermission("tasks", "update"), taskHandler.RevertTask)
+		}
+
+		// Live task changes - server-sent events for every task the user can see; EventSource
+		// clients that cannot set headers pass the token as ?access_token=
+		v1.GET("/tasks/stream", middleware.StreamAuthMiddleware(), middleware.RequirePermission("tasks", "read"), taskStreamHandler.Stream)
+
+		// Attachment downloads - authorized by the signed link instead of a bearer token
+		v1.GET("/attachments/:attachment_id/download", attachmentHandler.DownloadAttachment)
+
+		// Calendar feeds - authorized by the secret in the URL, which calendar apps subscribe to
+		v1.GET("/calendar/:token", calendarFeedHandler.Feed)
+
+		// Label routes - personal labels belong to their user, team labels are managed by team admins
+		labelRoutes := v1.Group("/labels")
+		labelRoutes.Use(middleware.AuthMiddleware())
+		{
+			// Create and list labels - any authenticated user
+			labelRoutes.POST("", labelHandler.CreateLabel)
+			labelRoutes.GET("", labelHandler.GetLabels)
+
+			// Update and delete label - label owner or team admin
+			labelRoutes.PUT("/:label_id", labelHandler.UpdateLabel)
+			labelRoutes.DELETE("/:label_id", labelHandler.DeleteLabel)
+
+			// Bulk apply and remove - the user must be able to edit every task
+			labelRoutes.POST("/apply", middleware.RequirePermission("tasks", "update"), labelHandler.ApplyLabels)
+			labelRoutes.POST("/remove", middleware.RequirePermission("tasks", "update"), labelHandler.RemoveLabels)
 		}
 
 		// User routes with ABAC policies
@@ -89,16 +289,107 @@ func main() {
 			// Get all users - admin only with user:read permission
 			userRoutes.GET("", middleware.RequireRoleAndPermission("admin", "users", "read"), userHandler.GetUsers)
 
-			// Get tasks by user - admin can access any user's tasks, regular users can only access their own
+			// Get tasks by user - admin can access any user's tasks, regular users see their own plus the ones shared with them
 			userRoutes.GET("/:user_id/tasks", middleware.RequirePermission("tasks", "read"), taskHandler.GetTasksByUser)
 
 			// Get own profile - any authenticated user
 			userRoutes.GET("/profile", userHandler.GetUserProfile)
 
+			// Own reminder preferences - any authenticated user
+			userRoutes.GET("/reminders", reminderHandler.GetPreferences)
+			userRoutes.PUT("/reminders", reminderHandler.UpdatePreferences)
+
+			// Own calendar feed - create or rotate, check and revoke the secret URL
+			userRoutes.POST("/calendar-feed", middleware.RequirePermission("tasks", "read"), calendarFeedHandler.CreateFeed)
+			userRoutes.GET("/calendar-feed", calendarFeedHandler.GetFeed)
+			userRoutes.DELETE("/calendar-feed", calendarFeedHandler.RevokeFeed)
+
 			// Get user profile by ID - admin only with user:read permission
 			userRoutes.GET("/profile/:user_id", middleware.RequireRoleAndPermission("admin", "users", "read"), userHandler.GetUserProfileByUserId)
 		}
 
+		// Notification routes - every user reads and marks their own inbox
+		notificationRoutes := v1.Group("/notifications")
+		notificationRoutes.Use(middleware.AuthMiddleware())
+		{
+			notificationRoutes.GET("", notificationHandler.GetNotifications)
+			notificationRoutes.GET("/unread-count", notificationHandler.GetUnreadCount)
+			notificationRoutes.POST("/read-all", notificationHandler.MarkAllRead)
+			notificationRoutes.POST("/:notification_id/read", notificationHandler.MarkRead)
+		}
+
+		// Webhook routes - users subscribe to their own tasks, admins to the whole tenant
+		webhookRoutes := v1.Group("/webhooks")
+		webhookRoutes.Use(middleware.AuthMiddleware())
+		{
+			// Create and list webhooks - any authenticated user, tenant scope for admins only
+			webhookRoutes.POST("", webhookHandler.CreateWebhook)
+			webhookRoutes.GET("", webhookHandler.GetWebhooks)
+
+			// Get, update and delete webhook - webhook owner or admin
+			webhookRoutes.GET("/:webhook_id", webhookHandler.GetWebhook)
+			webhookRoutes.PUT("/:webhook_id", webhookHandler.UpdateWebhook)
+			webhookRoutes.DELETE("/:webhook_id", webhookHandler.DeleteWebhook)
+
+			// Delivery log and manual redelivery - webhook owner or admin
+			webhookRoutes.GET("/:webhook_id/deliveries", webhookHandler.GetDeliveries)
+			webhookRoutes.POST("/:webhook_id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+		}
+
+		// Team routes - scoped to team membership instead of global roles
+		teamRoutes := v1.Group("/teams")
+		teamRoutes.Use(middleware.AuthMiddleware())
+		{
+			// Create team - any authenticated user, the creator becomes team admin
+			teamRoutes.POST("", teamHandler.CreateTeam)
+
+			// List teams - admins see all teams, users see the teams they belong to
+			teamRoutes.GET("", teamHandler.GetTeams)
+
+			// Get team - team members only
+			teamRoutes.GET("/:team_id", middleware.RequireTeamRole(db, teamService), teamHandler.GetTeam)
+
+			// Update and delete team - team admin only
+			teamRoutes.PUT("/:team_id", middleware.RequireTeamRole(db, teamService, models.TeamRoleAdmin), teamHandler.UpdateTeam)
+			teamRoutes.DELETE("/:team_id", middleware.RequireTeamRole(db, teamService, models.TeamRoleAdmin), teamHandler.DeleteTeam)
+
+			// Team membership - members can list, team admins can add, change and remove members
+			teamRoutes.GET("/:team_id/members", middleware.RequireTeamRole(db, teamService), teamHandler.GetMembers)
+			teamRoutes.POST("/:team_id/members", middleware.RequireTeamRole(db, teamService, models.TeamRoleAdmin), teamHandler.SetMember)
+			teamRoutes.DELETE("/:team_id/members/:user_id", middleware.RequireTeamRole(db, teamService, models.TeamRoleAdmin), teamHandler.RemoveMember)
+
+			// Team tasks - members with the matching task permission
+			teamRoutes.GET("/:team_id/tasks", middleware.RequirePermission("tasks", "read"), middleware.RequireTeamRole(db, teamService), teamHandler.GetTeamTasks)
+			teamRoutes.POST("/:team_id/tasks", middleware.RequirePermission("tasks", "create"), middleware.RequireTeamRole(db, teamService), teamHandler.CreateTeamTask)
+		}
+
+		// Project routes - scoped to project membership instead of global roles
+		projectRoutes := v1.Group("/projects")
+		projectRoutes.Use(middleware.AuthMiddleware())
+		{
+			// Create project - any authenticated user, the creator becomes project owner
+			projectRoutes.POST("", projectHandler.CreateProject)
+
+			// List projects - admins see all projects, users see the projects they belong to
+			projectRoutes.GET("", projectHandler.GetProjects)
+
+			// Get project - any project member
+			projectRoutes.GET("/:project_id", middleware.RequireProjectAccess(db, projectService, models.TaskAccessViewer), projectHandler.GetProject)
+
+			// Update, archive and delete project - project owner only
+			projectRoutes.PUT("/:project_id", middleware.RequireProjectAccess(db, projectService, models.TaskAccessOwner), projectHandler.UpdateProject)
+			projectRoutes.DELETE("/:project_id", middleware.RequireProjectAccess(db, projectService, models.TaskAccessOwner), projectHandler.DeleteProject)
+
+			// Project membership - members can list, project owners can add, change and remove members
+			projectRoutes.GET("/:project_id/members", middleware.RequireProjectAccess(db, projectService, models.TaskAccessViewer), projectHandler.GetMembers)
+			projectRoutes.POST("/:project_id/members", middleware.RequireProjectAccess(db, projectService, models.TaskAccessOwner), projectHandler.SetMember)
+			projectRoutes.DELETE("/:project_id/members/:user_id", middleware.RequireProjectAccess(db, projectService, models.TaskAccessOwner), projectHandler.RemoveMember)
+
+			// Project tasks - viewers can list, editors can create
+			projectRoutes.GET("/:project_id/tasks", middleware.RequirePermission("tasks", "read"), middleware.RequireProjectAccess(db, projectService, models.TaskAccessViewer), projectHandler.GetProjectTasks)
+			projectRoutes.POST("/:project_id/tasks", middleware.RequirePermission("tasks", "create"), middleware.RequireProjectAccess(db, projectService, models.TaskAccessEditor), projectHandler.CreateProjectTask)
+		}
+
 		// Admin-only routes
 		adminRoutes := v1.Group("/admin")
 		adminRoutes.Use(middleware.AuthMiddleware(), middleware.RequireRole("admin"))
@@ -106,7 +397,86 @@ func main() {
 			adminRoutes.GET("/dashboard", func(c *gin.Context) {
 				c.JSON(200, gin.H{"message": "admin access granted"})
 			})
+
+			// Label usage counts for the admin panel
+			adminRoutes.GET("/labels/usage", labelHandler.GetLabelUsage)
+
+			// Audit log with filters, NDJSON export and hash chain verification
+			adminRoutes.GET("/audit", auditHandler.GetEntries)
+			adminRoutes.GET("/audit/export", auditHandler.Export)
+			adminRoutes.GET("/audit/verify", auditHandler.Verify)
+		}
+	}
+	// Create upcoming occurrences of recurring tasks across all tenants. Every
+	// replica runs the generator; occurrences are unique per series and slot, so
+	// concurrent runs cannot create duplicates.
+	systemDB := db.WithContext(repositories.WithoutTenantScope(context.Background()))
+	go recurrenceService.Run(context.Background(), systemDB, utils.GetEnvAsDuration("RECURRENCE_INTERVAL", time.Minute))
+
+	// Send due-date reminders. Only the replica holding the reminder lease sends,
+	// so each reminder goes out from one place.
+	go reminderService.Run(context.Background(), systemDB, utils.GetEnvAsDuration("REMINDER_INTERVAL", time.Minute))
+
+	// Send queued webhook deliveries and retry failed ones. Like reminders, only
+	// the replica holding the webhook lease sends.
+	go webhookService.Run(context.Background(), systemDB, utils.GetEnvAsDuration("WEBHOOK_INTERVAL", 10*time.Second))
+
+	// Publish domain events from the outbox to its sinks, and delete published
+	// events past their retention period. Only the replica holding the outbox
+	// lease relays.
+	go outboxService.Run(context.Background(), systemDB, utils.GetEnvAsDuration("OUTBOX_INTERVAL", 5*time.Second), utils.GetEnvAsDuration("OUTBOX_RETENTION", 7*24*time.Hour))
+
+	// Delete notifications past their retention period
+	go notificationService.RunRetention(context.Background(), systemDB, utils.GetEnvAsDuration("NOTIFICATION_RETENTION", 90*24*time.Hour), time.Hour)
+
+	// Delete expired idempotency keys
+	go idempotencyService.Run(context.Background(), systemDB, time.Hour)
+
+	// Metrics are served on a separate admin port that is not exposed
+	// publicly; METRICS_TOKEN additionally requires scrapes to send it as a
+	// bearer token. An empty METRICS_ADDR turns the endpoint off.
+	if addr := utils.GetEnv("METRICS_ADDR", ":9090"); addr != "" {
+		metrics.Registry.MustRegister(
+			collectors.NewDBStatsCollector(sqlDB, dbCfg.Name),
+			metrics.NewTaskCollector(systemDB, 5*time.Second),
+		)
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler(utils.GetEnv("METRICS_TOKEN", "")))
+		go func() {
+			if err := http.ListenAndServe(addr, mux); err != nil {
+				fatal("Metrics server stopped", err)
+			}
+		}()
+	}
+
+	if err := r.Run(":8080"); err != nil {
+		fatal("Server stopped", err)
+	}
+}
+
+// fatal logs why the server cannot run and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// verifyAudit checks the hash chain of every tenant's audit log, printing one
+// line per tenant. It returns the exit status: 1 when a chain is broken.
+func verifyAudit(db *gorm.DB) int {
+	systemDB := db.WithContext(repositories.WithoutTenantScope(context.Background()))
+	results, err := services.NewAuditService().VerifyChains(systemDB)
+	if err != nil {
+		slog.Error("Audit verification failed", "error", err)
+		return 2
+	}
+	status := 0
+	for _, result := range results {
+		if result.Valid {
+			fmt.Printf("tenant %s: %d entries, chain intact\n", result.TenantID, result.Entries)
+			continue
 		}
+		fmt.Printf("tenant %s: chain broken at entry %d: %s\n", result.TenantID, *result.BrokenAt, result.Reason)
+		status = 1
 	}
-	r.Run(":8080")
+	return status
 }
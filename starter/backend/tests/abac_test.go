@@ -24,7 +24,7 @@ func setupABACTestDB(t *testing.T) *gorm.DB {
 	assert.NoError(t, err)
 
 	// Migrate the schema
-	err = db.AutoMigrate(&models.User{}, &models.Role{}, &models.Permission{}, &models.RolePermission{}, &models.UserRole{}, &models.Token{}, &models.Task{})
+	err = db.AutoMigrate(&models.User{}, &models.Role{}, &models.Permission{}, &models.RolePermission{}, &models.UserRole{}, &models.Token{}, &models.Task{}, &models.TaskShare{}, &models.Team{}, &models.TeamMember{}, &models.Project{}, &models.ProjectMember{}, &models.TaskDependency{}, &models.Comment{}, &models.CommentRevision{}, &models.CommentMention{}, &models.Attachment{}, &models.Label{}, &models.TaskLabel{}, &models.ReminderPreference{}, &models.TaskReminder{}, &models.Notification{}, &models.Lease{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.OutboxEvent{}, &models.OutboxDelivery{}, &models.AuditEntry{}, &models.TaskRevision{}, &models.CalendarFeed{}, &models.IdempotencyKey{})
 	assert.NoError(t, err)
 
 	// Create default roles
@@ -109,6 +109,7 @@ func TestABACTaskAccessPolicies(t *testing.T) {
 	db := setupABACTestDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middleware.ErrorHandler())
 
 	// Setup handlers
 	taskService := services.NewTaskService()
@@ -127,6 +128,7 @@ func TestABACTaskAccessPolicies(t *testing.T) {
 	{
 		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
 		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
 		taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
 		taskRoutes.GET("/:id", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskByID)
 		taskRoutes.GET("", middleware.RequireRoleAndPermission("admin", "tasks", "read"), taskHandler.GetTasks)
@@ -197,7 +199,9 @@ func TestABACTaskAccessPolicies(t *testing.T) {
 
 	t.Run("User can update their own task", func(t *testing.T) {
 		updateData := map[string]interface{}{
-			"title": "Updated Task 1",
+			"title":    "Updated Task 1",
+			"status":   "pending",
+			"priority": "medium",
 		}
 		body, _ := json.Marshal(updateData)
 		req := httptest.NewRequest("PUT", "/tasks/"+task1.ID.String(), bytes.NewBuffer(body))
@@ -226,6 +230,7 @@ func TestABACUserAccessPolicies(t *testing.T) {
 	db := setupABACTestDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middleware.ErrorHandler())
 
 	// Setup handlers
 	userService := services.NewUserService()
@@ -313,6 +318,7 @@ func TestABACPermissionEnforcement(t *testing.T) {
 	db := setupABACTestDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middleware.ErrorHandler())
 
 	// Setup middleware test routes
 	router.GET("/test-permission/:resource/:action", middleware.AuthMiddleware(), func(c *gin.Context) {
@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupAuditRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+	router.Use(middleware.RequestInfoMiddleware())
+
+	authService := services.NewAuthService()
+	authHandler := handlers.NewAuthHandler(db, authService)
+	refreshHandler := handlers.NewRefreshHandler(db, authService)
+	taskHandler := handlers.NewTaskHandler(db, services.NewTaskService())
+	userHandler := handlers.NewUserHandler(db, services.NewUserService())
+	auditHandler := handlers.NewAuditHandler(db, services.NewAuditService())
+
+	router.POST("/token", authHandler.Token)
+	router.POST("/refresh", refreshHandler.Refresh)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
+	}
+
+	userRoutes := router.Group("/users")
+	userRoutes.Use(middleware.AuthMiddleware())
+	{
+		userRoutes.DELETE("/:user_id", middleware.RequireRoleAndPermission("admin", "users", "delete"), userHandler.DeleteUser)
+	}
+
+	adminRoutes := router.Group("/admin")
+	adminRoutes.Use(middleware.AuthMiddleware(), middleware.RequireRole("admin"))
+	{
+		adminRoutes.GET("/audit", auditHandler.GetEntries)
+		adminRoutes.GET("/audit/export", auditHandler.Export)
+		adminRoutes.GET("/audit/verify", auditHandler.Verify)
+	}
+
+	return router
+}
+
+type auditPage struct {
+	Items []models.AuditEntry `json:"items"`
+	Total int64               `json:"total"`
+}
+
+func TestAuditLog(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupAuditRouter(db)
+
+	assert.NoError(t, services.NewRegisterService().RegisterUser(db, models.User{Username: "alice", Email: "alice@test.com", Password: "alice123"}))
+	var alice models.User
+	db.First(&alice, "username = ?", "alice")
+	adminID, adminToken := createTestUser(t, db, "admin", "admin@test.com", "admin123", true)
+
+	entries := func(query string) auditPage {
+		var page auditPage
+		resp := doJSON(router, "GET", "/admin/audit"+query, adminToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &page)
+		return page
+	}
+
+	var aliceToken string
+	t.Run("Logins, failed logins and refreshes are recorded", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/token", "", gin.H{"username": "alice", "password": "wrong"})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		resp = doJSON(router, "POST", "/token", "", gin.H{"username": "mallory", "password": "guess"})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+		body, _ := json.Marshal(gin.H{"username": "alice", "password": "alice123"})
+		req := httptest.NewRequest("POST", "/token", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "audit-test/1.0")
+		req.Header.Set(middleware.RequestIDHeader, "req-42")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "req-42", rec.Header().Get(middleware.RequestIDHeader))
+		var tokens handlers.AuthResponse
+		json.Unmarshal(rec.Body.Bytes(), &tokens)
+		aliceToken = tokens.AccessToken
+
+		resp = doJSON(router, "POST", "/refresh", "", gin.H{"refresh_token": tokens.RefreshToken})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NotEmpty(t, resp.Header().Get(middleware.RequestIDHeader))
+
+		failed := entries("?action=auth.login_failed")
+		assert.Equal(t, int64(2), failed.Total)
+		assert.Equal(t, `unknown username "mallory"`, failed.Items[0].Detail)
+		assert.Nil(t, failed.Items[0].TargetID)
+		assert.Equal(t, "wrong password", failed.Items[1].Detail)
+		assert.Equal(t, alice.ID, *failed.Items[1].TargetID)
+		assert.Nil(t, failed.Items[1].ActorID)
+
+		login := entries("?action=auth.login").Items[0]
+		assert.Equal(t, alice.ID, *login.ActorID)
+		assert.Equal(t, "audit-test/1.0", login.UserAgent)
+		assert.Equal(t, "req-42", login.RequestID)
+		assert.NotEmpty(t, login.IP)
+
+		assert.Equal(t, int64(1), entries("?action=auth.refresh").Total)
+	})
+
+	t.Run("Task changes are recorded with a diff", func(t *testing.T) {
+		var task models.Task
+		resp := doJSON(router, "POST", "/tasks", aliceToken, gin.H{"title": "Draft", "priority": "low"})
+		json.Unmarshal(resp.Body.Bytes(), &task)
+		doJSON(router, "PATCH", "/tasks/"+task.ID.String(), aliceToken, gin.H{"title": "Final"})
+		doJSON(router, "DELETE", "/tasks/"+task.ID.String(), aliceToken, nil)
+
+		page := entries("?target_id=" + task.ID.String())
+		assert.Equal(t, int64(3), page.Total)
+		deleted, updated, created := page.Items[0], page.Items[1], page.Items[2]
+
+		assert.Equal(t, models.AuditTaskCreated, created.Action)
+		assert.Equal(t, alice.ID, *created.ActorID)
+		assert.JSONEq(t, `null`, string(created.Changes["title"].Before))
+		assert.JSONEq(t, `"Draft"`, string(created.Changes["title"].After))
+
+		assert.Equal(t, models.AuditTaskUpdated, updated.Action)
+		assert.JSONEq(t, `"Draft"`, string(updated.Changes["title"].Before))
+		assert.JSONEq(t, `"Final"`, string(updated.Changes["title"].After))
+		assert.NotContains(t, updated.Changes, "priority")
+		assert.NotContains(t, updated.Changes, "updated_at")
+
+		assert.Equal(t, models.AuditTaskDeleted, deleted.Action)
+		assert.JSONEq(t, `"Final"`, string(deleted.Changes["title"].Before))
+		assert.JSONEq(t, `null`, string(deleted.Changes["title"].After))
+	})
+
+	t.Run("User deletions are recorded", func(t *testing.T) {
+		resp := doJSON(router, "DELETE", "/users/"+alice.ID.String(), adminToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+
+		page := entries("?action=user.deleted&actor_id=" + adminID.String())
+		assert.Equal(t, int64(1), page.Total)
+		assert.Equal(t, alice.ID, *page.Items[0].TargetID)
+		assert.JSONEq(t, `"alice"`, string(page.Items[0].Changes["username"].Before))
+		assert.NotContains(t, page.Items[0].Changes, "password")
+	})
+
+	t.Run("Only admins read the audit log", func(t *testing.T) {
+		_, userToken := createTestUser(t, db, "bob", "bob@test.com", "bob123", false)
+		resp := doJSON(router, "GET", "/admin/audit", userToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "GET", "/admin/audit?from=yesterday", adminToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("The log exports as NDJSON in chain order", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/admin/audit/export", adminToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, "application/x-ndjson", resp.Header().Get("Content-Type"))
+
+		scanner := bufio.NewScanner(resp.Body)
+		var seqs []int64
+		for scanner.Scan() {
+			var entry models.AuditEntry
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+			seqs = append(seqs, entry.Seq)
+		}
+		assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8}, seqs)
+
+		resp = doJSON(router, "GET", "/admin/audit/export?action=auth.login_failed", adminToken, nil)
+		assert.Equal(t, 2, strings.Count(resp.Body.String(), "\n"))
+	})
+
+	t.Run("Verification detects tampering", func(t *testing.T) {
+		var result services.AuditVerification
+		resp := doJSON(router, "GET", "/admin/audit/verify", adminToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.True(t, result.Valid)
+		assert.Equal(t, int64(8), result.Entries)
+
+		db.Exec("UPDATE audit_entries SET detail = ? WHERE seq = ?", "right password", 2)
+		resp = doJSON(router, "GET", "/admin/audit/verify", adminToken, nil)
+		result = services.AuditVerification{}
+		json.Unmarshal(resp.Body.Bytes(), &result)
+		assert.False(t, result.Valid)
+		assert.Equal(t, int64(2), *result.BrokenAt)
+
+		// Removing the entry instead leaves a gap in the chain
+		db.Exec("DELETE FROM audit_entries WHERE seq = ?", 2)
+		results, err := services.NewAuditService().VerifyChains(db)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.False(t, results[0].Valid)
+		assert.Equal(t, int64(3), *results[0].BrokenAt)
+	})
+}
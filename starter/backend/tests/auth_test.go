@@ -24,7 +24,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	assert.NoError(t, err)
 
 	// Migrate the schema
-	err = db.AutoMigrate(&models.User{}, &models.Role{}, &models.Permission{}, &models.RolePermission{}, &models.UserRole{}, &models.Token{})
+	err = db.AutoMigrate(&models.User{}, &models.Role{}, &models.Permission{}, &models.RolePermission{}, &models.UserRole{}, &models.Token{}, &models.Project{}, &models.ProjectMember{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.OutboxEvent{}, &models.OutboxDelivery{}, &models.AuditEntry{}, &models.TaskRevision{}, &models.CalendarFeed{}, &models.IdempotencyKey{})
 	assert.NoError(t, err)
 
 	// Create default roles and permissions
@@ -141,6 +141,7 @@ func TestProtectedRoutes(t *testing.T) {
 	db := setupTestDB(t)
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middleware.ErrorHandler())
 
 	// Setup routes with middleware
 	authService := services.NewAuthService()
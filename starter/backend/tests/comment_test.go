@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupCommentRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	commentHandler := handlers.NewCommentHandler(db, services.NewTaskService(), services.NewCommentService())
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.GET("/:id/comments", middleware.RequirePermission("tasks", "read"), commentHandler.GetComments)
+		taskRoutes.POST("/:id/comments", middleware.RequirePermission("tasks", "read"), commentHandler.CreateComment)
+		taskRoutes.PUT("/:id/comments/:comment_id", middleware.RequirePermission("tasks", "read"), commentHandler.UpdateComment)
+		taskRoutes.DELETE("/:id/comments/:comment_id", middleware.RequirePermission("tasks", "read"), commentHandler.DeleteComment)
+		taskRoutes.GET("/:id/comments/:comment_id/history", middleware.RequirePermission("tasks", "read"), commentHandler.GetCommentHistory)
+	}
+
+	return router
+}
+
+func TestTaskComments(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupCommentRouter(db)
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	viewerID, viewerToken := createTestUser(t, db, "viewer", "viewer@test.com", "viewer123", false)
+	_, strangerToken := createTestUser(t, db, "stranger", "stranger@test.com", "stranger123", false)
+
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Discuss me", UserID: ownerID, Status: "pending"}
+	db.Create(&task)
+	db.Create(&models.TaskShare{ID: uuid.Must(uuid.NewV4()), TaskID: task.ID, SubjectType: models.ShareSubjectUser, SubjectID: viewerID, Permission: models.TaskAccessViewer})
+	commentsPath := "/tasks/" + task.ID.String() + "/comments"
+
+	var comment models.Comment
+
+	t.Run("Viewers can comment and mention people who can see the task", func(t *testing.T) {
+		resp := doJSON(router, "POST", commentsPath, viewerToken, handlers.CommentRequest{Body: "**Looks good** @owner, cc @stranger and mail viewer@test.com"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &comment)
+		assert.Equal(t, viewerID, comment.AuthorID)
+		assert.Len(t, comment.Mentions, 1)
+		assert.Equal(t, ownerID, comment.Mentions[0].UserID)
+	})
+
+	t.Run("Strangers cannot read or post", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, doJSON(router, "GET", commentsPath, strangerToken, nil).Code)
+		resp := doJSON(router, "POST", commentsPath, strangerToken, handlers.CommentRequest{Body: "hi"})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	commentPath := commentsPath + "/" + comment.ID.String()
+
+	t.Run("Only the author can edit and edits keep history", func(t *testing.T) {
+		resp := doJSON(router, "PUT", commentPath, ownerToken, handlers.CommentRequest{Body: "rewritten"})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "PUT", commentPath, viewerToken, handlers.CommentRequest{Body: "Looks good, no mentions"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var edited models.Comment
+		json.Unmarshal(resp.Body.Bytes(), &edited)
+		assert.NotNil(t, edited.EditedAt)
+		assert.Len(t, edited.Mentions, 0)
+
+		var revisions []models.CommentRevision
+		resp = doJSON(router, "GET", commentPath+"/history", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &revisions)
+		assert.Len(t, revisions, 1)
+		assert.Contains(t, revisions[0].Body, "@owner")
+	})
+
+	t.Run("Listing is paginated", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			doJSON(router, "POST", commentsPath, ownerToken, handlers.CommentRequest{Body: fmt.Sprintf("comment %d", i)})
+		}
+
+		var page struct {
+			Items    []models.Comment `json:"items"`
+			Page     int              `json:"page"`
+			PageSize int              `json:"page_size"`
+			Total    int64            `json:"total"`
+		}
+		resp := doJSON(router, "GET", commentsPath+"?page=2&page_size=2", viewerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &page)
+		assert.Equal(t, int64(5), page.Total)
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, "comment 1", page.Items[0].Body)
+
+		assert.Equal(t, http.StatusBadRequest, doJSON(router, "GET", commentsPath+"?page=0", viewerToken, nil).Code)
+	})
+
+	t.Run("Task owners can delete any comment", func(t *testing.T) {
+		resp := doJSON(router, "DELETE", commentPath, ownerToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Equal(t, http.StatusNotFound, doJSON(router, "GET", commentPath+"/history", ownerToken, nil).Code)
+
+		var deleted models.Comment
+		assert.NoError(t, db.Unscoped().First(&deleted, "id = ?", comment.ID).Error)
+		assert.True(t, deleted.DeletedAt.Valid)
+	})
+}
+
+func TestMentionedUsernames(t *testing.T) {
+	assert.Equal(t, []string{"alice", "bob.smith"}, services.MentionedUsernames("@alice and @bob.smith. Again @alice, not bob@example.com"))
+	assert.Empty(t, services.MentionedUsernames("no mentions here"))
+}
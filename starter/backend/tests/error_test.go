@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"task-manager/backend/internal/apperr"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type problemBody struct {
+	Type      string              `json:"type"`
+	Title     string              `json:"title"`
+	Status    int                 `json:"status"`
+	Detail    string              `json:"detail"`
+	Instance  string              `json:"instance"`
+	Code      string              `json:"code"`
+	RequestID string              `json:"request_id"`
+	Errors    []apperr.FieldError `json:"errors"`
+}
+
+func setupErrorRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestInfoMiddleware())
+	router.Use(middleware.ErrorHandler())
+
+	taskHandler := handlers.NewTaskHandler(db, services.NewTaskService())
+	registerHandler := handlers.NewRegisterHandler(db, services.NewRegisterService())
+
+	router.POST("/register", registerHandler.Registration)
+	router.GET("/broken", func(c *gin.Context) {
+		apperr.Abort(c, errors.New(`pq: relation "secret_table" does not exist`))
+	})
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
+	}
+
+	return router
+}
+
+func decodeProblem(t *testing.T, resp *http.Response, body []byte) problemBody {
+	t.Helper()
+	assert.Equal(t, apperr.ContentType, resp.Header.Get("Content-Type"))
+	var problem problemBody
+	assert.NoError(t, json.Unmarshal(body, &problem))
+	return problem
+}
+
+func TestProblemDetails(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupErrorRouter(db)
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+
+	t.Run("Invalid fields are listed with their own codes", func(t *testing.T) {
+		resp := doJSONWithHeaders(router, "POST", "/register", "", map[string]string{middleware.RequestIDHeader: "req-42"},
+			gin.H{"username": "newbie", "email": "not-an-email", "password": "123"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		problem := decodeProblem(t, resp.Result(), resp.Body.Bytes())
+		assert.Equal(t, "about:blank", problem.Type)
+		assert.Equal(t, "Bad Request", problem.Title)
+		assert.Equal(t, http.StatusBadRequest, problem.Status)
+		assert.Equal(t, "validation_failed", problem.Code)
+		assert.Equal(t, "/register", problem.Instance)
+		assert.Equal(t, "req-42", problem.RequestID)
+		assert.ElementsMatch(t, []apperr.FieldError{
+			{Field: "email", Code: "email", Message: "email must be an email address"},
+			{Field: "password", Code: "min", Message: "password must be at least 6 characters"},
+		}, problem.Errors)
+	})
+
+	t.Run("Domain errors keep their status and code", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Typo", "user_id": ownerID, "status": "pendng"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		problem := decodeProblem(t, resp.Result(), resp.Body.Bytes())
+		assert.Equal(t, "validation_failed", problem.Code)
+		assert.Equal(t, "status", problem.Errors[0].Field)
+		assert.Equal(t, "invalid_status", problem.Errors[0].Code)
+
+		resp = doJSON(router, "DELETE", "/tasks/00000000-0000-0000-0000-000000000001", ownerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		problem = decodeProblem(t, resp.Result(), resp.Body.Bytes())
+		assert.Equal(t, "task_not_found", problem.Code)
+		assert.NotEmpty(t, problem.RequestID)
+
+		resp = doJSON(router, "POST", "/tasks", "", gin.H{"title": "Anonymous"})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Equal(t, "invalid_token", decodeProblem(t, resp.Result(), resp.Body.Bytes()).Code)
+	})
+
+	t.Run("Deleting a task that is gone answers 404", func(t *testing.T) {
+		var task models.Task
+		resp := doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Short-lived", "user_id": ownerID})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &task)
+
+		err := services.NewTaskService().DeleteTask(db, task.ID, 0)
+		assert.NoError(t, err)
+		err = services.NewTaskService().DeleteTask(db, task.ID, 0)
+		assert.ErrorIs(t, err, services.ErrTaskNotFound)
+		assert.Equal(t, http.StatusNotFound, apperr.From(err).Status())
+	})
+
+	t.Run("Unexpected errors do not leak their cause", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/broken", "", nil)
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+		problem := decodeProblem(t, resp.Result(), resp.Body.Bytes())
+		assert.Equal(t, "internal_error", problem.Code)
+		assert.Equal(t, "internal server error", problem.Detail)
+		assert.False(t, strings.Contains(resp.Body.String(), "secret_table"))
+	})
+}
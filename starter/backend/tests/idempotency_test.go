@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupIdempotencyRouter(db *gorm.DB, ttl time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	idempotency := middleware.Idempotency(db, services.NewIdempotencyService(ttl))
+	taskHandler := handlers.NewTaskHandler(db, services.NewTaskService())
+	registerHandler := handlers.NewRegisterHandler(db, services.NewRegisterService())
+
+	router.POST("/register", idempotency, registerHandler.Registration)
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), idempotency, taskHandler.CreateTask)
+	}
+
+	return router
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupIdempotencyRouter(db, time.Hour)
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	_, otherToken := createTestUser(t, db, "other", "other@test.com", "other123", false)
+
+	countTasks := func(title string) int64 {
+		var count int64
+		db.Model(&models.Task{}).Where("title = ?", title).Count(&count)
+		return count
+	}
+
+	t.Run("A retried create is replayed instead of creating a second task", func(t *testing.T) {
+		header := map[string]string{middleware.IdempotencyKeyHeader: "create-1"}
+		payload := gin.H{"title": "Once only", "user_id": ownerID}
+
+		first := doJSONWithHeaders(router, "POST", "/tasks", ownerToken, header, payload)
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Empty(t, first.Header().Get(middleware.IdempotentReplayedHeader))
+
+		retry := doJSONWithHeaders(router, "POST", "/tasks", ownerToken, header, payload)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+		assert.JSONEq(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, int64(1), countTasks("Once only"))
+
+		// Without a key every request runs
+		doJSON(router, "POST", "/tasks", ownerToken, payload)
+		assert.Equal(t, int64(2), countTasks("Once only"))
+	})
+
+	t.Run("A key reused for a different request is rejected", func(t *testing.T) {
+		header := map[string]string{middleware.IdempotencyKeyHeader: "create-2"}
+
+		resp := doJSONWithHeaders(router, "POST", "/tasks", ownerToken, header, gin.H{"title": "First payload", "user_id": ownerID})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		resp = doJSONWithHeaders(router, "POST", "/tasks", ownerToken, header, gin.H{"title": "Second payload", "user_id": ownerID})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Equal(t, int64(0), countTasks("Second payload"))
+	})
+
+	t.Run("Keys belong to the user who sent them", func(t *testing.T) {
+		header := map[string]string{middleware.IdempotencyKeyHeader: "shared-key"}
+
+		resp := doJSONWithHeaders(router, "POST", "/tasks", ownerToken, header, gin.H{"title": "Mine"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		resp = doJSONWithHeaders(router, "POST", "/tasks", otherToken, header, gin.H{"title": "Mine"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Empty(t, resp.Header().Get(middleware.IdempotentReplayedHeader))
+		assert.Equal(t, int64(2), countTasks("Mine"))
+	})
+
+	t.Run("Failed requests are replayed but expired keys are not", func(t *testing.T) {
+		header := map[string]string{middleware.IdempotencyKeyHeader: "invalid"}
+
+		resp := doJSONWithHeaders(router, "POST", "/tasks", ownerToken, header, gin.H{"title": "Bad", "priority": "urgent"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSONWithHeaders(router, "POST", "/tasks", ownerToken, header, gin.H{"title": "Bad", "priority": "urgent"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Equal(t, "true", resp.Header().Get(middleware.IdempotentReplayedHeader))
+
+		expiring := setupIdempotencyRouter(db, -time.Second)
+		header = map[string]string{middleware.IdempotencyKeyHeader: "expiring"}
+		doJSONWithHeaders(expiring, "POST", "/tasks", ownerToken, header, gin.H{"title": "Expired"})
+		resp = doJSONWithHeaders(expiring, "POST", "/tasks", ownerToken, header, gin.H{"title": "Expired"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Empty(t, resp.Header().Get(middleware.IdempotentReplayedHeader))
+		assert.Equal(t, int64(2), countTasks("Expired"))
+
+		n, err := services.NewIdempotencyService(time.Hour).DeleteExpired(db, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("A retried registration is replayed", func(t *testing.T) {
+		header := map[string]string{middleware.IdempotencyKeyHeader: "signup"}
+		payload := handlers.RegisterRequest{Username: "newcomer", Email: "newcomer@test.com", Password: "newcomer123"}
+
+		resp := doJSONWithHeaders(router, "POST", "/register", "", header, payload)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		// Without the key the retry would be a conflict on the username
+		resp = doJSONWithHeaders(router, "POST", "/register", "", header, payload)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Equal(t, "true", resp.Header().Get(middleware.IdempotentReplayedHeader))
+
+		var body map[string]string
+		json.Unmarshal(resp.Body.Bytes(), &body)
+		assert.Equal(t, "user created successfully", body["message"])
+
+		resp = doJSONWithHeaders(router, "POST", "/register", "", map[string]string{middleware.IdempotencyKeyHeader: strings.Repeat("k", 256)}, payload)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("A key reused for a different registration is rejected", func(t *testing.T) {
+		header := map[string]string{middleware.IdempotencyKeyHeader: "1"}
+
+		resp := doJSONWithHeaders(router, "POST", "/register", "", header, handlers.RegisterRequest{Username: "first", Email: "first@test.com", Password: "first123"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		resp = doJSONWithHeaders(router, "POST", "/register", "", header, handlers.RegisterRequest{Username: "second", Email: "second@test.com", Password: "second123"})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+		// Another client using the same key is not affected
+		header["X-Forwarded-For"] = "203.0.113.7"
+		resp = doJSONWithHeaders(router, "POST", "/register", "", header, handlers.RegisterRequest{Username: "third", Email: "third@test.com", Password: "third123"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Empty(t, resp.Header().Get(middleware.IdempotentReplayedHeader))
+
+		var usernames []string
+		db.Model(&models.User{}).Where("username IN ?", []string{"first", "second", "third"}).Order("username").Pluck("username", &usernames)
+		assert.Equal(t, []string{"first", "third"}, usernames)
+	})
+
+	t.Run("A handler that panics releases its key", func(t *testing.T) {
+		panicked := false
+		panicky := gin.New()
+		panicky.Use(middleware.ErrorHandler(), middleware.Recovery())
+		panicky.POST("/tasks", middleware.AuthMiddleware(), middleware.Idempotency(db, services.NewIdempotencyService(time.Hour)), func(c *gin.Context) {
+			if !panicked {
+				panicked = true
+				panic("lost connection")
+			}
+			c.JSON(http.StatusCreated, gin.H{"ok": true})
+		})
+		header := map[string]string{middleware.IdempotencyKeyHeader: "panic"}
+
+		resp := doJSONWithHeaders(panicky, "POST", "/tasks", ownerToken, header, gin.H{"title": "Panic"})
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+		resp = doJSONWithHeaders(panicky, "POST", "/tasks", ownerToken, header, gin.H{"title": "Panic"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Empty(t, resp.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+
+	t.Run("Keys of requests that never finished are taken over after the lock timeout", func(t *testing.T) {
+		stuck := func(key string, createdAt time.Time) {
+			assert.NoError(t, db.Create(&models.IdempotencyKey{
+				ID: uuid.Must(uuid.NewV4()), UserID: ownerID, Key: key, RequestHash: "unknown",
+				Method: "POST", Path: "/tasks", CreatedAt: createdAt, ExpiresAt: time.Now().Add(time.Hour),
+			}).Error)
+		}
+		stuck("running", time.Now())
+		stuck("abandoned", time.Now().Add(-2*time.Minute))
+
+		// A live claim is kept, so the request is checked against it
+		resp := doJSONWithHeaders(router, "POST", "/tasks", ownerToken, map[string]string{middleware.IdempotencyKeyHeader: "running"}, gin.H{"title": "Running"})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Equal(t, int64(0), countTasks("Running"))
+
+		resp = doJSONWithHeaders(router, "POST", "/tasks", ownerToken, map[string]string{middleware.IdempotencyKeyHeader: "abandoned"}, gin.H{"title": "Abandoned"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Equal(t, int64(1), countTasks("Abandoned"))
+	})
+}
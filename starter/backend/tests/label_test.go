@@ -0,0 +1,222 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupLabelRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService()
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	labelHandler := handlers.NewLabelHandler(db, services.NewLabelService(), taskService)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.GET("/:id", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskByID)
+		taskRoutes.PUT("/:id/labels/:label_id", middleware.RequirePermission("tasks", "update"), labelHandler.AddTaskLabel)
+		taskRoutes.DELETE("/:id/labels/:label_id", middleware.RequirePermission("tasks", "update"), labelHandler.RemoveTaskLabel)
+	}
+
+	userRoutes := router.Group("/users")
+	userRoutes.Use(middleware.AuthMiddleware())
+	{
+		userRoutes.GET("/:user_id/tasks", middleware.RequirePermission("tasks", "read"), taskHandler.GetTasksByUser)
+	}
+
+	labelRoutes := router.Group("/labels")
+	labelRoutes.Use(middleware.AuthMiddleware())
+	{
+		labelRoutes.POST("", labelHandler.CreateLabel)
+		labelRoutes.GET("", labelHandler.GetLabels)
+		labelRoutes.PUT("/:label_id", labelHandler.UpdateLabel)
+		labelRoutes.DELETE("/:label_id", labelHandler.DeleteLabel)
+		labelRoutes.POST("/apply", middleware.RequirePermission("tasks", "update"), labelHandler.ApplyLabels)
+		labelRoutes.POST("/remove", middleware.RequirePermission("tasks", "update"), labelHandler.RemoveLabels)
+	}
+
+	adminRoutes := router.Group("/admin")
+	adminRoutes.Use(middleware.AuthMiddleware(), middleware.RequireRole("admin"))
+	{
+		adminRoutes.GET("/labels/usage", labelHandler.GetLabelUsage)
+	}
+
+	return router
+}
+
+func TestLabels(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupLabelRouter(db)
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	memberID, memberToken := createTestUser(t, db, "member", "member@test.com", "member123", false)
+	_, strangerToken := createTestUser(t, db, "stranger", "stranger@test.com", "stranger123", false)
+	_, adminToken := createTestUser(t, db, "admin", "admin@test.com", "admin123", true)
+
+	teamService := services.NewTeamService()
+	team := models.Team{Name: "Platform"}
+	assert.NoError(t, teamService.CreateTeam(db, &team, ownerID))
+	_, err := teamService.SetMember(db, team.ID, memberID, models.TeamRoleMember)
+	assert.NoError(t, err)
+
+	var tasks []models.Task
+	for _, title := range []string{"Crash on login", "Slow search", "Write docs"} {
+		task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: title, UserID: ownerID, Status: "pending"}
+		db.Create(&task)
+		tasks = append(tasks, task)
+	}
+
+	createLabel := func(token string, payload gin.H) (models.Label, int) {
+		resp := doJSON(router, "POST", "/labels", token, payload)
+		var label models.Label
+		json.Unmarshal(resp.Body.Bytes(), &label)
+		return label, resp.Code
+	}
+
+	bug, code := createLabel(ownerToken, gin.H{"name": "Bug", "color": "#D73A4A"})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "#d73a4a", bug.Color)
+	urgent, code := createLabel(ownerToken, gin.H{"name": "urgent"})
+	assert.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, models.DefaultLabelColor, urgent.Color)
+
+	t.Run("Label names are validated and unique per owner", func(t *testing.T) {
+		_, code := createLabel(ownerToken, gin.H{"name": "bug"})
+		assert.Equal(t, http.StatusConflict, code)
+		_, code = createLabel(ownerToken, gin.H{"name": "a,b"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		_, code = createLabel(ownerToken, gin.H{"name": "red", "color": "red"})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		// Another user may have a label with the same name
+		_, code = createLabel(strangerToken, gin.H{"name": "bug"})
+		assert.Equal(t, http.StatusCreated, code)
+	})
+
+	var teamLabel models.Label
+
+	t.Run("Only team admins create team labels, members can use them", func(t *testing.T) {
+		_, code := createLabel(memberToken, gin.H{"name": "platform", "team_id": team.ID})
+		assert.Equal(t, http.StatusForbidden, code)
+
+		teamLabel, code = createLabel(ownerToken, gin.H{"name": "platform", "team_id": team.ID})
+		assert.Equal(t, http.StatusCreated, code)
+		assert.Nil(t, teamLabel.UserID)
+
+		var labels []models.Label
+		resp := doJSON(router, "GET", "/labels", memberToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &labels)
+		assert.Len(t, labels, 1)
+		assert.Equal(t, teamLabel.ID, labels[0].ID)
+
+		resp = doJSON(router, "PUT", "/labels/"+teamLabel.ID.String(), memberToken, gin.H{"name": "infra"})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		resp = doJSON(router, "PUT", "/labels/"+bug.ID.String(), strangerToken, gin.H{"name": "mine"})
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Bulk apply is all or nothing", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/labels/apply", memberToken, gin.H{
+			"task_ids":  []uuid.UUID{tasks[0].ID},
+			"label_ids": []uuid.UUID{teamLabel.ID},
+		})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "POST", "/labels/apply", ownerToken, gin.H{
+			"task_ids":  []uuid.UUID{tasks[0].ID, tasks[1].ID, uuid.Must(uuid.NewV4())},
+			"label_ids": []uuid.UUID{bug.ID},
+		})
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		var count int64
+		db.Model(&models.TaskLabel{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+
+		resp = doJSON(router, "POST", "/labels/apply", ownerToken, gin.H{
+			"task_ids":  []uuid.UUID{tasks[0].ID, tasks[1].ID},
+			"label_ids": []uuid.UUID{bug.ID, bug.ID},
+		})
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+
+		// Applying again is harmless
+		resp = doJSON(router, "PUT", "/tasks/"+tasks[0].ID.String()+"/labels/"+urgent.ID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		resp = doJSON(router, "PUT", "/tasks/"+tasks[0].ID.String()+"/labels/"+urgent.ID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var labels []models.Label
+		json.Unmarshal(resp.Body.Bytes(), &labels)
+		assert.Len(t, labels, 2)
+
+		var task models.Task
+		resp = doJSON(router, "GET", "/tasks/"+tasks[0].ID.String(), ownerToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &task)
+		assert.Len(t, task.Labels, 2)
+	})
+
+	t.Run("Task listings filter by label", func(t *testing.T) {
+		list := func(query string) []string {
+			resp := doJSON(router, "GET", "/users/"+ownerID.String()+"/tasks?"+query, ownerToken, nil)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var found []models.Task
+			json.Unmarshal(resp.Body.Bytes(), &found)
+			titles := []string{}
+			for _, task := range found {
+				titles = append(titles, task.Title)
+			}
+			return titles
+		}
+
+		assert.ElementsMatch(t, []string{"Crash on login", "Slow search"}, list("labels=BUG,urgent"))
+		assert.ElementsMatch(t, []string{"Crash on login"}, list("labels=bug,urgent&label_mode=all"))
+		assert.Empty(t, list("labels=nothing"))
+		assert.Len(t, list(""), 3)
+
+		resp := doJSON(router, "GET", "/users/"+ownerID.String()+"/tasks?labels=bug&label_mode=some", ownerToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Admins see usage counts", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/admin/labels/usage", ownerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "GET", "/admin/labels/usage", adminToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var usage []services.LabelUsage
+		json.Unmarshal(resp.Body.Bytes(), &usage)
+		assert.Len(t, usage, 4)
+		assert.Equal(t, bug.ID, usage[0].ID)
+		assert.Equal(t, int64(2), usage[0].TaskCount)
+		assert.Equal(t, int64(1), usage[1].TaskCount)
+	})
+
+	t.Run("Removing and deleting labels", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/labels/remove", ownerToken, gin.H{
+			"task_ids":  []uuid.UUID{tasks[1].ID},
+			"label_ids": []uuid.UUID{bug.ID},
+		})
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+
+		resp = doJSON(router, "DELETE", "/tasks/"+tasks[0].ID.String()+"/labels/"+urgent.ID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+
+		resp = doJSON(router, "DELETE", "/labels/"+bug.ID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+
+		var count int64
+		db.Model(&models.TaskLabel{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+}
@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/logging"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// logLines decodes the JSON lines written to the buffer
+func logLines(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(buffer.Bytes()))
+	for scanner.Scan() {
+		var line map[string]interface{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestStructuredLogging(t *testing.T) {
+	db := setupABACTestDB(t)
+
+	t.Run("Levels are parsed by name", func(t *testing.T) {
+		level, err := logging.ParseLevel("warn")
+		assert.NoError(t, err)
+		assert.Equal(t, slog.LevelWarn, level)
+
+		_, err = logging.ParseLevel("verbose")
+		assert.Error(t, err)
+	})
+
+	t.Run("Secrets and personal data are redacted", func(t *testing.T) {
+		var buffer bytes.Buffer
+		logger := logging.New(&buffer, slog.LevelInfo)
+
+		logger.Info("login",
+			"password", "hunter2",
+			"refresh_token", "rt-123",
+			"user", struct {
+				Username string `json:"username"`
+				Email    string `json:"email"`
+			}{"alice", "alice@example.com"},
+			"data", json.RawMessage(`{"task": {"title": "Plan"}, "headers": {"Authorization": "Bearer abc"}}`),
+		)
+
+		output := buffer.String()
+		for _, secret := range []string{"hunter2", "rt-123", "alice@example.com", "Bearer abc"} {
+			assert.NotContains(t, output, secret)
+		}
+		line := logLines(t, &buffer)[0]
+		assert.Equal(t, logging.Redacted, line["password"])
+		assert.Equal(t, "alice", line["user"].(map[string]interface{})["username"])
+		assert.Equal(t, "Plan", line["data"].(map[string]interface{})["task"].(map[string]interface{})["title"])
+	})
+
+	t.Run("Requests and their queries carry the request ID", func(t *testing.T) {
+		var buffer bytes.Buffer
+		logger := logging.New(&buffer, slog.LevelDebug)
+		previous := slog.Default()
+		slog.SetDefault(logger)
+		t.Cleanup(func() { slog.SetDefault(previous) })
+
+		loggedDB := db.Session(&gorm.Session{Logger: logging.NewGormLogger(logger, time.Second)})
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.Use(middleware.RequestInfoMiddleware())
+		router.Use(middleware.RequestLogger())
+		router.Use(middleware.ErrorHandler())
+		router.POST("/register", handlers.NewRegisterHandler(loggedDB, services.NewRegisterService()).Registration)
+
+		resp := doJSONWithHeaders(router, "POST", "/register", "", map[string]string{middleware.RequestIDHeader: "req-log-1"},
+			gin.H{"username": "logged", "email": "logged@example.com", "password": "s3cret-pass"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		var queries int
+		var request map[string]interface{}
+		for _, line := range logLines(t, &buffer) {
+			switch line["msg"] {
+			case "query":
+				queries++
+				assert.Equal(t, "req-log-1", line["request_id"])
+			case "request":
+				request = line
+			}
+		}
+		assert.Greater(t, queries, 0)
+		assert.Equal(t, "req-log-1", request["request_id"])
+		assert.Equal(t, "/register", request["route"])
+		assert.Equal(t, float64(http.StatusCreated), request["status"])
+
+		// Query parameters are left out, so the log holds neither the email nor the password hash
+		assert.False(t, strings.Contains(buffer.String(), "logged@example.com"))
+		assert.False(t, strings.Contains(buffer.String(), "$2a$"))
+	})
+}
@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/metrics"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/repositories"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// metricValue returns the value of the series with exactly the given labels,
+// or the sample count for histograms. Missing series count as zero.
+func metricValue(t *testing.T, gatherer prometheus.Gatherer, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := gatherer.Gather()
+	assert.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if !hasLabels(metric, labels) {
+				continue
+			}
+			switch {
+			case metric.GetCounter() != nil:
+				return metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				return metric.GetGauge().GetValue()
+			case metric.GetHistogram() != nil:
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	if len(metric.GetLabel()) != len(labels) {
+		return false
+	}
+	for _, pair := range metric.GetLabel() {
+		if labels[pair.GetName()] != pair.GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMetrics(t *testing.T) {
+	db := setupABACTestDB(t)
+	assert.NoError(t, metrics.InstrumentGorm(db))
+	_, userToken := createTestUser(t, db, "metrics-user", "metrics-user@test.com", "user123", false)
+	assert.NoError(t, services.NewRegisterService().RegisterUser(db, models.User{Username: "metrics-login", Email: "metrics-login@test.com", Password: "login123"}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Metrics())
+	router.Use(middleware.ErrorHandler())
+	authHandler := handlers.NewAuthHandler(db, services.NewAuthService())
+	taskHandler := handlers.NewTaskHandler(db, services.NewTaskService())
+	router.POST("/token", authHandler.Token)
+	router.GET("/tasks/:id", middleware.AuthMiddleware(), middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskByID)
+	router.GET("/admin", middleware.AuthMiddleware(), middleware.RequireRole("admin"), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	// A token that only grants reading, so every bulk operation is refused
+	readOnly := func(c *gin.Context) { c.Set("permissions", []string{"tasks:read"}) }
+	router.POST("/tasks/bulk", middleware.AuthMiddleware(), readOnly, taskHandler.BulkTasks)
+
+	t.Run("Requests are labelled by route template and status", func(t *testing.T) {
+		labels := map[string]string{"method": "GET", "route": "/tasks/:id", "status": "404"}
+		before := metricValue(t, metrics.Registry, "taskmanager_http_requests_total", labels)
+		beforeDuration := metricValue(t, metrics.Registry, "taskmanager_http_request_duration_seconds", labels)
+
+		for i := 0; i < 2; i++ {
+			resp := doJSON(router, "GET", "/tasks/"+uuid.Must(uuid.NewV4()).String(), userToken, nil)
+			assert.Equal(t, http.StatusNotFound, resp.Code)
+		}
+		resp := doJSON(router, "GET", "/nowhere", userToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		assert.Equal(t, before+2, metricValue(t, metrics.Registry, "taskmanager_http_requests_total", labels))
+		assert.Equal(t, beforeDuration+2, metricValue(t, metrics.Registry, "taskmanager_http_request_duration_seconds", labels))
+		assert.Greater(t, metricValue(t, metrics.Registry, "taskmanager_http_requests_total", map[string]string{"method": "GET", "route": "unmatched", "status": "404"}), 0.0)
+		assert.Greater(t, metricValue(t, metrics.Registry, "taskmanager_db_query_duration_seconds", map[string]string{"operation": "query"}), 0.0)
+	})
+
+	t.Run("Logins are counted by result", func(t *testing.T) {
+		success := metricValue(t, metrics.Registry, "taskmanager_auth_logins_total", map[string]string{"result": "success"})
+		failure := metricValue(t, metrics.Registry, "taskmanager_auth_logins_total", map[string]string{"result": "failure"})
+
+		resp := doJSON(router, "POST", "/token", "", gin.H{"username": "metrics-login", "password": "wrong"})
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		resp = doJSON(router, "POST", "/token", "", gin.H{"username": "metrics-login", "password": "login123"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		assert.Equal(t, success+1, metricValue(t, metrics.Registry, "taskmanager_auth_logins_total", map[string]string{"result": "success"}))
+		assert.Equal(t, failure+1, metricValue(t, metrics.Registry, "taskmanager_auth_logins_total", map[string]string{"result": "failure"}))
+	})
+
+	t.Run("Authorization denials are counted by permission", func(t *testing.T) {
+		labels := map[string]string{"permission": "role:admin"}
+		before := metricValue(t, metrics.Registry, "taskmanager_authorization_denials_total", labels)
+
+		resp := doJSON(router, "GET", "/admin", userToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		assert.Equal(t, before+1, metricValue(t, metrics.Registry, "taskmanager_authorization_denials_total", labels))
+
+		labels = map[string]string{"permission": "tasks:create"}
+		before = metricValue(t, metrics.Registry, "taskmanager_authorization_denials_total", labels)
+		resp = doJSON(router, "POST", "/tasks/bulk", userToken, gin.H{"mode": "best_effort", "operations": []gin.H{
+			{"op": "create", "task": gin.H{"title": "Refused"}},
+			{"op": "create", "task": gin.H{"title": "Refused too"}},
+		}})
+		assert.Equal(t, http.StatusMultiStatus, resp.Code)
+		assert.Equal(t, before+2, metricValue(t, metrics.Registry, "taskmanager_authorization_denials_total", labels))
+	})
+
+	t.Run("Tasks are counted by status across tenants", func(t *testing.T) {
+		userID := uuid.Must(uuid.NewV4())
+		for _, status := range []string{models.TaskStatusPending, models.TaskStatusPending, models.TaskStatusCompleted} {
+			assert.NoError(t, db.Create(&models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Counted", UserID: userID, Status: status}).Error)
+		}
+
+		registry := prometheus.NewRegistry()
+		systemDB := db.WithContext(repositories.WithoutTenantScope(context.Background()))
+		registry.MustRegister(metrics.NewTaskCollector(systemDB, time.Second))
+
+		assert.Equal(t, 2.0, metricValue(t, registry, "taskmanager_tasks", map[string]string{"status": models.TaskStatusPending}))
+		assert.Equal(t, 1.0, metricValue(t, registry, "taskmanager_tasks", map[string]string{"status": models.TaskStatusCompleted}))
+		assert.Equal(t, 0.0, metricValue(t, registry, "taskmanager_tasks", map[string]string{"status": models.TaskStatusReview}))
+	})
+
+	t.Run("Scrapes need the token when one is set", func(t *testing.T) {
+		handler := metrics.Handler("scrape-secret")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		req.Header.Set("Authorization", "Bearer scrape-secret")
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, strings.Contains(rec.Body.String(), "taskmanager_http_requests_total"))
+	})
+}
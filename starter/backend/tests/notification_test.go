@@ -0,0 +1,184 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupNotificationRouter(db *gorm.DB, notificationService services.NotificationService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService()
+	teamService := services.NewTeamService()
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	taskShareHandler := handlers.NewTaskShareHandler(db, taskService, services.NewTaskShareService())
+	commentHandler := handlers.NewCommentHandler(db, taskService, services.NewCommentService())
+	teamHandler := handlers.NewTeamHandler(db, teamService, taskService)
+	notificationHandler := handlers.NewNotificationHandler(db, notificationService)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.POST("/:id/shares", middleware.RequirePermission("tasks", "update"), taskShareHandler.ShareTask)
+		taskRoutes.POST("/:id/comments", middleware.RequirePermission("tasks", "read"), commentHandler.CreateComment)
+	}
+
+	teamRoutes := router.Group("/teams")
+	teamRoutes.Use(middleware.AuthMiddleware())
+	{
+		teamRoutes.POST("", teamHandler.CreateTeam)
+		teamRoutes.POST("/:team_id/members", middleware.RequireTeamRole(db, teamService, models.TeamRoleAdmin), teamHandler.SetMember)
+	}
+
+	notificationRoutes := router.Group("/notifications")
+	notificationRoutes.Use(middleware.AuthMiddleware())
+	{
+		notificationRoutes.GET("", notificationHandler.GetNotifications)
+		notificationRoutes.GET("/unread-count", notificationHandler.GetUnreadCount)
+		notificationRoutes.POST("/read-all", notificationHandler.MarkAllRead)
+		notificationRoutes.POST("/:notification_id/read", notificationHandler.MarkRead)
+	}
+
+	return router
+}
+
+type notificationPage struct {
+	Items  []models.Notification `json:"items"`
+	Total  int64                 `json:"total"`
+	Unread int64                 `json:"unread"`
+}
+
+func TestNotifications(t *testing.T) {
+	db := setupABACTestDB(t)
+	notificationService := services.NewNotificationService()
+	router := setupNotificationRouter(db, notificationService)
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	friendID, friendToken := createTestUser(t, db, "friend", "friend@test.com", "friend123", false)
+	_, adminToken := createTestUser(t, db, "admin", "admin@test.com", "admin123", true)
+
+	inbox := func(token, query string) notificationPage {
+		resp := doJSON(router, "GET", "/notifications"+query, token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var page notificationPage
+		json.Unmarshal(resp.Body.Bytes(), &page)
+		return page
+	}
+
+	var task models.Task
+	resp := doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Write report"})
+	json.Unmarshal(resp.Body.Bytes(), &task)
+
+	t.Run("Sharing a task notifies the user it is shared with", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/tasks/"+task.ID.String()+"/shares", ownerToken, gin.H{
+			"subject_type": "user", "subject_id": friendID, "permission": "editor",
+		})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		page := inbox(friendToken, "")
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, models.NotificationTaskShared, page.Items[0].Type)
+		assert.Equal(t, task.ID, *page.Items[0].TaskID)
+		assert.Equal(t, ownerID, *page.Items[0].ActorID)
+		assert.Equal(t, int64(1), page.Unread)
+
+		// Sharing again at the same level says nothing new
+		doJSON(router, "POST", "/tasks/"+task.ID.String()+"/shares", ownerToken, gin.H{
+			"subject_type": "user", "subject_id": friendID, "permission": "editor",
+		})
+		assert.Equal(t, int64(1), inbox(friendToken, "").Total)
+	})
+
+	t.Run("Comments notify the owner and mentioned users, never the author", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/tasks/"+task.ID.String()+"/comments", friendToken, gin.H{"body": "First draft is up"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		page := inbox(ownerToken, "")
+		assert.Len(t, page.Items, 1)
+		assert.Equal(t, models.NotificationTaskCommented, page.Items[0].Type)
+		assert.Equal(t, "First draft is up", page.Items[0].Body)
+
+		doJSON(router, "POST", "/tasks/"+task.ID.String()+"/comments", friendToken, gin.H{"body": "@owner please review"})
+		page = inbox(ownerToken, "")
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, models.NotificationTaskMentioned, page.Items[0].Type)
+
+		doJSON(router, "POST", "/tasks/"+task.ID.String()+"/comments", ownerToken, gin.H{"body": "Thanks @friend"})
+		page = inbox(friendToken, "")
+		assert.Equal(t, int64(2), page.Total)
+		assert.Equal(t, models.NotificationTaskMentioned, page.Items[0].Type)
+		assert.Equal(t, int64(2), inbox(ownerToken, "").Total)
+	})
+
+	t.Run("Reassigning a task notifies the new owner", func(t *testing.T) {
+		resp := doJSON(router, "PATCH", "/tasks/"+task.ID.String(), adminToken, gin.H{"user_id": friendID})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		page := inbox(friendToken, "")
+		assert.Equal(t, models.NotificationTaskAssigned, page.Items[0].Type)
+		assert.Equal(t, "Task assigned to you: Write report", page.Items[0].Title)
+	})
+
+	t.Run("Team role changes are announced", func(t *testing.T) {
+		var team models.Team
+		resp := doJSON(router, "POST", "/teams", ownerToken, gin.H{"name": "Writers"})
+		json.Unmarshal(resp.Body.Bytes(), &team)
+
+		doJSON(router, "POST", "/teams/"+team.ID.String()+"/members", ownerToken, gin.H{"user_id": friendID, "role": "member"})
+		doJSON(router, "POST", "/teams/"+team.ID.String()+"/members", ownerToken, gin.H{"user_id": friendID, "role": "admin"})
+
+		page := inbox(friendToken, "")
+		assert.Equal(t, models.NotificationRoleChanged, page.Items[0].Type)
+		assert.Equal(t, "Your role in team Writers is now admin", page.Items[0].Title)
+		assert.Equal(t, models.NotificationRoleChanged, page.Items[1].Type)
+	})
+
+	t.Run("Notifications are marked read one by one or all at once", func(t *testing.T) {
+		page := inbox(friendToken, "?unread=true&page_size=2")
+		assert.Len(t, page.Items, 2)
+		assert.Equal(t, int64(5), page.Total)
+
+		resp := doJSON(router, "POST", "/notifications/"+page.Items[0].ID.String()+"/read", friendToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Equal(t, int64(4), inbox(friendToken, "?unread=true").Total)
+		assert.Equal(t, int64(5), inbox(friendToken, "").Total)
+
+		// Other users' notifications cannot be touched
+		resp = doJSON(router, "POST", "/notifications/"+page.Items[1].ID.String()+"/read", ownerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		resp = doJSON(router, "POST", "/notifications/read-all", friendToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.JSONEq(t, `{"updated": 4}`, resp.Body.String())
+
+		resp = doJSON(router, "GET", "/notifications/unread-count", friendToken, nil)
+		assert.JSONEq(t, `{"unread": 0}`, resp.Body.String())
+		resp = doJSON(router, "GET", "/notifications/unread-count", ownerToken, nil)
+		assert.JSONEq(t, `{"unread": 2}`, resp.Body.String())
+	})
+
+	t.Run("Old notifications are deleted", func(t *testing.T) {
+		old := models.Notification{ID: uuid.Must(uuid.NewV4()), UserID: ownerID, Type: models.NotificationTaskShared, Title: "Old", CreatedAt: time.Now().AddDate(0, -6, 0)}
+		db.Create(&old)
+
+		deleted, err := notificationService.DeleteExpired(db, time.Now().AddDate(0, 0, -90))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		assert.Equal(t, int64(2), inbox(ownerToken, "").Total)
+	})
+}
@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/outbox"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// recordingSink keeps the events it publishes, or fails while failing is set
+type recordingSink struct {
+	id      string
+	mu      sync.Mutex
+	events  []outbox.Event
+	failing bool
+}
+
+func (s *recordingSink) ID() string {
+	return s.id
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event outbox.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failing {
+		return errors.New("sink unavailable")
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingSink) fail(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+func (s *recordingSink) published() []outbox.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]outbox.Event(nil), s.events...)
+}
+
+// streamRecorder stands in for a NATS or Kafka producer
+type streamRecorder struct {
+	messages []outbox.StreamMessage
+}
+
+func (p *streamRecorder) Publish(ctx context.Context, msg outbox.StreamMessage) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func outboxEvents(db *gorm.DB, eventType string) []models.OutboxEvent {
+	var events []models.OutboxEvent
+	db.Where("type = ?", eventType).Order("created_at").Find(&events)
+	return events
+}
+
+func TestOutbox(t *testing.T) {
+	db := setupABACTestDB(t)
+	taskService := services.NewTaskService()
+	ownerID, _ := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	memberID, _ := createTestUser(t, db, "member", "member@test.com", "member123", false)
+
+	t.Run("Events are recorded with the change", func(t *testing.T) {
+		task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Plan release", UserID: ownerID}
+		assert.NoError(t, taskService.CreateTask(db, &task))
+
+		events := outboxEvents(db, models.EventTaskCreated)
+		assert.Len(t, events, 1)
+		assert.Equal(t, models.AggregateTask, events[0].AggregateType)
+		assert.Equal(t, task.ID, events[0].AggregateID)
+		assert.Nil(t, events[0].PublishedAt)
+		var data models.Task
+		assert.NoError(t, json.Unmarshal([]byte(events[0].Payload), &data))
+		assert.Equal(t, "Plan release", data.Title)
+	})
+
+	t.Run("Rolled back changes leave no event", func(t *testing.T) {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Never happened", UserID: ownerID}
+			if err := taskService.CreateTask(tx, &task); err != nil {
+				return err
+			}
+			return errors.New("abort")
+		})
+		assert.EqualError(t, err, "abort")
+		assert.Len(t, outboxEvents(db, models.EventTaskCreated), 1)
+	})
+
+	t.Run("Role changes and user deletions are recorded", func(t *testing.T) {
+		teamService := services.NewTeamService()
+		team := models.Team{Name: "Platform"}
+		assert.NoError(t, teamService.CreateTeam(db, &team, ownerID))
+		_, err := teamService.SetMember(db, team.ID, memberID, models.TeamRoleMember)
+		assert.NoError(t, err)
+		assert.NoError(t, teamService.RemoveMember(db, team.ID, memberID))
+
+		events := outboxEvents(db, models.EventRoleChanged)
+		assert.Len(t, events, 2)
+		assert.JSONEq(t, `{"user_id": "`+memberID.String()+`", "scope": "team", "scope_id": "`+team.ID.String()+`", "role": "member"}`, events[0].Payload)
+		assert.JSONEq(t, `{"user_id": "`+memberID.String()+`", "scope": "team", "scope_id": "`+team.ID.String()+`", "role": ""}`, events[1].Payload)
+
+		assert.NoError(t, services.NewUserService().DeleteUser(db, memberID))
+		events = outboxEvents(db, models.EventUserDeleted)
+		assert.Len(t, events, 1)
+		assert.Equal(t, models.AggregateUser, events[0].AggregateType)
+		assert.Equal(t, memberID, events[0].AggregateID)
+		assert.Contains(t, events[0].Payload, `"username":"member"`)
+	})
+
+	t.Run("Every sink receives every event once", func(t *testing.T) {
+		first := &recordingSink{id: "first"}
+		second := &recordingSink{id: "second"}
+		relay := services.NewOutboxService(first, second)
+
+		n, err := relay.RelayPending(db, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 4, n)
+		assert.Len(t, first.published(), 4)
+		assert.Len(t, second.published(), 4)
+		assert.Equal(t, models.EventTaskCreated, first.published()[0].Type)
+
+		var pending int64
+		db.Model(&models.OutboxEvent{}).Where("published_at IS NULL").Count(&pending)
+		assert.Equal(t, int64(0), pending)
+
+		n, err = relay.RelayPending(db, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Len(t, first.published(), 4)
+	})
+
+	t.Run("Failed sinks are retried without repeating the others", func(t *testing.T) {
+		healthy := &recordingSink{id: "healthy"}
+		flaky := &recordingSink{id: "flaky", failing: true}
+		relay := services.NewOutboxService(healthy, flaky)
+
+		task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Ship it", UserID: ownerID}
+		assert.NoError(t, taskService.CreateTask(db, &task))
+		// Only the new event is pending for these sinks
+		db.Model(&models.OutboxEvent{}).Where("aggregate_id <> ?", task.ID).Update("published_at", time.Now())
+
+		now := time.Now()
+		n, err := relay.RelayPending(db, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Len(t, healthy.published(), 1)
+
+		var event models.OutboxEvent
+		db.First(&event, "aggregate_id = ? AND type = ?", task.ID, models.EventTaskCreated)
+		assert.Equal(t, 1, event.Attempts)
+		assert.Contains(t, event.LastError, "flaky: sink unavailable")
+		assert.True(t, event.NextAttemptAt.After(now))
+
+		// Not due yet
+		n, _ = relay.RelayPending(db, now)
+		assert.Equal(t, 0, n)
+
+		flaky.fail(false)
+		n, err = relay.RelayPending(db, event.NextAttemptAt)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Len(t, healthy.published(), 1)
+		assert.Len(t, flaky.published(), 1)
+		assert.Equal(t, healthy.published()[0].ID, flaky.published()[0].ID)
+	})
+
+	t.Run("Webhook sinks send the event ID as idempotency key", func(t *testing.T) {
+		receiver := &webhookReceiver{status: http.StatusInternalServerError}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Notify partners", UserID: ownerID}
+		assert.NoError(t, taskService.CreateTask(db, &task))
+		db.Model(&models.OutboxEvent{}).Where("aggregate_id <> ?", task.ID).Update("published_at", time.Now())
+		relay := services.NewOutboxService(outbox.NewWebhookSink("partner", server.URL, "s3cret", time.Second))
+
+		n, err := relay.RelayPending(db, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+
+		receiver.answer(http.StatusOK)
+		n, err = relay.RelayPending(db, time.Now().Add(time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		requests := receiver.received()
+		assert.Len(t, requests, 2)
+		key := requests[0].header.Get("Idempotency-Key")
+		assert.NotEmpty(t, key)
+		assert.Equal(t, key, requests[1].header.Get("Idempotency-Key"))
+		assert.Equal(t, models.EventTaskCreated, requests[1].header.Get("X-Outbox-Event"))
+		assert.NotEmpty(t, requests[1].header.Get("X-Outbox-Signature"))
+
+		var event outbox.Event
+		assert.NoError(t, json.Unmarshal(requests[1].body, &event))
+		assert.Equal(t, key, event.ID.String())
+		assert.Equal(t, task.ID, event.AggregateID)
+	})
+
+	t.Run("Stream sinks publish by event type keyed by aggregate", func(t *testing.T) {
+		producer := &streamRecorder{}
+		sink := outbox.NewStreamSink("stream", "taskmanager", producer)
+		event := outbox.Event{ID: uuid.Must(uuid.NewV4()), Type: models.EventTaskUpdated, AggregateID: uuid.Must(uuid.NewV4()), Data: json.RawMessage(`{}`)}
+		assert.NoError(t, sink.Publish(context.Background(), event))
+
+		assert.Len(t, producer.messages, 1)
+		assert.Equal(t, "taskmanager.task.updated", producer.messages[0].Subject)
+		assert.Equal(t, event.ID.String(), producer.messages[0].ID)
+		assert.Equal(t, event.AggregateID.String(), producer.messages[0].Key)
+	})
+
+	t.Run("Published events are deleted after retention", func(t *testing.T) {
+		var before int64
+		db.Model(&models.OutboxEvent{}).Count(&before)
+		relay := services.NewOutboxService()
+
+		n, err := relay.DeletePublished(db, time.Now().Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+
+		n, err = relay.DeletePublished(db, time.Now().Add(2*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, before, n)
+		var deliveries int64
+		db.Model(&models.OutboxDelivery{}).Count(&deliveries)
+		assert.Equal(t, int64(0), deliveries)
+	})
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupProjectRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService()
+	projectService := services.NewProjectService()
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	projectHandler := handlers.NewProjectHandler(db, projectService, taskService)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.GET("/:id", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskByID)
+	}
+
+	projectRoutes := router.Group("/projects")
+	projectRoutes.Use(middleware.AuthMiddleware())
+	{
+		projectRoutes.POST("", projectHandler.CreateProject)
+		projectRoutes.GET("", projectHandler.GetProjects)
+		projectRoutes.GET("/:project_id", middleware.RequireProjectAccess(db, projectService, models.TaskAccessViewer), projectHandler.GetProject)
+		projectRoutes.PUT("/:project_id", middleware.RequireProjectAccess(db, projectService, models.TaskAccessOwner), projectHandler.UpdateProject)
+		projectRoutes.DELETE("/:project_id", middleware.RequireProjectAccess(db, projectService, models.TaskAccessOwner), projectHandler.DeleteProject)
+		projectRoutes.POST("/:project_id/members", middleware.RequireProjectAccess(db, projectService, models.TaskAccessOwner), projectHandler.SetMember)
+		projectRoutes.GET("/:project_id/tasks", middleware.RequirePermission("tasks", "read"), middleware.RequireProjectAccess(db, projectService, models.TaskAccessViewer), projectHandler.GetProjectTasks)
+		projectRoutes.POST("/:project_id/tasks", middleware.RequirePermission("tasks", "create"), middleware.RequireProjectAccess(db, projectService, models.TaskAccessEditor), projectHandler.CreateProjectTask)
+	}
+
+	return router
+}
+
+func TestProjects(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupProjectRouter(db)
+	projectService := services.NewProjectService()
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	viewerID, viewerToken := createTestUser(t, db, "viewer", "viewer@test.com", "viewer123", false)
+	editorID, editorToken := createTestUser(t, db, "editor", "editor@test.com", "editor123", false)
+	_, strangerToken := createTestUser(t, db, "stranger", "stranger@test.com", "stranger123", false)
+
+	inbox, err := projectService.CreateInbox(db, ownerID)
+	assert.NoError(t, err)
+
+	var project models.Project
+
+	t.Run("Creator becomes project owner", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/projects", ownerToken, handlers.ProjectRequest{Name: "Launch"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &project)
+
+		permission, err := services.ProjectMemberPermission(db, project.ID, ownerID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.TaskAccessOwner, permission)
+	})
+
+	projectPath := "/projects/" + project.ID.String()
+
+	t.Run("Only project owners can add members", func(t *testing.T) {
+		resp := doJSON(router, "POST", projectPath+"/members", strangerToken, handlers.ProjectMemberRequest{UserID: viewerID, Permission: models.TaskAccessViewer})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "POST", projectPath+"/members", ownerToken, handlers.ProjectMemberRequest{UserID: viewerID, Permission: models.TaskAccessViewer})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = doJSON(router, "POST", projectPath+"/members", ownerToken, handlers.ProjectMemberRequest{UserID: editorID, Permission: models.TaskAccessEditor})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = doJSON(router, "POST", projectPath+"/members", ownerToken, handlers.ProjectMemberRequest{UserID: editorID, Permission: "admin"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	var task models.Task
+
+	t.Run("Editors create tasks, viewers only read them", func(t *testing.T) {
+		resp := doJSON(router, "POST", projectPath+"/tasks", viewerToken, map[string]interface{}{"title": "Nope", "status": "pending"})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "POST", projectPath+"/tasks", editorToken, map[string]interface{}{"title": "Write launch post", "status": "pending", "priority": "high"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &task)
+		assert.Equal(t, project.ID, *task.ProjectID)
+
+		assert.Equal(t, http.StatusOK, doJSON(router, "GET", "/tasks/"+task.ID.String(), viewerToken, nil).Code)
+		resp = doJSON(router, "PATCH", "/tasks/"+task.ID.String(), viewerToken, map[string]interface{}{"title": "Edited"})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Equal(t, http.StatusForbidden, doJSON(router, "GET", "/tasks/"+task.ID.String(), strangerToken, nil).Code)
+	})
+
+	t.Run("Project task listing accepts the task filters", func(t *testing.T) {
+		doJSON(router, "POST", projectPath+"/tasks", ownerToken, map[string]interface{}{"title": "Book venue", "status": "completed", "priority": "low"})
+
+		var tasks []models.Task
+		resp := doJSON(router, "GET", projectPath+"/tasks", viewerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &tasks)
+		assert.Len(t, tasks, 2)
+
+		tasks = nil
+		resp = doJSON(router, "GET", projectPath+"/tasks?priority=high", viewerToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &tasks)
+		assert.Len(t, tasks, 1)
+
+		tasks = nil
+		resp = doJSON(router, "GET", projectPath+"/tasks?q=venue", viewerToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &tasks)
+		assert.Len(t, tasks, 1)
+
+		assert.Equal(t, http.StatusForbidden, doJSON(router, "GET", projectPath+"/tasks", strangerToken, nil).Code)
+	})
+
+	t.Run("Tasks without a project land in the owner's inbox", func(t *testing.T) {
+		var created models.Task
+		resp := doJSON(router, "POST", "/tasks", ownerToken, map[string]interface{}{"title": "Loose end", "status": "pending"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &created)
+		assert.Equal(t, inbox.ID, *created.ProjectID)
+	})
+
+	t.Run("Archived projects reject new tasks", func(t *testing.T) {
+		resp := doJSON(router, "PUT", projectPath, editorToken, handlers.ProjectRequest{Name: "Launch", Archived: true})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "PUT", projectPath, ownerToken, handlers.ProjectRequest{Name: "Launch", Archived: true})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = doJSON(router, "POST", projectPath+"/tasks", editorToken, map[string]interface{}{"title": "Too late", "status": "pending"})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		var projects []models.Project
+		resp = doJSON(router, "GET", "/projects", ownerToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &projects)
+		assert.Len(t, projects, 1)
+
+		projects = nil
+		resp = doJSON(router, "GET", "/projects?archived=true", ownerToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &projects)
+		assert.Len(t, projects, 2)
+	})
+
+	t.Run("Inbox cannot be archived or deleted", func(t *testing.T) {
+		inboxPath := "/projects/" + inbox.ID.String()
+		resp := doJSON(router, "PUT", inboxPath, ownerToken, handlers.ProjectRequest{Name: "Inbox", Archived: true})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		resp = doJSON(router, "DELETE", inboxPath, ownerToken, nil)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("Deleting a project keeps its tasks", func(t *testing.T) {
+		resp := doJSON(router, "DELETE", projectPath, ownerToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+
+		var kept models.Task
+		assert.NoError(t, db.First(&kept, "id = ?", task.ID).Error)
+		assert.Nil(t, kept.ProjectID)
+	})
+}
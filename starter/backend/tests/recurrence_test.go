@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/rrule"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRRule(t *testing.T) {
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 9, 0, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		rule    string
+		dtstart time.Time
+		after   time.Time
+		next    time.Time
+	}{
+		{"FREQ=DAILY;INTERVAL=2", day(1, 1), day(1, 1), day(1, 3)},
+		{"FREQ=WEEKLY;BYDAY=MO,WE,FR", day(1, 1), day(1, 1), day(1, 3)},
+		{"FREQ=WEEKLY;BYDAY=MO,WE,FR", day(1, 1), day(1, 5), day(1, 8)},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH", day(1, 2), day(1, 4), day(1, 16)},
+		{"FREQ=WEEKLY", day(1, 1), day(1, 10), day(1, 15)},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", day(1, 31), day(1, 31), day(2, 29)},
+		{"FREQ=MONTHLY", day(1, 31), day(1, 31), day(3, 31)},
+		{"FREQ=MONTHLY;BYDAY=-1FR", day(1, 26), day(1, 26), day(2, 23)},
+		{"FREQ=MONTHLY;BYDAY=2TU", day(1, 9), day(1, 9), day(2, 13)},
+		{"FREQ=YEARLY", day(2, 29), day(2, 29), time.Date(2028, 2, 29, 9, 0, 0, 0, time.UTC)},
+		{"FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU", day(3, 31), day(3, 31), time.Date(2025, 3, 30, 9, 0, 0, 0, time.UTC)},
+		{"FREQ=WEEKLY;COUNT=3", day(1, 1), day(1, 8), day(1, 15)},
+		{"FREQ=DAILY;UNTIL=20240103", day(1, 1), day(1, 2), day(1, 3)},
+	}
+	for _, tc := range cases {
+		rule, err := rrule.Parse(tc.rule)
+		assert.NoError(t, err, tc.rule)
+		next, ok := rule.After(tc.dtstart, tc.after)
+		assert.True(t, ok, tc.rule)
+		assert.Equal(t, tc.next, next, tc.rule)
+	}
+
+	t.Run("Series end", func(t *testing.T) {
+		for rule, after := range map[string]time.Time{
+			"FREQ=WEEKLY;COUNT=3":                 day(1, 15),
+			"FREQ=DAILY;UNTIL=20240103":           day(1, 3),
+			"FREQ=DAILY;UNTIL=20240103T080000Z":   day(1, 2),
+			"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30": day(1, 1),
+		} {
+			parsed, err := rrule.Parse(rule)
+			assert.NoError(t, err, rule)
+			_, ok := parsed.After(day(1, 1), after)
+			assert.False(t, ok, rule)
+		}
+	})
+
+	t.Run("Invalid rules", func(t *testing.T) {
+		for _, rule := range []string{"", "INTERVAL=2", "FREQ=HOURLY", "FREQ=DAILY;BYDAY=1MO", "FREQ=DAILY;COUNT=2;UNTIL=20240101", "FREQ=WEEKLY;BYSETPOS=1", "FREQ=DAILY;FREQ=WEEKLY", "FREQ=MONTHLY;BYMONTHDAY=0"} {
+			_, err := rrule.Parse(rule)
+			assert.ErrorIs(t, err, rrule.ErrInvalidRule, rule)
+		}
+	})
+
+	t.Run("Rules are written in canonical form", func(t *testing.T) {
+		rule, err := rrule.Parse("rrule:byday=mo,fr;interval=2;freq=weekly")
+		assert.NoError(t, err)
+		assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", rule.String())
+		assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;UNTIL=20240105T090000Z;BYDAY=MO,FR", rule.WithUntil(day(1, 5)).String())
+	})
+}
+
+func setupRecurrenceRouter(db *gorm.DB, recurrenceService services.RecurrenceService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService()
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	recurrenceHandler := handlers.NewRecurrenceHandler(db, taskService, recurrenceService)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.PUT("/:id/recurrence", middleware.RequirePermission("tasks", "update"), recurrenceHandler.SetRecurrence)
+	}
+
+	return router
+}
+
+func TestRecurringTasks(t *testing.T) {
+	db := setupABACTestDB(t)
+	recurrenceService := services.NewRecurrenceService(24 * time.Hour)
+	router := setupRecurrenceRouter(db, recurrenceService)
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+
+	firstDue := time.Date(2030, 1, 7, 18, 0, 0, 0, time.UTC)
+	var first models.Task
+	resp := doJSON(router, "POST", "/tasks", ownerToken, gin.H{
+		"title": "Take out the bins", "status": "review", "due_date": firstDue, "recurrence": "freq=weekly",
+	})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	json.Unmarshal(resp.Body.Bytes(), &first)
+	seriesID := *first.SeriesID
+
+	label := models.Label{ID: uuid.Must(uuid.NewV4()), Name: "chores", Color: "#00ff00", UserID: &ownerID, CreatedBy: ownerID}
+	db.Create(&label)
+	db.Create(&models.TaskLabel{TaskID: first.ID, LabelID: label.ID, CreatedBy: ownerID})
+
+	occurrences := func(series uuid.UUID) []models.Task {
+		var tasks []models.Task
+		db.Preload("Labels").Where("series_id = ?", series).Order("occurrence_at").Find(&tasks)
+		return tasks
+	}
+
+	t.Run("Recurring tasks need a valid rule and a due date", func(t *testing.T) {
+		assert.Equal(t, "FREQ=WEEKLY", first.Recurrence)
+		assert.True(t, firstDue.Equal(*first.OccurrenceAt))
+
+		resp := doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Bad", "due_date": firstDue, "recurrence": "FREQ=SOMETIMES"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Undated", "recurrence": "FREQ=DAILY"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Completing an occurrence creates the next one", func(t *testing.T) {
+		resp := doJSON(router, "PATCH", "/tasks/"+first.ID.String(), ownerToken, gin.H{"status": "completed"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		tasks := occurrences(seriesID)
+		assert.Len(t, tasks, 2)
+		second := tasks[1]
+		assert.Equal(t, "Take out the bins", second.Title)
+		assert.Equal(t, models.TaskStatusPending, second.Status)
+		assert.True(t, firstDue.AddDate(0, 0, 7).Equal(*second.DueDate))
+		assert.Len(t, second.Labels, 1)
+
+		// Reopening and completing again does not add another occurrence
+		doJSON(router, "PATCH", "/tasks/"+first.ID.String(), ownerToken, gin.H{"status": "in_progress"})
+		doJSON(router, "PATCH", "/tasks/"+first.ID.String(), ownerToken, gin.H{"status": "review"})
+		doJSON(router, "PATCH", "/tasks/"+first.ID.String(), ownerToken, gin.H{"status": "completed"})
+		assert.Len(t, occurrences(seriesID), 2)
+	})
+
+	t.Run("The generator creates occurrences within the lead time once", func(t *testing.T) {
+		created, err := recurrenceService.GenerateDue(db, firstDue.AddDate(0, 0, 10))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, created)
+
+		created, err = recurrenceService.GenerateDue(db, firstDue.AddDate(0, 0, 13))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, created)
+
+		created, err = recurrenceService.GenerateDue(db, firstDue.AddDate(0, 0, 13))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, created)
+
+		// A deleted occurrence is not brought back
+		tasks := occurrences(seriesID)
+		assert.Len(t, tasks, 3)
+		db.Delete(&tasks[2])
+		created, err = recurrenceService.GenerateDue(db, firstDue.AddDate(0, 0, 13))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, created)
+		db.Unscoped().Model(&tasks[2]).Update("deleted_at", nil)
+	})
+
+	t.Run("Edits can apply to the following occurrences", func(t *testing.T) {
+		tasks := occurrences(seriesID)
+		resp := doJSON(router, "PATCH", "/tasks/"+tasks[1].ID.String()+"?scope=following", ownerToken, gin.H{"title": "Bins and recycling"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		tasks = occurrences(seriesID)
+		assert.Equal(t, "Take out the bins", tasks[0].Title)
+		assert.Equal(t, "Bins and recycling", tasks[1].Title)
+		assert.Equal(t, "Bins and recycling", tasks[2].Title)
+
+		resp = doJSON(router, "PATCH", "/tasks/"+tasks[1].ID.String()+"?scope=everything", ownerToken, gin.H{"title": "x"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Changing the schedule splits the series", func(t *testing.T) {
+		tasks := occurrences(seriesID)
+		second, third := tasks[1], tasks[2]
+
+		var updated models.Task
+		resp := doJSON(router, "PUT", "/tasks/"+second.ID.String()+"/recurrence", ownerToken, gin.H{"recurrence": "FREQ=DAILY"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &updated)
+		assert.NotEqual(t, seriesID, *updated.SeriesID)
+		assert.Equal(t, "FREQ=DAILY", updated.Recurrence)
+
+		// The old series keeps only the first occurrence and ends before the split
+		old := occurrences(seriesID)
+		assert.Len(t, old, 1)
+		assert.Contains(t, old[0].Recurrence, "UNTIL=")
+		assert.Error(t, db.First(&models.Task{}, "id = ?", third.ID).Error)
+
+		created, err := recurrenceService.GenerateDue(db, second.DueDate.AddDate(0, 0, 1))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, created)
+		daily := occurrences(*updated.SeriesID)
+		assert.Len(t, daily, 3)
+		assert.True(t, second.DueDate.AddDate(0, 0, 2).Equal(*daily[2].DueDate))
+	})
+
+	t.Run("An empty rule stops the series", func(t *testing.T) {
+		var last models.Task
+		db.Where("series_id IS NOT NULL").Order("occurrence_at DESC").First(&last)
+
+		resp := doJSON(router, "PUT", "/tasks/"+last.ID.String()+"/recurrence", ownerToken, gin.H{"recurrence": ""})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		created, err := recurrenceService.GenerateDue(db, last.DueDate.AddDate(1, 0, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, created)
+	})
+}
@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/notify"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// recordingNotifier keeps every message it is given and fails while failing is set
+type recordingNotifier struct {
+	mu       sync.Mutex
+	messages []notify.Message
+	failing  bool
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.failing {
+		return errors.New("relay unavailable")
+	}
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func (n *recordingNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.messages)
+}
+
+func setupReminderRouter(db *gorm.DB, reminderService services.ReminderService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	reminderHandler := handlers.NewReminderHandler(db, reminderService)
+
+	userRoutes := router.Group("/users")
+	userRoutes.Use(middleware.AuthMiddleware())
+	{
+		userRoutes.GET("/reminders", reminderHandler.GetPreferences)
+		userRoutes.PUT("/reminders", reminderHandler.UpdatePreferences)
+	}
+
+	return router
+}
+
+func TestReminders(t *testing.T) {
+	db := setupABACTestDB(t)
+
+	var hooks []notify.Message
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg notify.Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		hooks = append(hooks, msg)
+	}))
+	defer receiver.Close()
+
+	email := &recordingNotifier{}
+	reminderService := services.NewReminderService(map[string]notify.Notifier{
+		models.ReminderChannelInApp:   services.NewInAppNotifier(db, services.NewNotificationService()),
+		models.ReminderChannelEmail:   email,
+		models.ReminderChannelWebhook: notify.NewWebhookNotifier(5 * time.Second).WithClient(receiver.Client()),
+	}, 24*time.Hour)
+	router := setupReminderRouter(db, reminderService)
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	quietID, quietToken := createTestUser(t, db, "quiet", "quiet@test.com", "quiet123", false)
+
+	now := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+	newTask := func(userID uuid.UUID, title, status string, due time.Time) models.Task {
+		task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: title, Status: status, Priority: models.TaskPriorityMedium, UserID: userID, DueDate: &due}
+		assert.NoError(t, db.Create(&task).Error)
+		return task
+	}
+	soon := newTask(ownerID, "Send invoice", models.TaskStatusPending, now.Add(2*time.Hour))
+	newTask(ownerID, "Plan offsite", models.TaskStatusPending, now.Add(72*time.Hour))
+	late := newTask(ownerID, "File taxes", models.TaskStatusInProgress, now.Add(-time.Hour))
+	newTask(ownerID, "Book venue", models.TaskStatusCompleted, now.Add(time.Hour))
+	newTask(ownerID, "Ancient chore", models.TaskStatusPending, now.AddDate(0, -1, 0))
+	newTask(quietID, "Quiet task", models.TaskStatusPending, now.Add(time.Hour))
+
+	inbox := func(userID uuid.UUID) []models.Notification {
+		var notifications []models.Notification
+		db.Where("user_id = ?", userID).Order("type").Find(&notifications)
+		return notifications
+	}
+
+	t.Run("Users start with in-app reminders a day ahead", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/users/reminders", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var pref models.ReminderPreference
+		json.Unmarshal(resp.Body.Bytes(), &pref)
+		assert.True(t, pref.Enabled)
+		assert.True(t, pref.Overdue)
+		assert.Equal(t, 24*60, pref.LeadMinutes)
+		assert.Equal(t, []string{models.ReminderChannelInApp}, pref.Channels)
+	})
+
+	t.Run("Invalid preferences are rejected", func(t *testing.T) {
+		resp := doJSON(router, "PUT", "/users/reminders", ownerToken, gin.H{"lead_minutes": 0})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "PUT", "/users/reminders", ownerToken, gin.H{"channels": []string{"carrier_pigeon"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "PUT", "/users/reminders", ownerToken, gin.H{"channels": []string{"webhook"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "PUT", "/users/reminders", ownerToken, gin.H{"channels": []string{"webhook"}, "webhook_url": "file:///etc/passwd"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Due soon and overdue tasks are reminded once per channel", func(t *testing.T) {
+		resp := doJSON(router, "PUT", "/users/reminders", ownerToken, gin.H{
+			"channels": []string{"in_app", "webhook", "email", "in_app"}, "webhook_url": receiver.URL, "lead_minutes": 180,
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		resp = doJSON(router, "PUT", "/users/reminders", quietToken, gin.H{"enabled": false})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		sent, err := reminderService.SendDue(db, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, sent)
+
+		notifications := inbox(ownerID)
+		assert.Len(t, notifications, 2)
+		assert.Equal(t, models.NotificationTaskDueSoon, notifications[0].Type)
+		assert.Equal(t, soon.ID, *notifications[0].TaskID)
+		assert.Equal(t, models.NotificationTaskOverdue, notifications[1].Type)
+		assert.Equal(t, late.ID, *notifications[1].TaskID)
+		assert.Len(t, hooks, 2)
+		assert.Equal(t, 2, email.count())
+		assert.Equal(t, "owner@test.com", email.messages[0].Email)
+		assert.Empty(t, inbox(quietID))
+
+		sent, err = reminderService.SendDue(db, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	})
+
+	t.Run("Moving the due date re-arms the reminder", func(t *testing.T) {
+		due := now.Add(26 * time.Hour)
+		db.Model(&models.Task{}).Where("id = ?", soon.ID).Update("due_date", due)
+
+		sent, err := reminderService.SendDue(db, now.Add(24*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, sent)
+	})
+
+	t.Run("Failed deliveries are retried", func(t *testing.T) {
+		db.Model(&models.Task{}).Where("id = ?", soon.ID).Update("status", models.TaskStatusCompleted)
+		newTask(ownerID, "Renew passport", models.TaskStatusPending, now.Add(48*time.Hour+30*time.Minute))
+		email.failing = true
+		sent, err := reminderService.SendDue(db, now.Add(48*time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, sent)
+
+		email.failing = false
+		sent, err = reminderService.SendDue(db, now.Add(48*time.Hour+time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, sent)
+	})
+}
+
+func TestLeases(t *testing.T) {
+	db := setupABACTestDB(t)
+	now := time.Date(2030, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	held, err := services.AcquireLease(db, "reminders", "replica-a", time.Minute, now)
+	assert.NoError(t, err)
+	assert.True(t, held)
+
+	// Another replica has to wait until the lease runs out
+	held, _ = services.AcquireLease(db, "reminders", "replica-b", time.Minute, now.Add(30*time.Second))
+	assert.False(t, held)
+
+	// The holder renews it
+	held, _ = services.AcquireLease(db, "reminders", "replica-a", time.Minute, now.Add(45*time.Second))
+	assert.True(t, held)
+	held, _ = services.AcquireLease(db, "reminders", "replica-b", time.Minute, now.Add(90*time.Second))
+	assert.False(t, held)
+
+	// Once it expires another replica takes over
+	held, _ = services.AcquireLease(db, "reminders", "replica-b", time.Minute, now.Add(2*time.Minute))
+	assert.True(t, held)
+	held, _ = services.AcquireLease(db, "reminders", "replica-a", time.Minute, now.Add(2*time.Minute))
+	assert.False(t, held)
+
+	// Releasing hands it over right away
+	assert.NoError(t, services.ReleaseLease(db, "reminders", "replica-b"))
+	held, _ = services.AcquireLease(db, "reminders", "replica-a", time.Minute, now.Add(2*time.Minute))
+	assert.True(t, held)
+}
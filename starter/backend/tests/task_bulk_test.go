@@ -0,0 +1,220 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTaskBulkRouter(db *gorm.DB, requireIfMatch bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskHandler := handlers.NewTaskHandler(db, services.NewTaskService()).WithRequireIfMatch(requireIfMatch)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("/bulk", taskHandler.BulkTasks)
+	}
+
+	return router
+}
+
+type bulkResponse struct {
+	Mode      string `json:"mode"`
+	Committed bool   `json:"committed"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+	Results   []struct {
+		Index  int          `json:"index"`
+		Op     string       `json:"op"`
+		ID     *uuid.UUID   `json:"id"`
+		Status int          `json:"status"`
+		Code   string       `json:"code"`
+		Error  string       `json:"error"`
+		Task   *models.Task `json:"task"`
+	} `json:"results"`
+}
+
+func TestTaskBulk(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTaskBulkRouter(db, false)
+	taskService := services.NewTaskService()
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	otherID, _ := createTestUser(t, db, "other", "other@test.com", "other123", false)
+	_, adminToken := createTestUser(t, db, "admin", "admin@test.com", "admin123", true)
+
+	newTask := func(title string, userID uuid.UUID, priority string) uuid.UUID {
+		task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: title, UserID: userID, Priority: priority}
+		assert.NoError(t, taskService.CreateTask(db, &task))
+		return task.ID
+	}
+	exists := func(id uuid.UUID) bool {
+		var count int64
+		db.Model(&models.Task{}).Where("id = ?", id).Count(&count)
+		return count == 1
+	}
+	decode := func(body []byte) bulkResponse {
+		var response bulkResponse
+		json.Unmarshal(body, &response)
+		return response
+	}
+
+	t.Run("All or nothing applies every operation", func(t *testing.T) {
+		editID := newTask("Edit me", ownerID, "low")
+		dropID := newTask("Drop me", ownerID, "low")
+
+		resp := doJSON(router, "POST", "/tasks/bulk", ownerToken, gin.H{"operations": []gin.H{
+			{"op": "create", "task": gin.H{"title": "Bulk created"}},
+			{"op": "update", "id": editID, "task": gin.H{"priority": "high", "description": "Edited"}},
+			{"op": "delete", "id": dropID},
+		}})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		response := decode(resp.Body.Bytes())
+		assert.True(t, response.Committed)
+		assert.Equal(t, 3, response.Succeeded)
+		assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+		assert.Equal(t, "Bulk created", response.Results[0].Task.Title)
+		assert.Equal(t, ownerID, response.Results[0].Task.UserID)
+		assert.Equal(t, http.StatusOK, response.Results[1].Status)
+		assert.Equal(t, models.TaskPriorityHigh, response.Results[1].Task.Priority)
+		assert.Equal(t, "Edit me", response.Results[1].Task.Title)
+		assert.Equal(t, http.StatusNoContent, response.Results[2].Status)
+		assert.False(t, exists(dropID))
+	})
+
+	t.Run("All or nothing rolls back on the first failure", func(t *testing.T) {
+		keepID := newTask("Keep me", ownerID, "low")
+		foreignID := newTask("Not yours", otherID, "low")
+
+		resp := doJSON(router, "POST", "/tasks/bulk", ownerToken, gin.H{"operations": []gin.H{
+			{"op": "delete", "id": keepID},
+			{"op": "update", "id": foreignID, "task": gin.H{"title": "Hijacked"}},
+			{"op": "create", "task": gin.H{"title": "Never created"}},
+		}})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		response := decode(resp.Body.Bytes())
+		assert.False(t, response.Committed)
+		assert.Equal(t, http.StatusFailedDependency, response.Results[0].Status)
+		assert.Equal(t, http.StatusForbidden, response.Results[1].Status)
+		assert.Equal(t, http.StatusFailedDependency, response.Results[2].Status)
+		assert.True(t, exists(keepID))
+
+		var count int64
+		db.Model(&models.Task{}).Where("title = ?", "Never created").Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Best effort reports each operation", func(t *testing.T) {
+		staleID := newTask("Stale", ownerID, "low")
+		editID := newTask("Fresh", ownerID, "low")
+
+		resp := doJSON(router, "POST", "/tasks/bulk", ownerToken, gin.H{"mode": "best_effort", "operations": []gin.H{
+			{"op": "update", "id": staleID, "version": 7, "task": gin.H{"title": "Too late"}},
+			{"op": "update", "id": editID, "task": gin.H{"status": "in_progress"}},
+			{"op": "update", "id": uuid.Must(uuid.NewV4()), "task": gin.H{"title": "Ghost"}},
+			{"op": "update", "id": editID, "task": gin.H{"created_at": "2001-01-01T00:00:00Z"}},
+			{"op": "archive", "id": editID},
+		}})
+		assert.Equal(t, http.StatusMultiStatus, resp.Code)
+		response := decode(resp.Body.Bytes())
+		assert.True(t, response.Committed)
+		assert.Equal(t, 1, response.Succeeded)
+		assert.Equal(t, 4, response.Failed)
+		assert.Equal(t, http.StatusPreconditionFailed, response.Results[0].Status)
+		assert.Equal(t, http.StatusOK, response.Results[1].Status)
+		assert.Equal(t, http.StatusNotFound, response.Results[2].Status)
+		assert.Equal(t, http.StatusBadRequest, response.Results[3].Status)
+		assert.Equal(t, http.StatusBadRequest, response.Results[4].Status)
+
+		stored, _ := taskService.GetTaskByID(db, editID)
+		assert.Equal(t, models.TaskStatusInProgress, stored.Status)
+	})
+
+	t.Run("A filter updates every visible match", func(t *testing.T) {
+		mineID := newTask("Mine", ownerID, "medium")
+		theirsID := newTask("Theirs", otherID, "medium")
+		db.Model(&models.Task{}).Where("id IN ?", []uuid.UUID{mineID, theirsID}).Update("description", "sweep")
+
+		resp := doJSON(router, "POST", "/tasks/bulk", ownerToken, gin.H{
+			"filter": gin.H{"q": "sweep"},
+			"update": gin.H{"priority": "low"},
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		response := decode(resp.Body.Bytes())
+		assert.Len(t, response.Results, 1)
+		assert.Equal(t, mineID, *response.Results[0].ID)
+
+		resp = doJSON(router, "POST", "/tasks/bulk", adminToken, gin.H{
+			"filter": gin.H{"q": "sweep"},
+			"update": gin.H{"priority": "high"},
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Len(t, decode(resp.Body.Bytes()).Results, 2)
+		stored, _ := taskService.GetTaskByID(db, theirsID)
+		assert.Equal(t, models.TaskPriorityHigh, stored.Priority)
+	})
+
+	t.Run("Created tasks belong to the caller", func(t *testing.T) {
+		for _, token := range []string{ownerToken, adminToken} {
+			resp := doJSON(router, "POST", "/tasks/bulk", token, gin.H{"operations": []gin.H{
+				{"op": "create", "task": gin.H{"title": "Planted", "user_id": otherID}},
+			}})
+			assert.Equal(t, http.StatusOK, resp.Code)
+			assert.NotEqual(t, otherID, decode(resp.Body.Bytes()).Results[0].Task.UserID)
+		}
+	})
+
+	t.Run("Versions are required when If-Match is", func(t *testing.T) {
+		strict := setupTaskBulkRouter(db, true)
+		editID := newTask("Strict", ownerID, "low")
+
+		resp := doJSON(strict, "POST", "/tasks/bulk", ownerToken, gin.H{"mode": "best_effort", "operations": []gin.H{
+			{"op": "create", "task": gin.H{"title": "Strict create"}},
+			{"op": "update", "id": editID, "task": gin.H{"title": "Blind"}},
+			{"op": "delete", "id": editID},
+			{"op": "update", "id": editID, "version": 1, "task": gin.H{"title": "Checked"}},
+		}})
+		assert.Equal(t, http.StatusMultiStatus, resp.Code)
+		response := decode(resp.Body.Bytes())
+		assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+		assert.Equal(t, http.StatusPreconditionRequired, response.Results[1].Status)
+		assert.Equal(t, "if_match_required", response.Results[1].Code)
+		assert.Equal(t, http.StatusPreconditionRequired, response.Results[2].Status)
+		assert.Equal(t, "if_match_required", response.Results[2].Code)
+		assert.Equal(t, http.StatusOK, response.Results[3].Status)
+		assert.Equal(t, "Checked", response.Results[3].Task.Title)
+
+		// A filter sends the version of each task it matched
+		resp = doJSON(strict, "POST", "/tasks/bulk", ownerToken, gin.H{
+			"filter": gin.H{"q": "Checked"},
+			"update": gin.H{"priority": "high"},
+		})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		stored, _ := taskService.GetTaskByID(db, editID)
+		assert.Equal(t, models.TaskPriorityHigh, stored.Priority)
+	})
+
+	t.Run("Malformed requests are refused", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/tasks/bulk", ownerToken, gin.H{"mode": "sometimes", "operations": []gin.H{{"op": "create"}}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "POST", "/tasks/bulk", ownerToken, gin.H{})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "POST", "/tasks/bulk", ownerToken, gin.H{"filter": gin.H{"status": "pending"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "POST", "/tasks/bulk", ownerToken, gin.H{"filter": gin.H{"status": "done"}, "update": gin.H{"priority": "low"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTaskETagRouter(db *gorm.DB, requireIfMatch bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskHandler := handlers.NewTaskHandler(db, services.NewTaskService()).WithRequireIfMatch(requireIfMatch)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.GET("/:id", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskByID)
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
+	}
+
+	return router
+}
+
+// doJSONWithHeaders is doJSON with extra request headers
+func doJSONWithHeaders(router *gin.Engine, method, path, token string, header map[string]string, payload interface{}) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	if payload != nil {
+		json.NewEncoder(&body).Encode(payload)
+	}
+	req := httptest.NewRequest(method, path, &body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestTaskETags(t *testing.T) {
+	db := setupABACTestDB(t)
+	taskService := services.NewTaskService()
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+
+	newTask := func(title string) string {
+		task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: title, UserID: ownerID}
+		assert.NoError(t, taskService.CreateTask(db, &task))
+		return "/tasks/" + task.ID.String()
+	}
+
+	t.Run("GET returns the version as ETag and honours If-None-Match", func(t *testing.T) {
+		router := setupTaskETagRouter(db, false)
+		taskPath := newTask("Read me")
+
+		resp := doJSON(router, "GET", taskPath, ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, `"1"`, resp.Header().Get("ETag"))
+
+		resp = doJSONWithHeaders(router, "GET", taskPath, ownerToken, map[string]string{"If-None-Match": `"1"`}, nil)
+		assert.Equal(t, http.StatusNotModified, resp.Code)
+		assert.Empty(t, resp.Body.String())
+
+		resp = doJSONWithHeaders(router, "PATCH", taskPath, ownerToken, map[string]string{"If-Match": `"1"`}, gin.H{"title": "Read me twice"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, `"2"`, resp.Header().Get("ETag"))
+
+		resp = doJSONWithHeaders(router, "GET", taskPath, ownerToken, map[string]string{"If-None-Match": `W/"1"`}, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var task models.Task
+		json.Unmarshal(resp.Body.Bytes(), &task)
+		assert.Equal(t, "Read me twice", task.Title)
+		assert.Equal(t, 2, task.Version)
+	})
+
+	t.Run("A stale If-Match is refused with the current task", func(t *testing.T) {
+		router := setupTaskETagRouter(db, false)
+		taskPath := newTask("Contested")
+
+		resp := doJSONWithHeaders(router, "PATCH", taskPath, ownerToken, map[string]string{"If-Match": `"1"`}, gin.H{"title": "First edit"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = doJSONWithHeaders(router, "PATCH", taskPath, ownerToken, map[string]string{"If-Match": `"1"`}, gin.H{"title": "Second edit"})
+		assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+		assert.Equal(t, `"2"`, resp.Header().Get("ETag"))
+		var problem struct {
+			Code string      `json:"code"`
+			Task models.Task `json:"task"`
+		}
+		json.Unmarshal(resp.Body.Bytes(), &problem)
+		assert.Equal(t, "version_mismatch", problem.Code)
+		assert.Equal(t, "First edit", problem.Task.Title)
+
+		resp = doJSONWithHeaders(router, "DELETE", taskPath, ownerToken, map[string]string{"If-Match": `"1"`}, nil)
+		assert.Equal(t, http.StatusPreconditionFailed, resp.Code)
+
+		// Without If-Match the write is unconditional unless required
+		resp = doJSON(router, "PUT", taskPath, ownerToken, gin.H{"title": "Blind edit", "status": "pending", "priority": "medium"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		resp = doJSONWithHeaders(router, "DELETE", taskPath, ownerToken, map[string]string{"If-Match": `"3"`}, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+	})
+
+	t.Run("If-Match can be required", func(t *testing.T) {
+		router := setupTaskETagRouter(db, true)
+		taskPath := newTask("Guarded")
+
+		resp := doJSON(router, "PATCH", taskPath, ownerToken, gin.H{"title": "Unguarded"})
+		assert.Equal(t, http.StatusPreconditionRequired, resp.Code)
+		resp = doJSON(router, "DELETE", taskPath, ownerToken, nil)
+		assert.Equal(t, http.StatusPreconditionRequired, resp.Code)
+
+		resp = doJSONWithHeaders(router, "PATCH", taskPath, ownerToken, map[string]string{"If-Match": "*"}, gin.H{"title": "Any version"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("Concurrent writers cannot both win", func(t *testing.T) {
+		task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Race", UserID: ownerID}
+		assert.NoError(t, taskService.CreateTask(db, &task))
+
+		// Both read version 1; the second write must not overwrite the first
+		assert.NoError(t, taskService.UpdateTask(db, task.ID, &models.Task{Title: "Winner"}, services.UpdateOptions{IfVersion: 1}))
+		err := taskService.UpdateTask(db, task.ID, &models.Task{Title: "Loser"}, services.UpdateOptions{IfVersion: 1})
+		assert.ErrorIs(t, err, services.ErrVersionMismatch)
+
+		stored, _ := taskService.GetTaskByID(db, task.ID)
+		assert.Equal(t, "Winner", stored.Title)
+		assert.Equal(t, 2, stored.Version)
+
+		// A version sent in the body is ignored
+		assert.NoError(t, taskService.UpdateTask(db, task.ID, &models.Task{Version: 42}, services.UpdateOptions{}))
+		stored, _ = taskService.GetTaskByID(db, task.ID)
+		assert.Equal(t, 3, stored.Version)
+	})
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTaskHierarchyRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService()
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	dependencyHandler := handlers.NewTaskDependencyHandler(db, taskService, services.NewTaskDependencyService())
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
+		taskRoutes.GET("/:id/tree", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskTree)
+		taskRoutes.GET("/:id/dependencies", middleware.RequirePermission("tasks", "read"), dependencyHandler.GetDependencies)
+		taskRoutes.POST("/:id/dependencies", middleware.RequirePermission("tasks", "update"), dependencyHandler.AddDependency)
+		taskRoutes.DELETE("/:id/dependencies/:blocker_id", middleware.RequirePermission("tasks", "update"), dependencyHandler.RemoveDependency)
+	}
+
+	return router
+}
+
+func TestSubtasksAndDependencies(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTaskHierarchyRouter(db)
+
+	_, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	_, strangerToken := createTestUser(t, db, "stranger", "stranger@test.com", "stranger123", false)
+
+	create := func(token string, payload map[string]interface{}) (models.Task, int) {
+		var task models.Task
+		resp := doJSON(router, "POST", "/tasks", token, payload)
+		json.Unmarshal(resp.Body.Bytes(), &task)
+		return task, resp.Code
+	}
+
+	parent, _ := create(ownerToken, map[string]interface{}{"title": "Release", "status": "review"})
+	first, code := create(ownerToken, map[string]interface{}{"title": "Write changelog", "status": "review", "parent_id": parent.ID})
+	assert.Equal(t, http.StatusCreated, code)
+	second, _ := create(ownerToken, map[string]interface{}{"title": "Tag release", "status": "review", "parent_id": parent.ID})
+	nested, _ := create(ownerToken, map[string]interface{}{"title": "Draft notes", "status": "completed", "parent_id": first.ID})
+
+	t.Run("Only editors of the parent can add subtasks", func(t *testing.T) {
+		_, code := create(strangerToken, map[string]interface{}{"title": "Sneaky", "status": "pending", "parent_id": parent.ID})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("Tree returns descendants with rolled up progress", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/tasks/"+parent.ID.String()+"/tree", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var tree services.TaskTree
+		json.Unmarshal(resp.Body.Bytes(), &tree)
+		assert.Equal(t, parent.ID, tree.ID)
+		assert.Len(t, tree.Subtasks, 2)
+		assert.Equal(t, 100, tree.Subtasks[0].Progress)
+		assert.Equal(t, nested.ID, tree.Subtasks[0].Subtasks[0].ID)
+		assert.Equal(t, 50, tree.Progress)
+
+		resp = doJSON(router, "GET", "/tasks/"+parent.ID.String()+"/tree", strangerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Dependencies reject cycles", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/tasks/"+second.ID.String()+"/dependencies", ownerToken, handlers.TaskDependencyRequest{BlockedByID: first.ID})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		resp = doJSON(router, "POST", "/tasks/"+first.ID.String()+"/dependencies", ownerToken, handlers.TaskDependencyRequest{BlockedByID: second.ID})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		// The parent waits on its subtasks, so a subtask cannot be blocked by it
+		resp = doJSON(router, "POST", "/tasks/"+first.ID.String()+"/dependencies", ownerToken, handlers.TaskDependencyRequest{BlockedByID: parent.ID})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = doJSON(router, "PATCH", "/tasks/"+parent.ID.String(), ownerToken, map[string]interface{}{"parent_id": nested.ID})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		var deps services.TaskDependencies
+		resp = doJSON(router, "GET", "/tasks/"+first.ID.String()+"/dependencies", ownerToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &deps)
+		assert.Len(t, deps.Blocking, 1)
+		assert.Len(t, deps.BlockedBy, 0)
+	})
+
+	t.Run("Completion waits for blockers and subtasks", func(t *testing.T) {
+		resp := doJSON(router, "PATCH", "/tasks/"+second.ID.String(), ownerToken, map[string]interface{}{"status": "completed"})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		resp = doJSON(router, "PATCH", "/tasks/"+parent.ID.String(), ownerToken, map[string]interface{}{"status": "completed"})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		resp = doJSON(router, "PATCH", "/tasks/"+first.ID.String(), ownerToken, map[string]interface{}{"status": "completed"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = doJSON(router, "PATCH", "/tasks/"+second.ID.String(), ownerToken, map[string]interface{}{"status": "completed"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("Force completes a task with open subtasks", func(t *testing.T) {
+		open, _ := create(ownerToken, map[string]interface{}{"title": "Announce", "status": "pending", "parent_id": parent.ID})
+
+		resp := doJSON(router, "PATCH", "/tasks/"+parent.ID.String(), ownerToken, map[string]interface{}{"status": "completed"})
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		resp = doJSON(router, "PATCH", "/tasks/"+parent.ID.String()+"?force=true", ownerToken, map[string]interface{}{"status": "completed"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var stillOpen models.Task
+		db.First(&stillOpen, "id = ?", open.ID)
+		assert.Equal(t, "pending", stillOpen.Status)
+	})
+
+	t.Run("Deleting a task moves its subtasks up", func(t *testing.T) {
+		resp := doJSON(router, "DELETE", "/tasks/"+first.ID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+
+		var moved models.Task
+		db.First(&moved, "id = ?", nested.ID)
+		assert.Equal(t, parent.ID, *moved.ParentID)
+
+		var count int64
+		db.Model(&models.TaskDependency{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+}
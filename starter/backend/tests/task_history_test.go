@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTaskHistoryRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService()
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	taskShareHandler := handlers.NewTaskShareHandler(db, taskService, services.NewTaskShareService())
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.GET("/:id/history", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskHistory)
+		taskRoutes.POST("/:id/history/:revision/revert", middleware.RequirePermission("tasks", "update"), taskHandler.RevertTask)
+		taskRoutes.POST("/:id/shares", middleware.RequirePermission("tasks", "update"), taskShareHandler.ShareTask)
+	}
+
+	return router
+}
+
+type historyPage struct {
+	Items []models.TaskRevision `json:"items"`
+	Total int64                 `json:"total"`
+}
+
+func TestTaskHistory(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTaskHistoryRouter(db)
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	viewerID, viewerToken := createTestUser(t, db, "viewer", "viewer@test.com", "viewer123", false)
+	_, otherToken := createTestUser(t, db, "other", "other@test.com", "other123", false)
+
+	var task models.Task
+	resp := doJSON(router, "POST", "/tasks", ownerToken, gin.H{
+		"title":       "Write report",
+		"description": "Quarterly numbers",
+		"priority":    "low",
+	})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	json.Unmarshal(resp.Body.Bytes(), &task)
+	taskPath := "/tasks/" + task.ID.String()
+
+	history := func(token, query string) historyPage {
+		var page historyPage
+		resp := doJSON(router, "GET", taskPath+"/history"+query, token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &page)
+		return page
+	}
+
+	t.Run("Every change is recorded with who made it and what moved", func(t *testing.T) {
+		doJSON(router, "PATCH", taskPath, ownerToken, gin.H{"title": "Write annual report", "priority": "high", "due_date": "2026-11-01T00:00:00Z"})
+		doJSON(router, "PATCH", taskPath, ownerToken, gin.H{"status": "in_progress"})
+		// Nothing changes, so no revision is added
+		doJSON(router, "PATCH", taskPath, ownerToken, gin.H{"priority": "high"})
+
+		page := history(ownerToken, "")
+		assert.Equal(t, int64(3), page.Total)
+		started, renamed, created := page.Items[0], page.Items[1], page.Items[2]
+
+		assert.Equal(t, 1, created.Revision)
+		assert.Equal(t, models.EventTaskCreated, created.Action)
+		assert.Equal(t, ownerID, *created.ActorID)
+		assert.JSONEq(t, `"Write report"`, string(created.Changes["title"].After))
+
+		assert.Equal(t, 2, renamed.Revision)
+		assert.JSONEq(t, `"Write report"`, string(renamed.Changes["title"].Before))
+		assert.JSONEq(t, `"Write annual report"`, string(renamed.Changes["title"].After))
+		assert.JSONEq(t, `"low"`, string(renamed.Changes["priority"].Before))
+		assert.JSONEq(t, `"high"`, string(renamed.Changes["priority"].After))
+		assert.NotContains(t, renamed.Changes, "description")
+
+		assert.Equal(t, 3, started.Revision)
+		assert.JSONEq(t, `"in_progress"`, string(started.Changes["status"].After))
+		assert.Contains(t, started.Changes, "started_at")
+
+		paged := history(ownerToken, "?page=2&page_size=2")
+		assert.Len(t, paged.Items, 1)
+		assert.Equal(t, 1, paged.Items[0].Revision)
+	})
+
+	t.Run("Only users who can see the task read its history", func(t *testing.T) {
+		resp := doJSON(router, "GET", taskPath+"/history", otherToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "POST", taskPath+"/shares", ownerToken, handlers.ShareTaskRequest{
+			SubjectType: models.ShareSubjectUser,
+			SubjectID:   viewerID,
+			Permission:  models.TaskAccessViewer,
+		})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Equal(t, int64(3), history(viewerToken, "").Total)
+
+		resp = doJSON(router, "POST", taskPath+"/history/1/revert", viewerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Reverting restores the fields, including empty ones", func(t *testing.T) {
+		resp := doJSON(router, "POST", taskPath+"/history/1/revert", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var reverted models.Task
+		json.Unmarshal(resp.Body.Bytes(), &reverted)
+		assert.Equal(t, "Write report", reverted.Title)
+		assert.Equal(t, models.TaskPriorityLow, reverted.Priority)
+		assert.Equal(t, models.TaskStatusPending, reverted.Status)
+		assert.Nil(t, reverted.DueDate)
+
+		latest := history(ownerToken, "").Items[0]
+		assert.Equal(t, 4, latest.Revision)
+		assert.Equal(t, 1, *latest.RevertedFrom)
+		assert.Equal(t, ownerID, *latest.ActorID)
+		assert.JSONEq(t, `null`, string(latest.Changes["due_date"].After))
+		assert.JSONEq(t, `"pending"`, string(latest.Changes["status"].After))
+	})
+
+	t.Run("Reverting follows the workflow", func(t *testing.T) {
+		for _, status := range []string{"in_progress", "review", "completed"} {
+			resp := doJSON(router, "PATCH", taskPath, ownerToken, gin.H{"status": status})
+			assert.Equal(t, http.StatusOK, resp.Code)
+		}
+
+		// Completed tasks can only go back to in progress
+		resp := doJSON(router, "POST", taskPath+"/history/1/revert", ownerToken, nil)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		resp = doJSON(router, "POST", taskPath+"/history/3/revert", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var reverted models.Task
+		json.Unmarshal(resp.Body.Bytes(), &reverted)
+		assert.Equal(t, models.TaskStatusInProgress, reverted.Status)
+		assert.Nil(t, reverted.CompletedAt)
+	})
+
+	t.Run("Unknown revisions are not found", func(t *testing.T) {
+		resp := doJSON(router, "POST", taskPath+"/history/99/revert", ownerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		resp = doJSON(router, "POST", taskPath+"/history/first/revert", ownerToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// doPatch sends a raw PATCH body with the given content type
+func doPatch(router *gin.Engine, path, token, contentType, body string) *httptest.ResponseRecorder {
+	return doRaw(router, "PATCH", path, token, contentType, body)
+}
+
+func TestTaskPatch(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTaskETagRouter(db, false)
+	taskService := services.NewTaskService()
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	otherID, _ := createTestUser(t, db, "other", "other@test.com", "other123", false)
+
+	newTask := func(title string) string {
+		due := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+		task := models.Task{
+			ID:          uuid.Must(uuid.NewV4()),
+			Title:       title,
+			Description: "Some details",
+			Priority:    models.TaskPriorityHigh,
+			DueDate:     &due,
+			UserID:      ownerID,
+		}
+		assert.NoError(t, taskService.CreateTask(db, &task))
+		return "/tasks/" + task.ID.String()
+	}
+	decode := func(resp *httptest.ResponseRecorder) models.Task {
+		var task models.Task
+		json.Unmarshal(resp.Body.Bytes(), &task)
+		return task
+	}
+
+	t.Run("Merge patch changes only the given fields and null clears", func(t *testing.T) {
+		taskPath := newTask("Merge me")
+
+		resp := doPatch(router, taskPath, ownerToken, "application/merge-patch+json", `{"title": "Merged", "due_date": null, "description": null}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, `"2"`, resp.Header().Get("ETag"))
+		task := decode(resp)
+		assert.Equal(t, "Merged", task.Title)
+		assert.Nil(t, task.DueDate)
+		assert.Empty(t, task.Description)
+		assert.Equal(t, models.TaskPriorityHigh, task.Priority)
+		assert.Equal(t, models.TaskStatusPending, task.Status)
+
+		// Only changed fields are validated, so an unchanged patch is a no-op
+		resp = doPatch(router, taskPath, ownerToken, "application/json", `{"title": "Merged"}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, `"2"`, resp.Header().Get("ETag"))
+
+		resp = doPatch(router, taskPath, ownerToken, "application/merge-patch+json", `{"priority": "urgent"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("JSON Patch applies operations in order", func(t *testing.T) {
+		taskPath := newTask("Patch me")
+
+		resp := doPatch(router, taskPath, ownerToken, "application/json-patch+json", `[
+			{"op": "test", "path": "/title", "value": "Patch me"},
+			{"op": "replace", "path": "/status", "value": "in_progress"},
+			{"op": "remove", "path": "/due_date"}
+		]`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		task := decode(resp)
+		assert.Equal(t, models.TaskStatusInProgress, task.Status)
+		assert.NotNil(t, task.StartedAt)
+		assert.Nil(t, task.DueDate)
+		assert.Equal(t, "Some details", task.Description)
+
+		// A failing test leaves the task untouched
+		resp = doPatch(router, taskPath, ownerToken, "application/json-patch+json", `[
+			{"op": "replace", "path": "/title", "value": "Never"},
+			{"op": "test", "path": "/title", "value": "Patch me"}
+		]`)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		resp = doPatch(router, taskPath, ownerToken, "application/json-patch+json", `[{"op": "replace", "path": "/missing", "value": 1}]`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = doJSON(router, "GET", taskPath, ownerToken, nil)
+		assert.Equal(t, "Patch me", decode(resp).Title)
+	})
+
+	t.Run("Read-only fields, reassignment and content type are checked", func(t *testing.T) {
+		taskPath := newTask("Guarded")
+
+		resp := doPatch(router, taskPath, ownerToken, "application/merge-patch+json", `{"created_at": "2001-01-01T00:00:00Z"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), "created_at cannot be changed")
+
+		resp = doPatch(router, taskPath, ownerToken, "application/merge-patch+json", `{"user_id": "`+otherID.String()+`"}`)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doPatch(router, taskPath, ownerToken, "text/plain", `{"title": "Nope"}`)
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+	})
+
+	t.Run("PUT replaces the whole task", func(t *testing.T) {
+		taskPath := newTask("Replace me")
+
+		resp := doJSON(router, "PUT", taskPath, ownerToken, gin.H{"title": "Partial"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = doJSON(router, "PUT", taskPath, ownerToken, gin.H{"title": "Replaced", "status": "pending", "priority": "low"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		task := decode(doJSON(router, "GET", taskPath, ownerToken, nil))
+		assert.Equal(t, "Replaced", task.Title)
+		assert.Equal(t, models.TaskPriorityLow, task.Priority)
+		assert.Empty(t, task.Description)
+		assert.Nil(t, task.DueDate)
+		assert.Equal(t, ownerID, task.UserID)
+	})
+}
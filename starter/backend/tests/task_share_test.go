@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTaskShareRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService()
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	taskShareHandler := handlers.NewTaskShareHandler(db, taskService, services.NewTaskShareService())
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
+		taskRoutes.GET("/:id", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskByID)
+		taskRoutes.GET("/shared", middleware.RequirePermission("tasks", "read"), taskHandler.GetSharedTasks)
+		taskRoutes.GET("/:id/shares", middleware.RequirePermission("tasks", "read"), taskShareHandler.GetShares)
+		taskRoutes.POST("/:id/shares", middleware.RequirePermission("tasks", "update"), taskShareHandler.ShareTask)
+		taskRoutes.DELETE("/:id/shares/:share_id", middleware.RequirePermission("tasks", "update"), taskShareHandler.RevokeShare)
+	}
+
+	userRoutes := router.Group("/users")
+	userRoutes.Use(middleware.AuthMiddleware())
+	{
+		userRoutes.GET("/:user_id/tasks", middleware.RequirePermission("tasks", "read"), taskHandler.GetTasksByUser)
+	}
+
+	return router
+}
+
+func doJSON(router *gin.Engine, method, path, token string, payload interface{}) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	if payload != nil {
+		json.NewEncoder(&body).Encode(payload)
+	}
+	req := httptest.NewRequest(method, path, &body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestTaskSharing(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTaskShareRouter(db)
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	viewerID, viewerToken := createTestUser(t, db, "viewer", "viewer@test.com", "viewer123", false)
+	editorID, editorToken := createTestUser(t, db, "editor", "editor@test.com", "editor123", false)
+	_, strangerToken := createTestUser(t, db, "stranger", "stranger@test.com", "stranger123", false)
+
+	task := models.Task{
+		ID:     uuid.Must(uuid.NewV4()),
+		Title:  "Shared task",
+		UserID: ownerID,
+		Status: "pending",
+	}
+	db.Create(&task)
+	taskPath := "/tasks/" + task.ID.String()
+
+	var viewerShare models.TaskShare
+
+	t.Run("Owner can share with a viewer and an editor", func(t *testing.T) {
+		resp := doJSON(router, "POST", taskPath+"/shares", ownerToken, handlers.ShareTaskRequest{
+			SubjectType: models.ShareSubjectUser,
+			SubjectID:   viewerID,
+			Permission:  models.TaskAccessViewer,
+		})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &viewerShare)
+
+		resp = doJSON(router, "POST", taskPath+"/shares", ownerToken, handlers.ShareTaskRequest{
+			SubjectType: models.ShareSubjectUser,
+			SubjectID:   editorID,
+			Permission:  models.TaskAccessEditor,
+		})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+	})
+
+	t.Run("Invalid permission level is rejected", func(t *testing.T) {
+		resp := doJSON(router, "POST", taskPath+"/shares", ownerToken, handlers.ShareTaskRequest{
+			SubjectType: models.ShareSubjectUser,
+			SubjectID:   viewerID,
+			Permission:  "superuser",
+		})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Viewer can read but not update", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, doJSON(router, "GET", taskPath, viewerToken, nil).Code)
+		resp := doJSON(router, "PATCH", taskPath, viewerToken, map[string]interface{}{"title": "Nope"})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Viewer cannot manage shares", func(t *testing.T) {
+		resp := doJSON(router, "GET", taskPath+"/shares", viewerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Editor can update but not delete", func(t *testing.T) {
+		resp := doJSON(router, "PATCH", taskPath, editorToken, map[string]interface{}{"title": "Edited"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var updated models.Task
+		db.First(&updated, "id = ?", task.ID)
+		assert.Equal(t, "Edited", updated.Title)
+		assert.Equal(t, ownerID, updated.UserID)
+
+		resp = doJSON(router, "DELETE", taskPath, editorToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Stranger cannot access the task", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, doJSON(router, "GET", taskPath, strangerToken, nil).Code)
+	})
+
+	t.Run("Shared with me lists the task", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/tasks/shared", viewerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var tasks []models.Task
+		json.Unmarshal(resp.Body.Bytes(), &tasks)
+		assert.Len(t, tasks, 1)
+
+		resp = doJSON(router, "GET", "/tasks/shared", strangerToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &tasks)
+		assert.Len(t, tasks, 0)
+	})
+
+	t.Run("User task listing only shows shared tasks to others", func(t *testing.T) {
+		other := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Private", UserID: ownerID, Status: "pending"}
+		db.Create(&other)
+
+		var tasks []models.Task
+		resp := doJSON(router, "GET", "/users/"+ownerID.String()+"/tasks", viewerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &tasks)
+		assert.Len(t, tasks, 1)
+
+		resp = doJSON(router, "GET", "/users/"+ownerID.String()+"/tasks", ownerToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &tasks)
+		assert.Len(t, tasks, 2)
+	})
+
+	t.Run("Role shares apply to every member of the role", func(t *testing.T) {
+		var userRole models.Role
+		db.Where("name = ?", "user").First(&userRole)
+
+		resp := doJSON(router, "POST", taskPath+"/shares", ownerToken, handlers.ShareTaskRequest{
+			SubjectType: models.ShareSubjectRole,
+			SubjectID:   userRole.ID,
+			Permission:  models.TaskAccessViewer,
+		})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Equal(t, http.StatusOK, doJSON(router, "GET", taskPath, strangerToken, nil).Code)
+	})
+
+	t.Run("Revoked share removes access", func(t *testing.T) {
+		db.Where("subject_type = ?", models.ShareSubjectRole).Delete(&models.TaskShare{})
+
+		resp := doJSON(router, "DELETE", taskPath+"/shares/"+viewerShare.ID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Equal(t, http.StatusForbidden, doJSON(router, "GET", taskPath, viewerToken, nil).Code)
+	})
+}
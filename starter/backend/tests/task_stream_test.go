@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-manager/backend/internal/events"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"task-manager/backend/internal/utils"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func setupTaskStreamRouter(db *gorm.DB, broker events.Broker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService().WithEvents(broker)
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	taskShareHandler := handlers.NewTaskShareHandler(db, taskService, services.NewTaskShareService())
+	taskStreamHandler := handlers.NewTaskStreamHandler(db, taskService, broker)
+
+	router.GET("/tasks/stream", middleware.StreamAuthMiddleware(), middleware.RequirePermission("tasks", "read"), taskStreamHandler.Stream)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
+		taskRoutes.POST("/:id/shares", middleware.RequirePermission("tasks", "update"), taskShareHandler.ShareTask)
+	}
+
+	return router
+}
+
+type streamEvent struct {
+	ID   string
+	Type string
+	Data string
+}
+
+// openStream connects to the task stream and returns its events as they arrive
+func openStream(t *testing.T, url string, header http.Header) <-chan streamEvent {
+	req, err := http.NewRequest("GET", url, nil)
+	require.NoError(t, err)
+	req.Header = header
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	t.Cleanup(func() { resp.Body.Close() })
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": connected\n", line)
+
+	stream := make(chan streamEvent, 16)
+	go func() {
+		defer close(stream)
+		var event streamEvent
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case line == "":
+				if event.Type != "" {
+					stream <- event
+				}
+				event = streamEvent{}
+			case strings.HasPrefix(line, "id: "):
+				event.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				event.Type = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+	return stream
+}
+
+func nextEvent(t *testing.T, stream <-chan streamEvent) streamEvent {
+	select {
+	case event, ok := <-stream:
+		require.True(t, ok, "stream closed")
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event within 2s")
+		return streamEvent{}
+	}
+}
+
+func TestTaskStream(t *testing.T) {
+	db := setupABACTestDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// Every connection to :memory: opens a separate database
+	sqlDB.SetMaxOpenConns(1)
+
+	router := setupTaskStreamRouter(db, events.NewMemoryBroker(16))
+	server := httptest.NewServer(router)
+	// Cleanups run last first, so the streams are closed before the server waits for them
+	t.Cleanup(server.Close)
+
+	_, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	friendID, friendToken := createTestUser(t, db, "friend", "friend@test.com", "friend123", false)
+	_, strangerToken := createTestUser(t, db, "stranger", "stranger@test.com", "stranger123", false)
+
+	bearer := func(token string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + token}}
+	}
+	owner := openStream(t, server.URL+"/tasks/stream", bearer(ownerToken))
+	friend := openStream(t, server.URL+"/tasks/stream?access_token="+friendToken, nil)
+	stranger := openStream(t, server.URL+"/tasks/stream", bearer(strangerToken))
+
+	t.Run("Owners see their tasks created, updated and deleted", func(t *testing.T) {
+		var task models.Task
+		resp := doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Draft agenda"})
+		json.Unmarshal(resp.Body.Bytes(), &task)
+
+		event := nextEvent(t, owner)
+		assert.Equal(t, events.TaskCreated, event.Type)
+		assert.NotEmpty(t, event.ID)
+		var streamed models.Task
+		json.Unmarshal([]byte(event.Data), &streamed)
+		assert.Equal(t, task.ID, streamed.ID)
+		assert.Equal(t, "Draft agenda", streamed.Title)
+
+		doJSON(router, "PATCH", "/tasks/"+task.ID.String(), ownerToken, gin.H{"title": "Final agenda"})
+		event = nextEvent(t, owner)
+		assert.Equal(t, events.TaskUpdated, event.Type)
+		json.Unmarshal([]byte(event.Data), &streamed)
+		assert.Equal(t, "Final agenda", streamed.Title)
+
+		doJSON(router, "DELETE", "/tasks/"+task.ID.String(), ownerToken, nil)
+		event = nextEvent(t, owner)
+		assert.Equal(t, events.TaskDeleted, event.Type)
+		assert.JSONEq(t, `{"id": "`+task.ID.String()+`"}`, event.Data)
+	})
+
+	t.Run("Users who lose a shared task to deletion hear about it", func(t *testing.T) {
+		var task models.Task
+		resp := doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Book room"})
+		json.Unmarshal(resp.Body.Bytes(), &task)
+		resp = doJSON(router, "POST", "/tasks/"+task.ID.String()+"/shares", ownerToken, gin.H{
+			"subject_type": "user", "subject_id": friendID, "permission": "viewer",
+		})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		nextEvent(t, owner)
+
+		doJSON(router, "DELETE", "/tasks/"+task.ID.String(), ownerToken, nil)
+		assert.Equal(t, events.TaskDeleted, nextEvent(t, owner).Type)
+
+		// Access is checked on delivery, so the creation reaches the friend too
+		// when the share is in place by then; the first task never does
+		event := nextEvent(t, friend)
+		if event.Type == events.TaskCreated {
+			assert.Contains(t, event.Data, task.ID.String())
+			event = nextEvent(t, friend)
+		}
+		assert.Equal(t, events.TaskDeleted, event.Type)
+		assert.JSONEq(t, `{"id": "`+task.ID.String()+`"}`, event.Data)
+	})
+
+	t.Run("Users only see tasks they have access to", func(t *testing.T) {
+		var task models.Task
+		resp := doJSON(router, "POST", "/tasks", strangerToken, gin.H{"title": "Own errand"})
+		json.Unmarshal(resp.Body.Bytes(), &task)
+
+		// Nothing the owner did reached the stranger before their own task
+		event := nextEvent(t, stranger)
+		assert.Equal(t, events.TaskCreated, event.Type)
+		var streamed models.Task
+		json.Unmarshal([]byte(event.Data), &streamed)
+		assert.Equal(t, task.ID, streamed.ID)
+	})
+
+	t.Run("Streams require a valid token", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/tasks/stream")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+		resp, err = http.Get(server.URL + "/tasks/stream?access_token=not-a-token")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Streams end when their token expires", func(t *testing.T) {
+		t.Setenv("JWT_SECRET", "stream-secret")
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &utils.Claims{
+			UserID:      friendID,
+			Username:    "friend",
+			Roles:       []string{"user"},
+			Permissions: []string{"tasks:read"},
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second)),
+			},
+		}).SignedString([]byte("stream-secret"))
+		require.NoError(t, err)
+
+		expiring := openStream(t, server.URL+"/tasks/stream?access_token="+token, nil)
+		select {
+		case _, open := <-expiring:
+			assert.False(t, open)
+		case <-time.After(3 * time.Second):
+			t.Fatal("stream outlived its token")
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTaskTransferRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	transferService := services.NewTaskTransferService(services.NewTaskService())
+	transferHandler := handlers.NewTaskTransferHandler(db, transferService)
+	feedHandler := handlers.NewCalendarFeedHandler(db, services.NewCalendarFeedService(), transferService)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.GET("/export", middleware.RequirePermission("tasks", "read"), transferHandler.ExportTasks)
+		taskRoutes.POST("/import", middleware.RequirePermission("tasks", "create"), transferHandler.ImportTasks)
+	}
+	userRoutes := router.Group("/users")
+	userRoutes.Use(middleware.AuthMiddleware())
+	{
+		userRoutes.POST("/calendar-feed", middleware.RequirePermission("tasks", "read"), feedHandler.CreateFeed)
+		userRoutes.GET("/calendar-feed", feedHandler.GetFeed)
+		userRoutes.DELETE("/calendar-feed", feedHandler.RevokeFeed)
+	}
+	router.GET("/api/v1/calendar/:token", feedHandler.Feed)
+
+	return router
+}
+
+// doRaw sends a request with a raw body of the given content type
+func doRaw(router *gin.Engine, method, path, token, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestTaskExport(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTaskTransferRouter(db)
+	taskService := services.NewTaskService()
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	otherID, _ := createTestUser(t, db, "other", "other@test.com", "other123", false)
+	_, adminToken := createTestUser(t, db, "admin", "admin@test.com", "admin123", true)
+
+	due := time.Date(2030, 5, 1, 9, 30, 0, 0, time.UTC)
+	for _, task := range []models.Task{
+		{Title: "=SUM(A1)", Description: "Line one\nline two, with; punctuation", DueDate: &due, Priority: "high", UserID: ownerID},
+		{Title: "No due date", UserID: ownerID},
+		{Title: "Someone else's", UserID: otherID},
+	} {
+		task.ID = uuid.Must(uuid.NewV4())
+		assert.NoError(t, taskService.CreateTask(db, &task))
+	}
+
+	t.Run("CSV holds the user's own tasks", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/tasks/export", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Header().Get("Content-Type"), "text/csv")
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		assert.NoError(t, err)
+		assert.Len(t, records, 3)
+		assert.Equal(t, "title", records[0][1])
+		titles := []string{records[1][1], records[2][1]}
+		assert.Contains(t, titles, "'=SUM(A1)")
+		assert.Contains(t, titles, "No due date")
+	})
+
+	t.Run("NDJSON and filters", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/tasks/export?format=ndjson&priority=high", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		scanner := bufio.NewScanner(resp.Body)
+		var tasks []models.Task
+		for scanner.Scan() {
+			var task models.Task
+			assert.NoError(t, json.Unmarshal(scanner.Bytes(), &task))
+			tasks = append(tasks, task)
+		}
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, "=SUM(A1)", tasks[0].Title)
+
+		resp = doJSON(router, "GET", "/tasks/export?format=xml", ownerToken, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("iCalendar has a to-do per task and an event per due date", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/tasks/export?format=ics", ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		body := resp.Body.String()
+		assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+		assert.True(t, strings.HasSuffix(body, "END:VCALENDAR\r\n"))
+		assert.Equal(t, 2, strings.Count(body, "BEGIN:VTODO"))
+		assert.Equal(t, 1, strings.Count(body, "BEGIN:VEVENT"))
+		assert.Contains(t, body, "DUE:20300501T093000Z")
+		assert.Contains(t, body, "DTSTART:20300501T093000Z")
+		assert.Contains(t, body, `DESCRIPTION:Line one\nline two\, with\; punctuation`)
+		assert.Contains(t, body, "PRIORITY:1")
+		for _, line := range strings.Split(body, "\r\n") {
+			assert.LessOrEqual(t, len(line), 75)
+		}
+	})
+
+	t.Run("Only admins export other users' tasks", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/tasks/export?all=true", ownerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		resp = doJSON(router, "GET", "/tasks/export?user_id="+otherID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "GET", "/tasks/export?all=true", adminToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		records, _ := csv.NewReader(resp.Body).ReadAll()
+		assert.Len(t, records, 4)
+
+		resp = doJSON(router, "GET", "/tasks/export?user_id="+otherID.String(), adminToken, nil)
+		records, _ = csv.NewReader(resp.Body).ReadAll()
+		assert.Len(t, records, 2)
+		assert.Equal(t, "Someone else's", records[1][1])
+	})
+}
+
+func TestTaskImport(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTaskTransferRouter(db)
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	otherID, _ := createTestUser(t, db, "other", "other@test.com", "other123", false)
+	_, adminToken := createTestUser(t, db, "admin", "admin@test.com", "admin123", true)
+
+	countTasks := func(userID uuid.UUID) int64 {
+		var count int64
+		db.Model(&models.Task{}).Where("user_id = ?", userID).Count(&count)
+		return count
+	}
+	decode := func(resp *httptest.ResponseRecorder) services.ImportReport {
+		var report services.ImportReport
+		json.Unmarshal(resp.Body.Bytes(), &report)
+		return report
+	}
+
+	csvBody := "Title,Description,Status,Priority,Due_Date,Notes\n" +
+		"Migrate sheet,From the old tracker,in_progress,High,2030-01-15,ignored\n" +
+		"\"'=Formula\",,,,,\n"
+
+	t.Run("A dry run validates without creating", func(t *testing.T) {
+		resp := doRaw(router, "POST", "/tasks/import?dry_run=true", ownerToken, "text/csv", csvBody)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		report := decode(resp)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 2, report.Total)
+		assert.Equal(t, 2, report.Valid)
+		assert.Zero(t, report.Created)
+		assert.Zero(t, countTasks(ownerID))
+	})
+
+	t.Run("CSV rows become the importer's tasks", func(t *testing.T) {
+		resp := doRaw(router, "POST", "/tasks/import", ownerToken, "text/csv", csvBody)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Equal(t, 2, decode(resp).Created)
+
+		var task models.Task
+		assert.NoError(t, db.Where("title = ?", "Migrate sheet").First(&task).Error)
+		assert.Equal(t, ownerID, task.UserID)
+		assert.Equal(t, models.TaskStatusInProgress, task.Status)
+		assert.Equal(t, models.TaskPriorityHigh, task.Priority)
+		assert.NotNil(t, task.StartedAt)
+		assert.Equal(t, "2030-01-15", task.DueDate.UTC().Format(time.DateOnly))
+		assert.Equal(t, 1, task.Version)
+		var formula models.Task
+		assert.NoError(t, db.Where("title = ?", "=Formula").First(&formula).Error)
+		assert.Equal(t, models.TaskStatusPending, formula.Status)
+	})
+
+	t.Run("Any invalid row fails the whole import", func(t *testing.T) {
+		before := countTasks(ownerID)
+		resp := doRaw(router, "POST", "/tasks/import", ownerToken, "application/json", `[
+			{"title": "Fine"},
+			{"title": "", "status": "done"},
+			{"title": "Not mine", "user_id": "`+otherID.String()+`"},
+			"not a task"
+		]`)
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		report := decode(resp)
+		assert.Equal(t, 4, report.Total)
+		assert.Equal(t, 1, report.Valid)
+		fields := map[string]int{}
+		for _, problem := range report.Errors {
+			fields[problem.Field] = problem.Line
+		}
+		assert.Equal(t, 2, fields["title"])
+		assert.Equal(t, 2, fields["status"])
+		assert.Equal(t, 3, fields["user_id"])
+		assert.Equal(t, 4, fields[""])
+		assert.Equal(t, before, countTasks(ownerID))
+	})
+
+	t.Run("Admins import NDJSON for other users", func(t *testing.T) {
+		resp := doRaw(router, "POST", "/tasks/import", adminToken, "application/x-ndjson",
+			`{"title": "Assigned", "user_id": "`+otherID.String()+`"}`+"\n\n"+
+				`{"title": "Orphan", "user_id": "`+uuid.Must(uuid.NewV4()).String()+`"}`+"\n")
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+		assert.Equal(t, "user_id", decode(resp).Errors[0].Field)
+
+		resp = doRaw(router, "POST", "/tasks/import", adminToken, "application/x-ndjson", `{"title": "Assigned", "user_id": "`+otherID.String()+`"}`)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		assert.Equal(t, int64(1), countTasks(otherID))
+	})
+
+	t.Run("Unsupported and empty bodies are refused", func(t *testing.T) {
+		resp := doRaw(router, "POST", "/tasks/import", ownerToken, "application/xml", "<tasks/>")
+		assert.Equal(t, http.StatusUnsupportedMediaType, resp.Code)
+		resp = doRaw(router, "POST", "/tasks/import", ownerToken, "text/csv", "name\nx\n")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doRaw(router, "POST", "/tasks/import", ownerToken, "application/json", "[]")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
+
+func TestCalendarFeed(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTaskTransferRouter(db)
+	taskService := services.NewTaskService()
+
+	ownerID, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	due := time.Now().Add(72 * time.Hour)
+	task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Subscribe to me", DueDate: &due, UserID: ownerID}
+	assert.NoError(t, taskService.CreateTask(db, &task))
+
+	resp := doJSON(router, "GET", "/users/calendar-feed", ownerToken, nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	var created struct {
+		URL  string              `json:"url"`
+		Feed models.CalendarFeed `json:"feed"`
+	}
+	resp = doJSON(router, "POST", "/users/calendar-feed", ownerToken, nil)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	assert.True(t, strings.HasPrefix(created.URL, handlers.CalendarFeedPath))
+	assert.True(t, strings.HasSuffix(created.URL, ".ics"))
+
+	// The feed needs no token, only its secret URL
+	resp = doJSON(router, "GET", created.URL, "", nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Header().Get("Content-Type"), "text/calendar")
+	assert.Contains(t, resp.Body.String(), "SUMMARY:Subscribe to me")
+
+	resp = doJSON(router, "GET", "/users/calendar-feed", ownerToken, nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotContains(t, resp.Body.String(), strings.TrimSuffix(strings.TrimPrefix(created.URL, handlers.CalendarFeedPath), ".ics"))
+
+	// Rotating replaces the URL
+	oldURL := created.URL
+	resp = doJSON(router, "POST", "/users/calendar-feed", ownerToken, nil)
+	json.Unmarshal(resp.Body.Bytes(), &created)
+	assert.NotEqual(t, oldURL, created.URL)
+	assert.Equal(t, http.StatusNotFound, doJSON(router, "GET", oldURL, "", nil).Code)
+	assert.Equal(t, http.StatusOK, doJSON(router, "GET", created.URL, "", nil).Code)
+
+	resp = doJSON(router, "DELETE", "/users/calendar-feed", ownerToken, nil)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(router, "GET", created.URL, "", nil).Code)
+	resp = doJSON(router, "DELETE", "/users/calendar-feed", ownerToken, nil)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
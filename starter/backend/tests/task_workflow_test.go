@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskWorkflow(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTaskHierarchyRouter(db)
+
+	_, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+
+	var task models.Task
+	resp := doJSON(router, "POST", "/tasks", ownerToken, map[string]interface{}{"title": "Ship it"})
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	json.Unmarshal(resp.Body.Bytes(), &task)
+	taskPath := "/tasks/" + task.ID.String()
+
+	reload := func() models.Task {
+		var current models.Task
+		db.First(&current, "id = ?", task.ID)
+		return current
+	}
+
+	t.Run("Status and priority default and are validated", func(t *testing.T) {
+		assert.Equal(t, models.TaskStatusPending, task.Status)
+		assert.Equal(t, models.TaskPriorityMedium, task.Priority)
+
+		resp := doJSON(router, "POST", "/tasks", ownerToken, map[string]interface{}{"title": "Typo", "status": "pendng"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = doJSON(router, "PATCH", taskPath, ownerToken, map[string]interface{}{"priority": "High"})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Illegal transitions are rejected with 422", func(t *testing.T) {
+		resp := doJSON(router, "PATCH", taskPath, ownerToken, map[string]interface{}{"status": "completed"})
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+
+		var body map[string]interface{}
+		json.Unmarshal(resp.Body.Bytes(), &body)
+		assert.Equal(t, []interface{}{"in_progress", "cancelled"}, body["allowed"])
+		assert.Equal(t, models.TaskStatusPending, reload().Status)
+	})
+
+	t.Run("Timestamps follow the status", func(t *testing.T) {
+		resp := doJSON(router, "PUT", taskPath, ownerToken, map[string]interface{}{"title": "Ship it", "status": "in_progress", "priority": "medium", "started_at": "2001-01-01T00:00:00Z"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		started := reload().StartedAt
+		assert.NotNil(t, started)
+		assert.True(t, started.Year() > 2001)
+
+		doJSON(router, "PATCH", taskPath, ownerToken, map[string]interface{}{"status": "review"})
+		resp = doJSON(router, "PATCH", taskPath, ownerToken, map[string]interface{}{"status": "completed"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		completed := reload()
+		assert.NotNil(t, completed.CompletedAt)
+		assert.True(t, completed.StartedAt.Equal(*started))
+
+		resp = doJSON(router, "PATCH", taskPath, ownerToken, map[string]interface{}{"status": "in_progress"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Nil(t, reload().CompletedAt)
+	})
+
+	t.Run("Workflow can be configured", func(t *testing.T) {
+		workflow, err := services.ParseWorkflow("pending:completed")
+		assert.NoError(t, err)
+		assert.NoError(t, workflow.CheckTransition(models.TaskStatusPending, models.TaskStatusCompleted))
+
+		var transitionErr *services.TransitionError
+		assert.ErrorAs(t, workflow.CheckTransition(models.TaskStatusCompleted, models.TaskStatusPending), &transitionErr)
+
+		_, err = services.ParseWorkflow("pending:done")
+		assert.Error(t, err)
+	})
+}
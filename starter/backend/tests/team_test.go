@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func setupTeamRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService()
+	teamService := services.NewTeamService()
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	teamHandler := handlers.NewTeamHandler(db, teamService, taskService)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
+		taskRoutes.GET("/:id", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskByID)
+	}
+
+	teamRoutes := router.Group("/teams")
+	teamRoutes.Use(middleware.AuthMiddleware())
+	{
+		teamRoutes.POST("", teamHandler.CreateTeam)
+		teamRoutes.GET("", teamHandler.GetTeams)
+		teamRoutes.GET("/:team_id", middleware.RequireTeamRole(db, teamService), teamHandler.GetTeam)
+		teamRoutes.POST("/:team_id/members", middleware.RequireTeamRole(db, teamService, models.TeamRoleAdmin), teamHandler.SetMember)
+		teamRoutes.DELETE("/:team_id/members/:user_id", middleware.RequireTeamRole(db, teamService, models.TeamRoleAdmin), teamHandler.RemoveMember)
+		teamRoutes.GET("/:team_id/tasks", middleware.RequirePermission("tasks", "read"), middleware.RequireTeamRole(db, teamService), teamHandler.GetTeamTasks)
+		teamRoutes.POST("/:team_id/tasks", middleware.RequirePermission("tasks", "create"), middleware.RequireTeamRole(db, teamService), teamHandler.CreateTeamTask)
+	}
+
+	return router
+}
+
+func TestTeamScopedTasks(t *testing.T) {
+	db := setupABACTestDB(t)
+	router := setupTeamRouter(db)
+
+	leadID, leadToken := createTestUser(t, db, "lead", "lead@test.com", "lead123", false)
+	memberID, memberToken := createTestUser(t, db, "member", "member@test.com", "member123", false)
+	outsiderID, outsiderToken := createTestUser(t, db, "outsider", "outsider@test.com", "outsider123", false)
+
+	var team models.Team
+	var memberTask models.Task
+
+	t.Run("Creator becomes team admin", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/teams", leadToken, handlers.TeamRequest{Name: "Platform"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &team)
+
+		role, err := services.TeamMemberRole(db, team.ID, leadID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.TeamRoleAdmin, role)
+	})
+
+	teamPath := "/teams/" + team.ID.String()
+
+	t.Run("Only team admins can add members", func(t *testing.T) {
+		resp := doJSON(router, "POST", teamPath+"/members", outsiderToken, handlers.TeamMemberRequest{UserID: memberID, Role: models.TeamRoleMember})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = doJSON(router, "POST", teamPath+"/members", leadToken, handlers.TeamMemberRequest{UserID: memberID, Role: models.TeamRoleMember})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = doJSON(router, "POST", teamPath+"/members", memberToken, handlers.TeamMemberRequest{UserID: memberID, Role: models.TeamRoleAdmin})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Members can create and list team tasks", func(t *testing.T) {
+		resp := doJSON(router, "POST", teamPath+"/tasks", memberToken, map[string]interface{}{"title": "Team task", "status": "pending"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &memberTask)
+		assert.Equal(t, team.ID, *memberTask.TeamID)
+
+		var tasks []models.Task
+		resp = doJSON(router, "GET", teamPath+"/tasks", leadToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &tasks)
+		assert.Len(t, tasks, 1)
+
+		resp = doJSON(router, "GET", teamPath+"/tasks", outsiderToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Team tasks only go into projects the member can edit", func(t *testing.T) {
+		projectService := services.NewProjectService()
+		foreign := models.Project{Name: "Outsider's"}
+		assert.NoError(t, projectService.CreateProject(db, &foreign, outsiderID))
+		inbox, err := projectService.CreateInbox(db, outsiderID)
+		assert.NoError(t, err)
+
+		for _, projectID := range []uuid.UUID{foreign.ID, inbox.ID} {
+			resp := doJSON(router, "POST", teamPath+"/tasks", memberToken, map[string]interface{}{"title": "Planted", "project_id": projectID})
+			assert.Equal(t, http.StatusForbidden, resp.Code)
+		}
+		var count int64
+		db.Model(&models.Task{}).Where("title = ?", "Planted").Count(&count)
+		assert.Zero(t, count)
+
+		mine := models.Project{Name: "Member's"}
+		assert.NoError(t, projectService.CreateProject(db, &mine, memberID))
+		resp := doJSON(router, "POST", teamPath+"/tasks", memberToken, map[string]interface{}{"title": "Filed", "project_id": mine.ID})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+	})
+
+	t.Run("Outsiders cannot see team tasks", func(t *testing.T) {
+		resp := doJSON(router, "GET", "/tasks/"+memberTask.ID.String(), outsiderToken, nil)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Team admin manages tasks without the global admin role", func(t *testing.T) {
+		resp := doJSON(router, "PATCH", "/tasks/"+memberTask.ID.String(), leadToken, map[string]interface{}{"title": "Reviewed"})
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = doJSON(router, "DELETE", "/tasks/"+memberTask.ID.String(), leadToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+	})
+
+	t.Run("Last team admin cannot be removed", func(t *testing.T) {
+		resp := doJSON(router, "DELETE", teamPath+"/members/"+leadID.String(), leadToken, nil)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("Users only list their own teams", func(t *testing.T) {
+		var teams []models.Team
+		resp := doJSON(router, "GET", "/teams", outsiderToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		json.Unmarshal(resp.Body.Bytes(), &teams)
+		assert.Len(t, teams, 0)
+
+		resp = doJSON(router, "GET", "/teams", memberToken, nil)
+		json.Unmarshal(resp.Body.Bytes(), &teams)
+		assert.Len(t, teams, 1)
+	})
+}
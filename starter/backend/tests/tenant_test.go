@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/repositories"
+	"task-manager/backend/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTenantTestDB(t *testing.T) (*gorm.DB, uuid.UUID, uuid.UUID) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, repositories.RegisterTenantScope(db))
+
+	system := db.WithContext(repositories.WithoutTenantScope(context.Background()))
+	err = system.AutoMigrate(&models.Tenant{}, &models.User{}, &models.Role{}, &models.Permission{}, &models.RolePermission{}, &models.UserRole{}, &models.Token{}, &models.Task{}, &models.TaskShare{}, &models.Team{}, &models.TeamMember{}, &models.Project{}, &models.ProjectMember{}, &models.TaskDependency{}, &models.Comment{}, &models.CommentRevision{}, &models.CommentMention{}, &models.Attachment{}, &models.Label{}, &models.TaskLabel{}, &models.ReminderPreference{}, &models.TaskReminder{}, &models.Notification{}, &models.Lease{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.OutboxEvent{}, &models.OutboxDelivery{}, &models.AuditEntry{}, &models.TaskRevision{}, &models.CalendarFeed{}, &models.IdempotencyKey{})
+	assert.NoError(t, err)
+
+	for _, action := range []string{"create", "read", "update", "delete"} {
+		system.Create(&models.Permission{ID: uuid.Must(uuid.NewV4()), Resource: "tasks", Action: action})
+	}
+
+	tenantService := services.NewTenantService()
+	tenantA := models.Tenant{Name: "Acme", Slug: "acme"}
+	tenantB := models.Tenant{Name: "Globex", Slug: "globex"}
+	assert.NoError(t, tenantService.CreateTenant(system, &tenantA))
+	assert.NoError(t, tenantService.CreateTenant(system, &tenantB))
+
+	return db, tenantA.ID, tenantB.ID
+}
+
+func TestTenantIsolation(t *testing.T) {
+	db, tenantA, tenantB := setupTenantTestDB(t)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskService := services.NewTaskService()
+	taskHandler := handlers.NewTaskHandler(db, taskService)
+	authHandler := handlers.NewAuthHandler(db, services.NewAuthService())
+	registerHandler := handlers.NewRegisterHandler(db, services.NewRegisterService())
+
+	router.Use(middleware.TenantMiddleware(db, services.NewTenantService()))
+	router.POST("/register", registerHandler.Registration)
+	router.POST("/login", authHandler.Token)
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.GET("/:id", middleware.RequirePermission("tasks", "read"), taskHandler.GetTaskByID)
+	}
+
+	send := func(method, path, tenant, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tenant != "" {
+			req.Header.Set(middleware.TenantHeader, tenant)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// The same username can exist in both tenants
+	tokens := map[uuid.UUID]string{}
+	taskIDs := map[uuid.UUID]uuid.UUID{}
+	for _, tenantID := range []uuid.UUID{tenantA, tenantB} {
+		resp := send("POST", "/register", tenantID.String(), "", `{"username":"alice","email":"alice@test.com","password":"password123"}`)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		resp = send("POST", "/login", tenantID.String(), "", `{"username":"alice","password":"password123"}`)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var auth handlers.AuthResponse
+		json.Unmarshal(resp.Body.Bytes(), &auth)
+		tokens[tenantID] = auth.AccessToken
+
+		resp = send("POST", "/tasks", "", auth.AccessToken, `{"title":"Task of `+tenantID.String()+`","status":"pending"}`)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		var task models.Task
+		json.Unmarshal(resp.Body.Bytes(), &task)
+		assert.Equal(t, tenantID, task.TenantID)
+		taskIDs[tenantID] = task.ID
+	}
+
+	t.Run("Unknown tenant header is rejected", func(t *testing.T) {
+		resp := send("POST", "/login", uuid.Must(uuid.NewV4()).String(), "", `{"username":"alice","password":"password123"}`)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Task from another tenant is not found", func(t *testing.T) {
+		resp := send("GET", "/tasks/"+taskIDs[tenantB].String(), "", tokens[tenantA], "")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		resp = send("GET", "/tasks/"+taskIDs[tenantA].String(), "", tokens[tenantA], "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	t.Run("Token cannot be replayed against another tenant", func(t *testing.T) {
+		resp := send("GET", "/tasks/"+taskIDs[tenantB].String(), tenantB.String(), tokens[tenantA], "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Unfiltered service queries only see the current tenant", func(t *testing.T) {
+		scoped := db.WithContext(repositories.WithTenant(context.Background(), tenantA))
+		tasks, err := taskService.GetTasks(scoped, services.TaskFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, taskIDs[tenantA], tasks[0].ID)
+
+		var users []models.User
+		assert.NoError(t, scoped.Find(&users).Error)
+		assert.Len(t, users, 1)
+	})
+
+	t.Run("Cross-tenant writes affect nothing", func(t *testing.T) {
+		scoped := db.WithContext(repositories.WithTenant(context.Background(), tenantA))
+		result := scoped.Model(&models.Task{}).Where("id = ?", taskIDs[tenantB]).Update("title", "hijacked")
+		assert.NoError(t, result.Error)
+		assert.Equal(t, int64(0), result.RowsAffected)
+
+		result = scoped.Delete(&models.Task{}, "id = ?", taskIDs[tenantB])
+		assert.NoError(t, result.Error)
+		assert.Equal(t, int64(0), result.RowsAffected)
+	})
+
+	t.Run("Created rows are stamped with the context tenant", func(t *testing.T) {
+		scoped := db.WithContext(repositories.WithTenant(context.Background(), tenantA))
+		task := models.Task{ID: uuid.Must(uuid.NewV4()), Title: "Sneaky", TenantID: tenantB}
+		assert.NoError(t, scoped.Create(&task).Error)
+		assert.Equal(t, tenantA, task.TenantID)
+	})
+
+	t.Run("Queries without a tenant fail closed", func(t *testing.T) {
+		_, err := taskService.GetTasks(db, services.TaskFilter{})
+		assert.ErrorIs(t, err, repositories.ErrMissingTenant)
+	})
+}
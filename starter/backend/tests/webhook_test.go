@@ -0,0 +1,354 @@
+package tests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync"
+	"task-manager/backend/internal/handlers"
+	"task-manager/backend/internal/middleware"
+	"task-manager/backend/internal/models"
+	"task-manager/backend/internal/notify"
+	"task-manager/backend/internal/services"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// webhookReceiver records the requests it gets and answers them with status
+type webhookReceiver struct {
+	mu       sync.Mutex
+	requests []receivedWebhook
+	status   int
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, receivedWebhook{header: req.Header.Clone(), body: body})
+	w.WriteHeader(r.status)
+	w.Write([]byte("thanks"))
+}
+
+func (r *webhookReceiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+func (r *webhookReceiver) answer(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func setupWebhookRouter(db *gorm.DB, webhookService services.WebhookService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler())
+
+	taskHandler := handlers.NewTaskHandler(db, services.NewTaskService())
+	userHandler := handlers.NewUserHandler(db, services.NewUserService())
+	webhookHandler := handlers.NewWebhookHandler(db, webhookService)
+
+	taskRoutes := router.Group("/tasks")
+	taskRoutes.Use(middleware.AuthMiddleware())
+	{
+		taskRoutes.POST("", middleware.RequirePermission("tasks", "create"), taskHandler.CreateTask)
+		taskRoutes.PUT("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.UpdateTask)
+		taskRoutes.PATCH("/:id", middleware.RequirePermission("tasks", "update"), taskHandler.PatchTask)
+		taskRoutes.DELETE("/:id", middleware.RequirePermission("tasks", "delete"), taskHandler.DeleteTask)
+	}
+
+	userRoutes := router.Group("/users")
+	userRoutes.Use(middleware.AuthMiddleware())
+	{
+		userRoutes.DELETE("/:user_id", middleware.RequireRoleAndPermission("admin", "users", "delete"), userHandler.DeleteUser)
+	}
+
+	webhookRoutes := router.Group("/webhooks")
+	webhookRoutes.Use(middleware.AuthMiddleware())
+	{
+		webhookRoutes.POST("", webhookHandler.CreateWebhook)
+		webhookRoutes.GET("", webhookHandler.GetWebhooks)
+		webhookRoutes.GET("/:webhook_id", webhookHandler.GetWebhook)
+		webhookRoutes.PUT("/:webhook_id", webhookHandler.UpdateWebhook)
+		webhookRoutes.DELETE("/:webhook_id", webhookHandler.DeleteWebhook)
+		webhookRoutes.GET("/:webhook_id/deliveries", webhookHandler.GetDeliveries)
+		webhookRoutes.POST("/:webhook_id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+	}
+
+	return router
+}
+
+type webhookDeliveryPage struct {
+	Items []models.WebhookDelivery `json:"items"`
+	Total int64                    `json:"total"`
+}
+
+type webhookEnvelope struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+func TestWebhooks(t *testing.T) {
+	db := setupABACTestDB(t)
+	receiver := &webhookReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhookService := services.NewWebhookService().WithClient(server.Client())
+	router := setupWebhookRouter(db, webhookService)
+
+	_, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+	strangerID, strangerToken := createTestUser(t, db, "stranger", "stranger@test.com", "stranger123", false)
+	_, adminToken := createTestUser(t, db, "admin", "admin@test.com", "admin123", true)
+
+	type created struct {
+		models.Webhook
+		Secret string `json:"secret"`
+	}
+	subscribe := func(token string, body gin.H) created {
+		resp := doJSON(router, "POST", "/webhooks", token, body)
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		var webhook created
+		json.Unmarshal(resp.Body.Bytes(), &webhook)
+		return webhook
+	}
+	deliveries := func(token string, webhook created) webhookDeliveryPage {
+		resp := doJSON(router, "GET", "/webhooks/"+webhook.ID.String()+"/deliveries", token, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		var page webhookDeliveryPage
+		json.Unmarshal(resp.Body.Bytes(), &page)
+		return page
+	}
+	deliver := func(at time.Time) int {
+		n, err := webhookService.DeliverDue(db, at)
+		assert.NoError(t, err)
+		return n
+	}
+
+	t.Run("Invalid webhooks are rejected", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/webhooks", ownerToken, gin.H{"url": "ftp://example.com", "events": []string{"task.created"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "POST", "/webhooks", ownerToken, gin.H{"url": server.URL, "events": []string{"task.renamed"}})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "POST", "/webhooks", ownerToken, gin.H{"url": server.URL})
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = doJSON(router, "POST", "/webhooks", ownerToken, gin.H{"url": server.URL, "events": []string{"user.deleted"}, "scope": "tenant"})
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	own := subscribe(ownerToken, gin.H{"url": server.URL, "events": []string{"task.created", "task.updated", "task.deleted"}})
+	strangers := subscribe(strangerToken, gin.H{"url": server.URL, "events": []string{"task.created"}})
+	tenant := subscribe(adminToken, gin.H{"url": server.URL, "events": []string{"task.deleted", "user.deleted"}, "scope": "tenant"})
+
+	t.Run("The secret is only shown on creation", func(t *testing.T) {
+		assert.Len(t, own.Secret, 64)
+		assert.Equal(t, models.WebhookScopeUser, own.Scope)
+
+		resp := doJSON(router, "GET", "/webhooks/"+own.ID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.NotContains(t, resp.Body.String(), own.Secret)
+
+		// Other users' webhooks do not exist for them
+		resp = doJSON(router, "GET", "/webhooks/"+own.ID.String(), strangerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	var task models.Task
+	resp := doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Ship release"})
+	json.Unmarshal(resp.Body.Bytes(), &task)
+
+	t.Run("Events reach subscribers who can see them, signed", func(t *testing.T) {
+		assert.Equal(t, 1, deliver(time.Now()))
+
+		requests := receiver.received()
+		assert.Len(t, requests, 1)
+		request := requests[0]
+		assert.Equal(t, "task.created", request.header.Get("X-Webhook-Event"))
+
+		mac := hmac.New(sha256.New, []byte(own.Secret))
+		mac.Write([]byte(request.header.Get("X-Webhook-Timestamp") + "." + string(request.body)))
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), request.header.Get("X-Webhook-Signature"))
+
+		var envelope webhookEnvelope
+		json.Unmarshal(request.body, &envelope)
+		assert.Equal(t, "task.created", envelope.Type)
+		var sent models.Task
+		json.Unmarshal(envelope.Data, &sent)
+		assert.Equal(t, task.ID, sent.ID)
+		assert.Equal(t, "Ship release", sent.Title)
+
+		assert.Equal(t, int64(0), deliveries(strangerToken, strangers).Total)
+	})
+
+	t.Run("Failed deliveries are retried with backoff", func(t *testing.T) {
+		receiver.answer(http.StatusServiceUnavailable)
+		doJSON(router, "PATCH", "/tasks/"+task.ID.String(), ownerToken, gin.H{"title": "Ship release 2.0"})
+
+		now := time.Now()
+		assert.Equal(t, 0, deliver(now))
+		latest := deliveries(ownerToken, own).Items[0]
+		assert.Equal(t, models.WebhookDeliveryPending, latest.Status)
+		assert.Equal(t, 1, latest.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, latest.ResponseCode)
+		assert.Equal(t, "thanks", latest.ResponseBody)
+		assert.WithinDuration(t, now.Add(30*time.Second), *latest.NextAttemptAt, time.Second)
+
+		// Not due yet
+		assert.Equal(t, 0, deliver(now.Add(10*time.Second)))
+		assert.Len(t, receiver.received(), 2)
+
+		assert.Equal(t, 0, deliver(now.Add(31*time.Second)))
+		latest = deliveries(ownerToken, own).Items[0]
+		assert.Equal(t, 2, latest.Attempts)
+		assert.WithinDuration(t, now.Add(91*time.Second), *latest.NextAttemptAt, time.Second)
+
+		receiver.answer(http.StatusOK)
+		assert.Equal(t, 1, deliver(now.Add(2*time.Minute)))
+		latest = deliveries(ownerToken, own).Items[0]
+		assert.Equal(t, models.WebhookDeliverySucceeded, latest.Status)
+		assert.Equal(t, 3, latest.Attempts)
+		assert.Equal(t, http.StatusOK, latest.ResponseCode)
+		assert.Nil(t, latest.NextAttemptAt)
+	})
+
+	t.Run("Deliveries are given up after too many failures", func(t *testing.T) {
+		receiver.answer(http.StatusInternalServerError)
+		doJSON(router, "PATCH", "/tasks/"+task.ID.String(), ownerToken, gin.H{"priority": "high"})
+
+		at := time.Now()
+		for i := 0; i < 10; i++ {
+			deliver(at)
+			at = at.Add(2 * time.Hour)
+		}
+		latest := deliveries(ownerToken, own).Items[0]
+		assert.Equal(t, models.WebhookDeliveryFailed, latest.Status)
+		assert.Equal(t, 8, latest.Attempts)
+		receiver.answer(http.StatusOK)
+	})
+
+	t.Run("Deliveries can be sent again by hand", func(t *testing.T) {
+		failed := deliveries(ownerToken, own).Items[0]
+		resp := doJSON(router, "POST", "/webhooks/"+own.ID.String()+"/deliveries/"+failed.ID.String()+"/redeliver", ownerToken, nil)
+		assert.Equal(t, http.StatusAccepted, resp.Code)
+
+		assert.Equal(t, 1, deliver(time.Now()))
+		requests := receiver.received()
+		last := requests[len(requests)-1]
+		assert.Equal(t, failed.Payload, string(last.body))
+		assert.NotEqual(t, failed.ID.String(), last.header.Get("X-Webhook-Delivery"))
+
+		page := deliveries(ownerToken, own)
+		assert.Equal(t, models.WebhookDeliverySucceeded, page.Items[0].Status)
+		assert.Equal(t, failed.ID, *page.Items[0].RedeliveryOf)
+
+		resp = doJSON(router, "POST", "/webhooks/"+own.ID.String()+"/deliveries/"+failed.ID.String()+"/redeliver", strangerToken, nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Tenant webhooks receive deletions and user events", func(t *testing.T) {
+		before := len(receiver.received())
+		resp := doJSON(router, "DELETE", "/tasks/"+task.ID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		resp = doJSON(router, "DELETE", "/users/"+strangerID.String(), adminToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+
+		assert.Equal(t, 3, deliver(time.Now()))
+		assert.Len(t, receiver.received(), before+3)
+
+		page := deliveries(adminToken, tenant)
+		assert.Equal(t, int64(2), page.Total)
+		types := []string{page.Items[0].EventType, page.Items[1].EventType}
+		assert.ElementsMatch(t, []string{"task.deleted", "user.deleted"}, types)
+		assert.Equal(t, "task.deleted", deliveries(ownerToken, own).Items[0].EventType)
+	})
+
+	t.Run("Disabled and deleted webhooks receive nothing", func(t *testing.T) {
+		resp := doJSON(router, "PUT", "/webhooks/"+own.ID.String(), ownerToken, gin.H{"active": false})
+		assert.Equal(t, http.StatusOK, resp.Code)
+		doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Quiet task"})
+		assert.Equal(t, 0, deliver(time.Now()))
+
+		resp = doJSON(router, "DELETE", "/webhooks/"+own.ID.String(), ownerToken, nil)
+		assert.Equal(t, http.StatusNoContent, resp.Code)
+		resp = doJSON(router, "GET", "/webhooks", ownerToken, nil)
+		assert.JSONEq(t, `[]`, resp.Body.String())
+	})
+}
+
+func TestWebhookTargets(t *testing.T) {
+	db := setupABACTestDB(t)
+	receiver := &webhookReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	// The default client, unlike the test server's, refuses internal addresses
+	webhookService := services.NewWebhookService()
+	router := setupWebhookRouter(db, webhookService)
+	_, ownerToken := createTestUser(t, db, "owner", "owner@test.com", "owner123", false)
+
+	t.Run("Deliveries to internal addresses are refused", func(t *testing.T) {
+		resp := doJSON(router, "POST", "/webhooks", ownerToken, gin.H{"url": server.URL + "/metrics", "events": []string{"task.created"}})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+		var webhook models.Webhook
+		json.Unmarshal(resp.Body.Bytes(), &webhook)
+
+		resp = doJSON(router, "POST", "/tasks", ownerToken, gin.H{"title": "Probe"})
+		assert.Equal(t, http.StatusCreated, resp.Code)
+
+		n, err := webhookService.DeliverDue(db, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+		assert.Empty(t, receiver.received())
+
+		resp = doJSON(router, "GET", "/webhooks/"+webhook.ID.String()+"/deliveries", ownerToken, nil)
+		var page webhookDeliveryPage
+		json.Unmarshal(resp.Body.Bytes(), &page)
+		assert.Len(t, page.Items, 1)
+		assert.Contains(t, page.Items[0].Error, notify.ErrNonPublicAddress.Error())
+		assert.Empty(t, page.Items[0].ResponseBody)
+	})
+
+	t.Run("Only public addresses are reachable", func(t *testing.T) {
+		for addr, public := range map[string]bool{
+			"93.184.216.34":    true,
+			"2606:4700::1111":  true,
+			"127.0.0.1":        false,
+			"::1":              false,
+			"::ffff:127.0.0.1": false,
+			"10.0.0.8":         false,
+			"172.16.4.2":       false,
+			"192.168.1.1":      false,
+			"169.254.169.254":  false,
+			"fe80::1":          false,
+			"fd00::1":          false,
+			"100.64.0.1":       false,
+			"0.0.0.0":          false,
+		} {
+			assert.Equal(t, public, notify.PublicAddr(netip.MustParseAddr(addr)), addr)
+		}
+	})
+
+	t.Run("Redirects are not followed", func(t *testing.T) {
+		client := notify.NewPublicClient(time.Second)
+		req := httptest.NewRequest("GET", "https://example.com/next", nil)
+		assert.ErrorIs(t, client.CheckRedirect(req, []*http.Request{req}), http.ErrUseLastResponse)
+	})
+}
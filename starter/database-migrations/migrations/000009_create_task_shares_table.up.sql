@@ -0,0 +1,18 @@
+-- Create task_shares table if not exists
+CREATE TABLE IF NOT EXISTS task_shares (
+    id UUID PRIMARY KEY,
+    task_id UUID NOT NULL,
+    subject_type VARCHAR(20) NOT NULL,
+    subject_id UUID NOT NULL,
+    permission VARCHAR(20) NOT NULL,
+    granted_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (task_id, subject_type, subject_id),
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    CHECK (subject_type IN ('user', 'role')),
+    CHECK (permission IN ('viewer', 'editor', 'owner'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_shares_task_id ON task_shares(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_shares_subject_id ON task_shares(subject_id);
@@ -0,0 +1,8 @@
+ALTER TABLE task_shares DROP CONSTRAINT IF EXISTS task_shares_subject_type_check;
+ALTER TABLE task_shares ADD CONSTRAINT task_shares_subject_type_check CHECK (subject_type IN ('user', 'role'));
+
+DROP INDEX IF EXISTS idx_tasks_team_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS team_id;
+
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
@@ -0,0 +1,36 @@
+-- Create teams table if not exists
+CREATE TABLE IF NOT EXISTS teams (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_teams_deleted_at ON teams(deleted_at);
+
+-- Create team_members table if not exists
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (team_id, user_id),
+    FOREIGN KEY (team_id) REFERENCES teams(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    CHECK (role IN ('admin', 'member'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON team_members(user_id);
+
+-- Tasks can be owned by a team
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES teams(id);
+CREATE INDEX IF NOT EXISTS idx_tasks_team_id ON tasks(team_id);
+
+-- Tasks can be shared with a whole team
+ALTER TABLE task_shares DROP CONSTRAINT IF EXISTS task_shares_subject_type_check;
+ALTER TABLE task_shares ADD CONSTRAINT task_shares_subject_type_check CHECK (subject_type IN ('user', 'role', 'team'));
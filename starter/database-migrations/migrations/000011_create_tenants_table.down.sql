@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS idx_users_tenant_username;
+DROP INDEX IF EXISTS idx_users_tenant_email;
+DROP INDEX IF EXISTS idx_roles_tenant_name;
+
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE roles ADD CONSTRAINT roles_name_key UNIQUE (name);
+
+ALTER TABLE task_shares DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE team_members DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE teams DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE tokens DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE roles DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,61 @@
+-- Create tenants table if not exists
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Insert default tenant; every existing row is backfilled into it
+INSERT INTO tenants (id, name, slug, created_at, updated_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Default', 'default', NOW(), NOW())
+ON CONFLICT (id) DO NOTHING;
+
+-- Add tenant_id to every tenant-scoped table
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE tokens ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE team_members ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE task_shares ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+
+-- The application always sets tenant_id explicitly, so drop the backfill defaults
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE roles ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE tokens ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE tasks ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE teams ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE team_members ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE task_shares ALTER COLUMN tenant_id DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_tokens_tenant_id ON tokens(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_tenant_id ON tasks(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_teams_tenant_id ON teams(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_team_members_tenant_id ON team_members(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_task_shares_tenant_id ON task_shares(tenant_id);
+
+-- Usernames, emails and role names are unique per tenant instead of globally
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_username;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_email;
+ALTER TABLE roles DROP CONSTRAINT IF EXISTS roles_name_key;
+ALTER TABLE roles DROP CONSTRAINT IF EXISTS uni_roles_name;
+
+-- Usernames were never unique, and the seed data holds two "admin" users.
+-- Keep the oldest name and suffix the others with their ID so the index can
+-- be built.
+UPDATE users u
+SET username = u.username || '-' || LEFT(u.id::text, 8)
+WHERE EXISTS (
+    SELECT 1 FROM users o
+    WHERE o.tenant_id = u.tenant_id
+      AND o.username = u.username
+      AND (o.created_at, o.id) < (u.created_at, u.id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_username ON users(tenant_id, username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email ON users(tenant_id, email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_tenant_name ON roles(tenant_id, name);
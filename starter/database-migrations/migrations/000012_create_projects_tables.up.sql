@@ -0,0 +1,66 @@
+-- Create projects table if not exists
+CREATE TABLE IF NOT EXISTS projects (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    owner_id UUID NOT NULL,
+    inbox BOOLEAN NOT NULL DEFAULT FALSE,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (owner_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_tenant_id ON projects(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_projects_owner_id ON projects(owner_id);
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects(deleted_at);
+
+-- Each user has at most one inbox
+CREATE UNIQUE INDEX IF NOT EXISTS idx_projects_inbox ON projects(owner_id) WHERE inbox AND deleted_at IS NULL;
+
+-- Create project_members table if not exists
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    permission VARCHAR(20) NOT NULL DEFAULT 'viewer',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (project_id, user_id),
+    FOREIGN KEY (project_id) REFERENCES projects(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    CHECK (permission IN ('viewer', 'editor', 'owner'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_members_user_id ON project_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_project_members_tenant_id ON project_members(tenant_id);
+
+-- Tasks can belong to a project
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id UUID REFERENCES projects(id);
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks(project_id);
+
+-- Backfill an Inbox project for every existing user
+INSERT INTO projects (id, tenant_id, name, description, owner_id, inbox, archived, created_at, updated_at)
+SELECT gen_random_uuid(), u.tenant_id, 'Inbox', '', u.id, TRUE, FALSE, NOW(), NOW()
+FROM users u
+WHERE NOT EXISTS (
+    SELECT 1 FROM projects p WHERE p.owner_id = u.id AND p.inbox AND p.deleted_at IS NULL
+);
+
+INSERT INTO project_members (project_id, user_id, tenant_id, permission, created_at, updated_at)
+SELECT p.id, p.owner_id, p.tenant_id, 'owner', NOW(), NOW()
+FROM projects p
+WHERE p.inbox
+ON CONFLICT (project_id, user_id) DO NOTHING;
+
+-- Personal tasks without a project move into their owner's Inbox
+UPDATE tasks t
+SET project_id = p.id
+FROM projects p
+WHERE p.owner_id = t.user_id
+  AND p.inbox
+  AND p.deleted_at IS NULL
+  AND t.project_id IS NULL
+  AND t.team_id IS NULL;
@@ -0,0 +1,20 @@
+-- Tasks can be broken down into subtasks
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES tasks(id);
+CREATE INDEX IF NOT EXISTS idx_tasks_parent_id ON tasks(parent_id);
+
+-- Create task_dependencies table if not exists
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    task_id UUID NOT NULL,
+    blocked_by_id UUID NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (task_id, blocked_by_id),
+    FOREIGN KEY (task_id) REFERENCES tasks(id),
+    FOREIGN KEY (blocked_by_id) REFERENCES tasks(id),
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    CHECK (task_id <> blocked_by_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_blocked_by_id ON task_dependencies(blocked_by_id);
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_tenant_id ON task_dependencies(tenant_id);
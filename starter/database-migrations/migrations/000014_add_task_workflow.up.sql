@@ -0,0 +1,24 @@
+-- Normalize free-form statuses and priorities before constraining them
+UPDATE tasks SET status = LOWER(TRIM(status));
+UPDATE tasks SET status = 'in_progress' WHERE status IN ('in progress', 'in-progress', 'doing', 'started');
+UPDATE tasks SET status = 'completed' WHERE status IN ('done', 'complete', 'closed');
+UPDATE tasks SET status = 'cancelled' WHERE status = 'canceled';
+UPDATE tasks SET status = 'pending'
+WHERE status NOT IN ('pending', 'in_progress', 'review', 'completed', 'cancelled');
+
+UPDATE tasks SET priority = LOWER(TRIM(priority));
+UPDATE tasks SET priority = 'medium' WHERE priority NOT IN ('low', 'medium', 'high');
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_status_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_status_check CHECK (status IN ('pending', 'in_progress', 'review', 'completed', 'cancelled'));
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_priority_check;
+ALTER TABLE tasks ADD CONSTRAINT tasks_priority_check CHECK (priority IN ('low', 'medium', 'high'));
+
+-- Workflow timestamps, backfilled as well as the existing data allows
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS started_at TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP;
+
+UPDATE tasks SET started_at = created_at
+WHERE started_at IS NULL AND status IN ('in_progress', 'review', 'completed');
+UPDATE tasks SET completed_at = updated_at
+WHERE completed_at IS NULL AND status = 'completed';
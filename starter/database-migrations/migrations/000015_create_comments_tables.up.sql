@@ -0,0 +1,47 @@
+-- Create comments table if not exists
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    task_id UUID NOT NULL,
+    author_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    edited_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (task_id) REFERENCES tasks(id),
+    FOREIGN KEY (author_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_tenant_id ON comments(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_comments_task_id ON comments(task_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments(deleted_at);
+
+-- Create comment_revisions table if not exists
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    comment_id UUID NOT NULL,
+    body TEXT NOT NULL,
+    edited_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (comment_id) REFERENCES comments(id),
+    FOREIGN KEY (edited_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_revisions_tenant_id ON comment_revisions(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_comment_revisions_comment_id ON comment_revisions(comment_id);
+
+-- Create comment_mentions table if not exists
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (comment_id, user_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(id),
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_user_id ON comment_mentions(user_id);
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_tenant_id ON comment_mentions(tenant_id);
@@ -0,0 +1,18 @@
+-- Create attachments table if not exists
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    task_id UUID NOT NULL,
+    uploaded_by UUID NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    storage_key VARCHAR(512) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (task_id) REFERENCES tasks(id),
+    FOREIGN KEY (uploaded_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_tenant_id ON attachments(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_attachments_task_id ON attachments(task_id);
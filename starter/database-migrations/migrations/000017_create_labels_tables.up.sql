@@ -0,0 +1,37 @@
+-- Create labels table if not exists
+CREATE TABLE IF NOT EXISTS labels (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    name VARCHAR(50) NOT NULL,
+    color CHAR(7) NOT NULL,
+    user_id UUID REFERENCES users(id),
+    team_id UUID REFERENCES teams(id),
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    CHECK ((user_id IS NULL) <> (team_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_labels_tenant_id ON labels(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_labels_user_id ON labels(user_id);
+CREATE INDEX IF NOT EXISTS idx_labels_team_id ON labels(team_id);
+
+-- Label names are unique per owner, ignoring case
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_user_name ON labels(user_id, LOWER(name)) WHERE team_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_team_name ON labels(team_id, LOWER(name)) WHERE team_id IS NOT NULL;
+
+-- Create task_labels table if not exists
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id UUID NOT NULL,
+    label_id UUID NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    created_by UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (task_id, label_id),
+    FOREIGN KEY (task_id) REFERENCES tasks(id),
+    FOREIGN KEY (label_id) REFERENCES labels(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_labels_label_id ON task_labels(label_id);
+CREATE INDEX IF NOT EXISTS idx_task_labels_tenant_id ON task_labels(tenant_id);
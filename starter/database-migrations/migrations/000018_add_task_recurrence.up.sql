@@ -0,0 +1,8 @@
+-- Recurring tasks: every occurrence carries the series' RRULE and the slot it fills
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS recurrence VARCHAR(500) NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS series_id UUID;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS occurrence_at TIMESTAMP;
+
+-- One occurrence per series and slot, deleted ones included, so generators
+-- running on several replicas cannot create the same occurrence twice
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_series_occurrence ON tasks(series_id, occurrence_at) WHERE series_id IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS leases;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS task_reminders;
+DROP TABLE IF EXISTS reminder_preferences;
@@ -0,0 +1,54 @@
+-- Create reminder_preferences table if not exists
+CREATE TABLE IF NOT EXISTS reminder_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    lead_minutes INTEGER NOT NULL,
+    overdue BOOLEAN NOT NULL DEFAULT TRUE,
+    channels TEXT NOT NULL DEFAULT '["in_app"]',
+    webhook_url VARCHAR(2048) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL,
+    CHECK (lead_minutes BETWEEN 1 AND 10080)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reminder_preferences_tenant_id ON reminder_preferences(tenant_id);
+
+-- Create task_reminders table if not exists
+CREATE TABLE IF NOT EXISTS task_reminders (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    due_date TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_reminders_tenant_id ON task_reminders(tenant_id);
+
+-- Each reminder is sent once per due date and channel
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_reminders_sent ON task_reminders(task_id, user_id, kind, channel, due_date);
+
+-- Create notifications table if not exists
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    task_id UUID,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_id ON notifications(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id);
+
+-- Leases are shared by all tenants and pick the one replica that runs a job
+CREATE TABLE IF NOT EXISTS leases (
+    name VARCHAR(100) PRIMARY KEY,
+    holder VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notifications_created_at;
+DROP INDEX IF EXISTS idx_notifications_user_unread;
+ALTER TABLE notifications DROP COLUMN IF EXISTS actor_id;
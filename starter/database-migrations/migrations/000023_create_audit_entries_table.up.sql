@@ -0,0 +1,38 @@
+-- Create audit_entries table if not exists
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    seq BIGINT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    actor_id UUID,
+    target_type VARCHAR(50) NOT NULL DEFAULT '',
+    target_id UUID,
+    changes TEXT,
+    detail TEXT NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- Each tenant's entries form one chain, numbered without gaps
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_entries_tenant_seq ON audit_entries(tenant_id, seq);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_action ON audit_entries(action);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_actor_id ON audit_entries(actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_target_id ON audit_entries(target_id);
+CREATE INDEX IF NOT EXISTS idx_audit_entries_created_at ON audit_entries(created_at);
+
+-- The log is append-only. The hash chain detects changes made around this
+-- trigger, for example by a superuser who disables it.
+CREATE OR REPLACE FUNCTION audit_entries_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_entries is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_entries_append_only ON audit_entries;
+CREATE TRIGGER audit_entries_append_only
+    BEFORE UPDATE OR DELETE ON audit_entries
+    FOR EACH ROW EXECUTE FUNCTION audit_entries_append_only();
@@ -0,0 +1,16 @@
+-- Create task_revisions table if not exists
+CREATE TABLE IF NOT EXISTS task_revisions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    actor_id UUID,
+    action VARCHAR(50) NOT NULL,
+    changes TEXT,
+    snapshot TEXT NOT NULL,
+    reverted_from INTEGER,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_revisions_task_revision ON task_revisions(task_id, revision);
+CREATE INDEX IF NOT EXISTS idx_task_revisions_tenant_id ON task_revisions(tenant_id);
@@ -0,0 +1,3 @@
+-- Version the tasks for optimistic concurrency: every write advances it, and
+-- clients send it back in If-Match
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
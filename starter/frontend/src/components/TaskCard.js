@@ -34,11 +34,11 @@ const TaskCard = ({ task }) => {
           <Typography
             variant="caption"
             sx={{
-              color: task.priority === 'High' ? 'red' : task.priority === 'Medium' ? 'orange' : 'green',
+              color: task.priority === 'high' ? 'red' : task.priority === 'medium' ? 'orange' : 'green',
               fontWeight: 'bold',
             }}
           >
-            Priority: {task.priority || 'Low'}
+            Priority: {task.priority || 'low'}
           </Typography>
         </Box>
 